@@ -0,0 +1,259 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strings"
+
+	db "github.com/cihanalici/api/db/sqlc"
+	"github.com/cihanalici/api/token"
+	"github.com/cihanalici/api/util"
+	"github.com/gin-gonic/gin"
+	"github.com/lib/pq"
+)
+
+// postalAddress holds the fields of an address that a parcel or an invoice
+// needs. Country is an upper case ISO 3166-1 alpha-2 code, whether State and
+// PostalCode are required and what the postal code looks like depends on it.
+type postalAddress struct {
+	FullName   string `json:"full_name" binding:"required,max=255"`
+	Phone      string `json:"phone" binding:"max=50"`
+	Line1      string `json:"line1" binding:"required,max=255"`
+	Line2      string `json:"line2" binding:"max=255"`
+	City       string `json:"city" binding:"required,max=100"`
+	State      string `json:"state" binding:"max=100"`
+	PostalCode string `json:"postal_code" binding:"max=20"`
+	Country    string `json:"country" binding:"required,iso3166_1_alpha2"`
+}
+
+// normalize trims the postal code and runs the country specific checks
+func (address *postalAddress) normalize() error {
+	address.PostalCode = strings.TrimSpace(address.PostalCode)
+	return util.ValidateAddressForCountry(address.Country, address.State, address.PostalCode)
+}
+
+func newPostalAddress(address db.UserAddress) postalAddress {
+	return postalAddress{
+		FullName:   address.FullName,
+		Phone:      address.Phone,
+		Line1:      address.Line1,
+		Line2:      address.Line2,
+		City:       address.City,
+		State:      address.State,
+		PostalCode: address.PostalCode,
+		Country:    address.Country,
+	}
+}
+
+// addressRequest is an address book entry
+type addressRequest struct {
+	Label string `json:"label" binding:"max=50"`
+	postalAddress
+	IsDefaultShipping bool `json:"is_default_shipping"`
+	IsDefaultBilling  bool `json:"is_default_billing"`
+}
+
+// bindAddressRequest reads and validates an address. It returns false after
+// writing the response.
+func bindAddressRequest(ctx *gin.Context) (addressRequest, bool) {
+	var req addressRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return req, false
+	}
+
+	if err := req.normalize(); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return req, false
+	}
+
+	return req, true
+}
+
+// addressErrorStatus maps a failed default flag change, which can only race
+// with another request of the same user, to a conflict
+func addressErrorStatus(err error) int {
+	if pqErr, ok := err.(*pq.Error); ok && pqErr.Code.Name() == "unique_violation" {
+		return http.StatusConflict
+	}
+	return http.StatusInternalServerError
+}
+
+// ListAddresses godoc
+// @Summary List the current user's addresses
+// @Description Default shipping and billing addresses come first
+// @Tags addresses
+// @Produce json
+// @Success 200 {array} db.UserAddress
+// @Router /users/me/addresses [get]
+func (server *Server) listAddresses(ctx *gin.Context) {
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	addresses, err := server.store.ListUserAddresses(ctx, authPayload.UserID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, addresses)
+}
+
+// CreateAddress godoc
+// @Summary Add an address to the current user's address book
+// @Description The first address becomes the default shipping and billing address
+// @Tags addresses
+// @Accept json
+// @Produce json
+// @Param request body addressRequest true "Address"
+// @Success 200 {object} db.UserAddress
+// @Router /users/me/addresses [post]
+func (server *Server) createAddress(ctx *gin.Context) {
+	req, ok := bindAddressRequest(ctx)
+	if !ok {
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	address, err := server.store.CreateUserAddressTx(ctx, db.CreateUserAddressParams{
+		UserID:            authPayload.UserID,
+		Label:             req.Label,
+		FullName:          req.FullName,
+		Phone:             req.Phone,
+		Line1:             req.Line1,
+		Line2:             req.Line2,
+		City:              req.City,
+		State:             req.State,
+		PostalCode:        req.PostalCode,
+		Country:           req.Country,
+		IsDefaultShipping: req.IsDefaultShipping,
+		IsDefaultBilling:  req.IsDefaultBilling,
+	})
+	if err != nil {
+		ctx.JSON(addressErrorStatus(err), errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, address)
+}
+
+type addressUriRequest struct {
+	ID int32 `uri:"id" binding:"required,min=1"`
+}
+
+// GetAddress godoc
+// @Summary Get one of the current user's addresses
+// @Tags addresses
+// @Produce json
+// @Param id path int true "Address ID"
+// @Success 200 {object} db.UserAddress
+// @Router /users/me/addresses/{id} [get]
+func (server *Server) getAddress(ctx *gin.Context) {
+	var uri addressUriRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	address, err := server.store.GetUserAddress(ctx, db.GetUserAddressParams{
+		ID:     uri.ID,
+		UserID: authPayload.UserID,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, address)
+}
+
+// UpdateAddress godoc
+// @Summary Replace one of the current user's addresses
+// @Description Setting a default flag moves it from the previous default address
+// @Tags addresses
+// @Accept json
+// @Produce json
+// @Param id path int true "Address ID"
+// @Param request body addressRequest true "Address"
+// @Success 200 {object} db.UserAddress
+// @Router /users/me/addresses/{id} [put]
+func (server *Server) updateAddress(ctx *gin.Context) {
+	var uri addressUriRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	req, ok := bindAddressRequest(ctx)
+	if !ok {
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	address, err := server.store.UpdateUserAddressTx(ctx, db.UpdateUserAddressParams{
+		ID:                uri.ID,
+		UserID:            authPayload.UserID,
+		Label:             req.Label,
+		FullName:          req.FullName,
+		Phone:             req.Phone,
+		Line1:             req.Line1,
+		Line2:             req.Line2,
+		City:              req.City,
+		State:             req.State,
+		PostalCode:        req.PostalCode,
+		Country:           req.Country,
+		IsDefaultShipping: req.IsDefaultShipping,
+		IsDefaultBilling:  req.IsDefaultBilling,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+		ctx.JSON(addressErrorStatus(err), errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, address)
+}
+
+// DeleteAddress godoc
+// @Summary Delete one of the current user's addresses
+// @Tags addresses
+// @Produce json
+// @Param id path int true "Address ID"
+// @Success 200 {object} map[string]string
+// @Router /users/me/addresses/{id} [delete]
+func (server *Server) deleteAddress(ctx *gin.Context) {
+	var uri addressUriRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	deleted, err := server.store.DeleteUserAddress(ctx, db.DeleteUserAddressParams{
+		ID:     uri.ID,
+		UserID: authPayload.UserID,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	if deleted == 0 {
+		ctx.JSON(http.StatusNotFound, errorResponse(sql.ErrNoRows))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
@@ -0,0 +1,301 @@
+package api
+
+import (
+	"crypto/subtle"
+	"database/sql"
+	"errors"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	db "github.com/cihanalici/api/db/sqlc"
+	"github.com/cihanalici/api/token"
+	"github.com/cihanalici/api/util"
+	"github.com/gin-gonic/gin"
+	"github.com/lib/pq"
+)
+
+// API keys look like ak_<prefix>_<secret>. The prefix is stored in clear to
+// find the key, the whole key only as a hash.
+const (
+	apiKeyMarker     = "ak"
+	apiKeyPrefixSize = 6
+	apiKeySecretSize = 32
+)
+
+const (
+	scopeRead  = "read"
+	scopeWrite = "write"
+)
+
+// apiKeyScopes lists every scope an API key can be given
+var apiKeyScopes = func() []string {
+	var scopes []string
+	for _, group := range []accessGroup{catalogManagers, orderManagers, userManagers} {
+		scopes = append(scopes, group.scope+":"+scopeRead, group.scope+":"+scopeWrite)
+	}
+	return scopes
+}()
+
+// apiKeyFromRequest returns the API key sent in the X-API-Key header or with
+// the ApiKey authorization scheme
+func apiKeyFromRequest(ctx *gin.Context) (string, bool) {
+	if apiKey := ctx.GetHeader(apiKeyHeaderKey); apiKey != "" {
+		return apiKey, true
+	}
+
+	fields := strings.Fields(ctx.GetHeader(authorizationHeaderKey))
+	if len(fields) == 2 && strings.ToLower(fields[0]) == authorizationTypeApiKey {
+		return fields[1], true
+	}
+
+	return "", false
+}
+
+// authenticateApiKey checks the key and lets the request through with a
+// payload that acts for the key's creator within the key's scopes. Keys stop
+// working once their creator is deleted or may no longer manage keys.
+func authenticateApiKey(ctx *gin.Context, store db.Store, rawKey string) {
+	parts := strings.Split(rawKey, "_")
+	if len(parts) != 3 || parts[0] != apiKeyMarker {
+		ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(util.ErrInvalidApiKey))
+		return
+	}
+
+	apiKey, err := store.GetApiKeyByPrefix(ctx, parts[1])
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(util.ErrInvalidApiKey))
+			return
+		}
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	if subtle.ConstantTimeCompare([]byte(token.HashToken(rawKey)), []byte(apiKey.KeyHash)) != 1 || apiKey.RevokedAt.Valid {
+		ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(util.ErrInvalidApiKey))
+		return
+	}
+
+	if apiKey.ExpiresAt.Valid && time.Now().After(apiKey.ExpiresAt.Time) {
+		ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(util.ErrExpiredToken))
+		return
+	}
+
+	creator, err := store.GetUserById(ctx, apiKey.CreatedBy)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	if err != nil || !slices.Contains(userManagers.roles, creator.Role) {
+		ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(util.ErrInvalidApiKey))
+		return
+	}
+
+	if err := store.TouchApiKey(ctx, apiKey.ID); err != nil {
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	payload := &token.Payload{
+		Type:      token.TokenTypeApiKey,
+		UserID:    apiKey.CreatedBy,
+		IssuedAt:  apiKey.CreatedAt,
+		ExpiredAt: apiKey.ExpiresAt.Time,
+		Scopes:    apiKey.Scopes,
+	}
+
+	ctx.Set(authorizationPayloadKey, payload)
+
+	ctx.Set("userId", payload.UserID)
+	ctx.Next()
+}
+
+type apiKeyResponse struct {
+	ID         int32      `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Scopes     []string   `json:"scopes"`
+	CreatedBy  int32      `json:"created_by"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+func nullTimePtr(t sql.NullTime) *time.Time {
+	if !t.Valid {
+		return nil
+	}
+	return &t.Time
+}
+
+func newApiKeyResponse(apiKey db.ApiKey) apiKeyResponse {
+	return apiKeyResponse{
+		ID:         apiKey.ID,
+		Name:       apiKey.Name,
+		Prefix:     apiKey.Prefix,
+		Scopes:     apiKey.Scopes,
+		CreatedBy:  apiKey.CreatedBy,
+		ExpiresAt:  nullTimePtr(apiKey.ExpiresAt),
+		LastUsedAt: nullTimePtr(apiKey.LastUsedAt),
+		RevokedAt:  nullTimePtr(apiKey.RevokedAt),
+		CreatedAt:  apiKey.CreatedAt,
+	}
+}
+
+// CreateApiKey godoc
+// @Summary Create an API key
+// @Description Mint a named key for server-to-server integrations. It acts for the creating admin within its scopes (catalog, orders and users, each :read or :write). The key is returned only once.
+// @Tags api-keys
+// @Accept json
+// @Produce json
+// @Param request body createApiKeyRequest true "Name, scopes and expiry"
+// @Success 200 {object} createApiKeyResponse
+// @Router /api-keys [post]
+
+type createApiKeyRequest struct {
+	Name      string     `json:"name" binding:"required,max=255"`
+	Scopes    []string   `json:"scopes" binding:"required,min=1"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+type createApiKeyResponse struct {
+	Key    string         `json:"key"`
+	ApiKey apiKeyResponse `json:"api_key"`
+}
+
+func (server *Server) createApiKey(ctx *gin.Context) {
+	var req createApiKeyRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	for _, scope := range req.Scopes {
+		if !slices.Contains(apiKeyScopes, scope) {
+			ctx.JSON(http.StatusBadRequest, errorResponse(util.ErrInvalidScope))
+			return
+		}
+	}
+
+	var expiresAt sql.NullTime
+	if req.ExpiresAt != nil {
+		if !req.ExpiresAt.After(time.Now()) {
+			ctx.JSON(http.StatusBadRequest, errorResponse(util.ErrExpiredToken))
+			return
+		}
+		expiresAt = sql.NullTime{Time: *req.ExpiresAt, Valid: true}
+	}
+
+	prefix, err := token.GenerateRandomToken(apiKeyPrefixSize)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	secret, err := token.GenerateRandomToken(apiKeySecretSize)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	rawKey := strings.Join([]string{apiKeyMarker, prefix, secret}, "_")
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	scopes := slices.Clone(req.Scopes)
+	slices.Sort(scopes)
+
+	apiKey, err := server.store.CreateApiKey(ctx, db.CreateApiKeyParams{
+		Name:      req.Name,
+		Prefix:    prefix,
+		KeyHash:   token.HashToken(rawKey),
+		Scopes:    slices.Compact(scopes),
+		CreatedBy: authPayload.UserID,
+		ExpiresAt: expiresAt,
+	})
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code.Name() == "unique_violation" {
+			ctx.JSON(http.StatusConflict, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, createApiKeyResponse{
+		Key:    rawKey,
+		ApiKey: newApiKeyResponse(apiKey),
+	})
+}
+
+// ListApiKeys godoc
+// @Summary List API keys
+// @Tags api-keys
+// @Produce json
+// @Param page_id query int true "Page ID"
+// @Param page_size query int true "Page Size"
+// @Success 200 {array} apiKeyResponse
+// @Router /api-keys [get]
+
+type listApiKeysRequest struct {
+	PageID   int32 `form:"page_id" binding:"required,min=1"`
+	PageSize int32 `form:"page_size" binding:"required,min=5,max=50"`
+}
+
+func (server *Server) listApiKeys(ctx *gin.Context) {
+	var req listApiKeysRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	apiKeys, err := server.store.ListApiKeys(ctx, db.ListApiKeysParams{
+		Limit:  req.PageSize,
+		Offset: (req.PageID - 1) * req.PageSize,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	rsp := make([]apiKeyResponse, 0, len(apiKeys))
+	for _, apiKey := range apiKeys {
+		rsp = append(rsp, newApiKeyResponse(apiKey))
+	}
+
+	ctx.JSON(http.StatusOK, rsp)
+}
+
+// RevokeApiKey godoc
+// @Summary Revoke an API key
+// @Tags api-keys
+// @Produce json
+// @Param id path int true "API key ID"
+// @Success 200 {object} apiKeyResponse
+// @Router /api-keys/{id} [delete]
+
+type apiKeyUriRequest struct {
+	ID int32 `uri:"id" binding:"required,min=1"`
+}
+
+func (server *Server) revokeApiKey(ctx *gin.Context) {
+	var uri apiKeyUriRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	apiKey, err := server.store.RevokeApiKey(ctx, uri.ID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newApiKeyResponse(apiKey))
+}
@@ -0,0 +1,91 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	db "github.com/cihanalici/api/db/sqlc"
+	"github.com/cihanalici/api/mail"
+	"github.com/cihanalici/api/payments"
+	"github.com/cihanalici/api/token"
+	"github.com/cihanalici/api/util"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+const testApiKey = "ak_abc123_secret"
+
+// apiKeyStore knows one key created by user 1; calling anything else panics
+type apiKeyStore struct {
+	db.Store
+	creator *db.User
+}
+
+func (store *apiKeyStore) GetApiKeyByPrefix(ctx context.Context, prefix string) (db.ApiKey, error) {
+	return db.ApiKey{ID: 1, Prefix: prefix, KeyHash: token.HashToken(testApiKey), CreatedBy: 1, Scopes: []string{"users:write"}}, nil
+}
+
+func (store *apiKeyStore) GetUserById(ctx context.Context, id int32) (db.User, error) {
+	if store.creator == nil {
+		return db.User{}, sql.ErrNoRows
+	}
+	return *store.creator, nil
+}
+
+func (store *apiKeyStore) TouchApiKey(ctx context.Context, id int32) error {
+	return nil
+}
+
+func TestAuthenticateApiKeyChecksCreator(t *testing.T) {
+	testCases := []struct {
+		name          string
+		creator       *db.User
+		expectAllowed bool
+	}{
+		{name: "Admin", creator: &db.User{ID: 1, Role: util.AdminRole}, expectAllowed: true},
+		{name: "Demoted", creator: &db.User{ID: 1, Role: util.StaffRole}},
+		{name: "Deleted"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			store := &apiKeyStore{creator: tc.creator}
+
+			recorder := httptest.NewRecorder()
+			ctx, _ := gin.CreateTestContext(recorder)
+			ctx.Request = httptest.NewRequest(http.MethodGet, "/users", nil)
+
+			authenticateApiKey(ctx, store, testApiKey)
+
+			require.Equal(t, tc.expectAllowed, !ctx.IsAborted())
+			if !tc.expectAllowed {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			}
+		})
+	}
+}
+
+func TestApiKeyCannotChangePrivileges(t *testing.T) {
+	config := util.Config{TokenSymmetricKey: "12345678901234567890123456789012"}
+	store := &apiKeyStore{creator: &db.User{ID: 1, Role: util.AdminRole}}
+
+	server, err := NewServer(config, store, mail.NewMemoryMailer(), payments.NewFakeGateway())
+	require.NoError(t, err)
+
+	for _, route := range [][2]string{
+		{http.MethodPut, "/users/2/role"},
+		{http.MethodDelete, "/users/2/mfa"},
+	} {
+		t.Run(route[0]+route[1], func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			request := httptest.NewRequest(route[0], route[1], nil)
+			request.Header.Set(apiKeyHeaderKey, testApiKey)
+
+			server.router.ServeHTTP(recorder, request)
+			require.Equal(t, http.StatusForbidden, recorder.Code)
+		})
+	}
+}
@@ -0,0 +1,449 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	db "github.com/cihanalici/api/db/sqlc"
+	"github.com/cihanalici/api/promotions"
+	"github.com/cihanalici/api/token"
+	"github.com/cihanalici/api/util"
+	"github.com/gin-gonic/gin"
+)
+
+// guest carts are identified by an opaque token sent in this header
+const cartTokenHeaderKey = "X-Cart-Token"
+
+type cartItemResponse struct {
+	ID               int32     `json:"id"`
+	ProductVariantID int32     `json:"product_variant_id"`
+	ProductID        int32     `json:"product_id"`
+	ProductName      string    `json:"product_name"`
+	Color            string    `json:"color"`
+	Size             string    `json:"size"`
+	Quantity         int32     `json:"quantity"`
+	UnitPrice        string    `json:"unit_price"`
+	LineTotal        string    `json:"line_total"`
+	DiscountAmount   string    `json:"discount_amount"`
+	Stock            int32     `json:"stock"`
+	Warning          string    `json:"warning,omitempty"`
+	CreatedAt        time.Time `json:"created_at"`
+	UpdatedAt        time.Time `json:"updated_at"`
+	// Discounts explains DiscountAmount, one entry per promotion
+	Discounts []db.OrderItemDiscount `json:"discounts"`
+}
+
+// cartResponse previews the automatic promotions checkout will apply, a
+// coupon is only taken into account at checkout
+type cartResponse struct {
+	ID             int32              `json:"id"`
+	CartToken      string             `json:"cart_token,omitempty"`
+	Items          []cartItemResponse `json:"items"`
+	Subtotal       string             `json:"subtotal"`
+	DiscountAmount string             `json:"discount_amount"`
+	Total          string             `json:"total"`
+}
+
+// emptyCartNotation renders a cart that was not created yet
+func emptyCartNotation() cartResponse {
+	return cartResponse{
+		Items:          []cartItemResponse{},
+		Subtotal:       util.FormatMoney(0),
+		DiscountAmount: util.FormatMoney(0),
+		Total:          util.FormatMoney(0),
+	}
+}
+
+// stockWarning explains why a cart line cannot be checked out as is
+func stockWarning(quantity, stock int32) string {
+	switch {
+	case stock <= 0:
+		return "out of stock"
+	case quantity > stock:
+		return fmt.Sprintf("only %d left in stock", stock)
+	}
+	return ""
+}
+
+// cartNotation renders a cart with live variant prices and stock and the
+// discounts of the promotions active now
+func (server *Server) cartNotation(ctx *gin.Context, cart db.Cart) (cartResponse, error) {
+	rsp := cartResponse{
+		ID:        cart.ID,
+		CartToken: cart.Token.String,
+		Items:     []cartItemResponse{},
+	}
+
+	rows, err := server.store.ListCartItemDetailsByCartId(ctx, cart.ID)
+	if err != nil {
+		return rsp, err
+	}
+
+	active, err := server.store.ListActivePromotions(ctx)
+	if err != nil {
+		return rsp, err
+	}
+
+	rules, err := db.PromotionRules(active)
+	if err != nil {
+		return rsp, err
+	}
+
+	lines := make([]promotions.Line, len(rows))
+	var subtotal int64
+	for i, row := range rows {
+		unitPrice, err := util.ParseMoney(row.Price)
+		if err != nil {
+			return rsp, err
+		}
+		subtotal += unitPrice * int64(row.Quantity)
+
+		lines[i] = promotions.Line{
+			ProductID:  row.ProductID,
+			CategoryID: row.CategoryID,
+			Quantity:   row.Quantity,
+			UnitPrice:  unitPrice,
+		}
+	}
+
+	result := promotions.Evaluate(rules, lines)
+	for i, row := range rows {
+		discounts := make([]db.OrderItemDiscount, len(result.Adjustments[i]))
+		for j, adjustment := range result.Adjustments[i] {
+			discounts[j] = db.PromotionDiscount(adjustment)
+		}
+
+		rsp.Items = append(rsp.Items, cartItemResponse{
+			ID:               row.ID,
+			ProductVariantID: row.ProductVariantID,
+			ProductID:        row.ProductID,
+			ProductName:      row.ProductName,
+			Color:            row.Color,
+			Size:             row.Size,
+			Quantity:         row.Quantity,
+			UnitPrice:        row.Price,
+			LineTotal:        util.FormatMoney(lines[i].UnitPrice * int64(row.Quantity)),
+			DiscountAmount:   util.FormatMoney(result.Discounts[i]),
+			Discounts:        discounts,
+			Stock:            row.Stock,
+			Warning:          stockWarning(row.Quantity, row.Stock),
+			CreatedAt:        row.CreatedAt,
+			UpdatedAt:        row.UpdatedAt,
+		})
+	}
+
+	rsp.Subtotal = util.FormatMoney(subtotal)
+	rsp.DiscountAmount = util.FormatMoney(result.Total)
+	rsp.Total = util.FormatMoney(subtotal - result.Total)
+	return rsp, nil
+}
+
+// currentCart returns the cart of the authenticated user, or the guest cart
+// named by the cart token header. When create is true a missing cart is
+// created; otherwise sql.ErrNoRows is returned.
+func (server *Server) currentCart(ctx *gin.Context, create bool) (db.Cart, error) {
+	if payload, ok := ctx.Get(authorizationPayloadKey); ok {
+		userID := sql.NullInt32{Int32: payload.(*token.Payload).UserID, Valid: true}
+
+		cart, err := server.store.GetCartByUserId(ctx, userID)
+		if errors.Is(err, sql.ErrNoRows) && create {
+			return server.store.CreateCart(ctx, db.CreateCartParams{UserID: userID})
+		}
+		return cart, err
+	}
+
+	if cartToken := ctx.GetHeader(cartTokenHeaderKey); cartToken != "" {
+		cart, err := server.store.GetCartByToken(ctx, sql.NullString{String: cartToken, Valid: true})
+		if !errors.Is(err, sql.ErrNoRows) || !create {
+			return cart, err
+		}
+	}
+
+	if !create {
+		return db.Cart{}, sql.ErrNoRows
+	}
+
+	cartToken, err := token.GenerateRandomToken(32)
+	if err != nil {
+		return db.Cart{}, err
+	}
+
+	return server.store.CreateCart(ctx, db.CreateCartParams{
+		Token: sql.NullString{String: cartToken, Valid: true},
+	})
+}
+
+// GetCart godoc
+// @Summary Get the current cart
+// @Description Get the cart of the logged in user, or the guest cart named by the X-Cart-Token header. discount_amount and discounts preview the automatic promotions checkout will apply.
+// @Tags cart
+// @Produce json
+// @Success 200 {object} cartResponse
+// @Router /cart [get]
+func (server *Server) getCart(ctx *gin.Context) {
+	cart, err := server.currentCart(ctx, false)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusOK, emptyCartNotation())
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	rsp, err := server.cartNotation(ctx, cart)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, rsp)
+}
+
+// AddCartItem godoc
+// @Summary Add a product variant to the cart
+// @Description Add a product variant to the cart, creating the cart if needed. A line holds at most 100 units, adding more keeps it at 100.
+// @Tags cart
+// @Accept json
+// @Produce json
+// @Param request body addCartItemRequest true "Cart item"
+// @Success 200 {object} cartResponse
+// @Router /cart/items [post]
+
+// quantities are capped at db.MaxCartItemQuantity
+type addCartItemRequest struct {
+	ProductVariantID int32 `json:"product_variant_id" binding:"required,min=1"`
+	Quantity         int32 `json:"quantity" binding:"required,min=1,max=100"`
+}
+
+func (server *Server) addCartItem(ctx *gin.Context) {
+	var req addCartItemRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	variant, err := server.store.GetProductVariantById(ctx, req.ProductVariantID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	if variant.ArchivedAt.Valid {
+		ctx.JSON(http.StatusConflict, errorResponse(util.ErrVariantUnavailable))
+		return
+	}
+
+	cart, err := server.currentCart(ctx, true)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	_, err = server.store.AddCartItem(ctx, db.AddCartItemParams{
+		CartID:           cart.ID,
+		ProductVariantID: req.ProductVariantID,
+		Quantity:         req.Quantity,
+		MaxQuantity:      db.MaxCartItemQuantity,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	rsp, err := server.cartNotation(ctx, cart)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, rsp)
+}
+
+// cartItemOfCurrentCart loads a cart item and makes sure it belongs to the
+// caller's cart. It returns false after writing the response.
+func (server *Server) cartItemOfCurrentCart(ctx *gin.Context, cartItemID int32) (db.Cart, db.CartItem, bool) {
+	cart, err := server.currentCart(ctx, false)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return cart, db.CartItem{}, false
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return cart, db.CartItem{}, false
+	}
+
+	cartItem, err := server.store.GetCartItemById(ctx, cartItemID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return cart, cartItem, false
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return cart, cartItem, false
+	}
+
+	if cartItem.CartID != cart.ID {
+		ctx.JSON(http.StatusNotFound, errorResponse(sql.ErrNoRows))
+		return cart, cartItem, false
+	}
+
+	return cart, cartItem, true
+}
+
+// UpdateCartItem godoc
+// @Summary Change the quantity of a cart item
+// @Tags cart
+// @Accept json
+// @Produce json
+// @Param id path int true "Cart Item ID"
+// @Param request body updateCartItemRequest true "Quantity"
+// @Success 200 {object} cartResponse
+// @Router /cart/items/{id} [put]
+
+type cartItemUriRequest struct {
+	ID int32 `uri:"id" binding:"required,min=1"`
+}
+
+type updateCartItemRequest struct {
+	Quantity int32 `json:"quantity" binding:"required,min=1,max=100"`
+}
+
+func (server *Server) updateCartItem(ctx *gin.Context) {
+	var uri cartItemUriRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var req updateCartItemRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	cart, cartItem, ok := server.cartItemOfCurrentCart(ctx, uri.ID)
+	if !ok {
+		return
+	}
+
+	_, err := server.store.UpdateCartItemQuantity(ctx, db.UpdateCartItemQuantityParams{
+		ID:       cartItem.ID,
+		Quantity: req.Quantity,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	rsp, err := server.cartNotation(ctx, cart)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, rsp)
+}
+
+// DeleteCartItem godoc
+// @Summary Remove an item from the cart
+// @Tags cart
+// @Produce json
+// @Param id path int true "Cart Item ID"
+// @Success 200 {object} cartResponse
+// @Router /cart/items/{id} [delete]
+func (server *Server) deleteCartItem(ctx *gin.Context) {
+	var uri cartItemUriRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	cart, cartItem, ok := server.cartItemOfCurrentCart(ctx, uri.ID)
+	if !ok {
+		return
+	}
+
+	err := server.store.DeleteCartItem(ctx, cartItem.ID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	rsp, err := server.cartNotation(ctx, cart)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, rsp)
+}
+
+// CheckoutCart godoc
+// @Summary Turn the cart into an order
+// @Description Create an order from the logged in user's cart and empty the cart. Without a body the default addresses are used and the order is left to be paid.
+// @Tags cart
+// @Accept json
+// @Produce json
+// @Param request body checkoutCartRequest false "Addresses and payment method"
+// @Success 200 {object} orderResponse
+// @Router /cart/checkout [post]
+
+type checkoutCartRequest struct {
+	checkoutAddressRequest
+	PaymentMethod string `json:"payment_method" binding:"max=255"`
+	CouponCode    string `json:"coupon_code" binding:"max=50"`
+}
+
+func (server *Server) checkoutCart(ctx *gin.Context) {
+	var req checkoutCartRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if !server.requireVerifiedEmail(ctx, authPayload.UserID) {
+		return
+	}
+
+	cart, err := server.currentCart(ctx, false)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusBadRequest, errorResponse(util.ErrEmptyOrder))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	shipping, billing, ok := server.orderAddresses(ctx, authPayload.UserID, req.checkoutAddressRequest)
+	if !ok {
+		return
+	}
+
+	result, err := server.store.CartCheckoutTx(ctx, db.CartCheckoutTxParams{
+		CartID:          cart.ID,
+		UserID:          authPayload.UserID,
+		ShippingAddress: shipping,
+		BillingAddress:  billing,
+		ShippingAmount:  server.config.ShippingFlatRate,
+		CouponCode:      req.CouponCode,
+	})
+	if err != nil {
+		ctx.JSON(checkoutErrorStatus(err), errorResponse(err))
+		return
+	}
+
+	server.sendOrderConfirmation(ctx, result)
+
+	ctx.JSON(http.StatusOK, server.payAtCheckout(ctx, result, authPayload.UserID, req.PaymentMethod))
+}
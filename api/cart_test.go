@@ -0,0 +1,73 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	db "github.com/cihanalici/api/db/sqlc"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+// cartStore knows the lines of one cart and the active promotions; calling
+// anything else panics
+type cartStore struct {
+	db.Store
+	rows       []db.ListCartItemDetailsByCartIdRow
+	promotions []db.Promotion
+}
+
+func (store *cartStore) ListCartItemDetailsByCartId(ctx context.Context, cartID int32) ([]db.ListCartItemDetailsByCartIdRow, error) {
+	return store.rows, nil
+}
+
+func (store *cartStore) ListActivePromotions(ctx context.Context) ([]db.Promotion, error) {
+	return store.promotions, nil
+}
+
+func TestCartNotationAppliesPromotions(t *testing.T) {
+	store := &cartStore{
+		rows: []db.ListCartItemDetailsByCartIdRow{
+			{ID: 1, ProductID: 3, CategoryID: 5, Quantity: 2, Price: "10.00", Stock: 10},
+			{ID: 2, ProductID: 4, CategoryID: 6, Quantity: 1, Price: "7.50", Stock: 10},
+		},
+		promotions: []db.Promotion{
+			{ID: 9, Name: "2 for 1", Type: "buy_x_get_y", Conditions: []byte(`{"product_ids":[3],"buy":1,"get":1}`), IsActive: true},
+		},
+	}
+	server := &Server{store: store}
+	ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+
+	rsp, err := server.cartNotation(ctx, db.Cart{ID: 1})
+	require.NoError(t, err)
+
+	require.Equal(t, "27.50", rsp.Subtotal)
+	require.Equal(t, "10.00", rsp.DiscountAmount)
+	require.Equal(t, "17.50", rsp.Total)
+
+	require.Len(t, rsp.Items, 2)
+	require.Equal(t, "10.00", rsp.Items[0].DiscountAmount)
+	require.Len(t, rsp.Items[0].Discounts, 1)
+	require.Equal(t, int32(9), rsp.Items[0].Discounts[0].PromotionID)
+	require.Equal(t, "0.00", rsp.Items[1].DiscountAmount)
+	require.Empty(t, rsp.Items[1].Discounts)
+}
+
+func TestCartItemQuantityIsCapped(t *testing.T) {
+	server := &Server{store: &cartStore{}}
+
+	for name, handler := range map[string]gin.HandlerFunc{"Add": server.addCartItem, "Update": server.updateCartItem} {
+		t.Run(name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			ctx, _ := gin.CreateTestContext(recorder)
+			ctx.Request = httptest.NewRequest(http.MethodPost, "/cart/items", strings.NewReader(`{"product_variant_id":1,"quantity":2147483647}`))
+			ctx.Params = gin.Params{{Key: "id", Value: "1"}}
+
+			handler(ctx)
+			require.Equal(t, http.StatusBadRequest, recorder.Code)
+		})
+	}
+}
@@ -0,0 +1,354 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	db "github.com/cihanalici/api/db/sqlc"
+	"github.com/cihanalici/api/util"
+	"github.com/gin-gonic/gin"
+	"github.com/lib/pq"
+)
+
+type couponResponse struct {
+	ID             int32      `json:"id"`
+	Code           string     `json:"code"`
+	Description    string     `json:"description"`
+	Type           string     `json:"type"`
+	Value          string     `json:"value"`
+	MinOrderAmount string     `json:"min_order_amount"`
+	StartsAt       *time.Time `json:"starts_at"`
+	EndsAt         *time.Time `json:"ends_at"`
+	UsageLimit     *int32     `json:"usage_limit"`
+	PerUserLimit   *int32     `json:"per_user_limit"`
+	IsActive       bool       `json:"is_active"`
+	ProductIDs     []int32    `json:"product_ids"`
+	CategoryIDs    []int32    `json:"category_ids"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+func nullInt32Ptr(n sql.NullInt32) *int32 {
+	if !n.Valid {
+		return nil
+	}
+	return &n.Int32
+}
+
+func couponNotation(coupon db.Coupon, productIDs, categoryIDs []int32) couponResponse {
+	return couponResponse{
+		ID:             coupon.ID,
+		Code:           coupon.Code,
+		Description:    coupon.Description,
+		Type:           coupon.Type,
+		Value:          coupon.Value,
+		MinOrderAmount: coupon.MinOrderAmount,
+		StartsAt:       nullTimePtr(coupon.StartsAt),
+		EndsAt:         nullTimePtr(coupon.EndsAt),
+		UsageLimit:     nullInt32Ptr(coupon.UsageLimit),
+		PerUserLimit:   nullInt32Ptr(coupon.PerUserLimit),
+		IsActive:       coupon.IsActive,
+		ProductIDs:     productIDs,
+		CategoryIDs:    categoryIDs,
+		CreatedAt:      coupon.CreatedAt,
+		UpdatedAt:      coupon.UpdatedAt,
+	}
+}
+
+// couponErrorStatus maps a duplicate code to a conflict and an unknown
+// product or category to a bad request
+func couponErrorStatus(err error) int {
+	if errors.Is(err, sql.ErrNoRows) {
+		return http.StatusNotFound
+	}
+	if pqErr, ok := err.(*pq.Error); ok {
+		switch pqErr.Code.Name() {
+		case "unique_violation":
+			return http.StatusConflict
+		case "foreign_key_violation":
+			return http.StatusBadRequest
+		}
+	}
+	return http.StatusInternalServerError
+}
+
+// CreateCoupon godoc
+// @Summary Create a coupon
+// @Tags coupons
+// @Description create a discount code. value is a percentage for percentage coupons, an amount for fixed ones and unused for free_shipping. Without product_ids and category_ids the coupon applies to every product.
+// @Accept  json
+// @Produce  json
+// @Param input body couponRequest true "Coupon"
+// @Success 201 {object} couponResponse
+// @Router /coupons [post]
+
+type couponRequest struct {
+	Code           string     `json:"code" binding:"required,max=50"`
+	Description    string     `json:"description" binding:"max=500"`
+	Type           string     `json:"type" binding:"required,oneof=percentage fixed free_shipping"`
+	Value          string     `json:"value"`
+	MinOrderAmount string     `json:"min_order_amount"`
+	StartsAt       *time.Time `json:"starts_at"`
+	EndsAt         *time.Time `json:"ends_at"`
+	UsageLimit     *int32     `json:"usage_limit" binding:"omitempty,min=1"`
+	PerUserLimit   *int32     `json:"per_user_limit" binding:"omitempty,min=1"`
+	// IsActive defaults to true
+	IsActive    *bool   `json:"is_active"`
+	ProductIDs  []int32 `json:"product_ids" binding:"omitempty,dive,min=1"`
+	CategoryIDs []int32 `json:"category_ids" binding:"omitempty,dive,min=1"`
+}
+
+// params validates the amounts and the validity window and converts the
+// request to the stored coupon
+func (req couponRequest) params() (db.CreateCouponParams, error) {
+	arg := db.CreateCouponParams{
+		Code:        db.NormalizeCouponCode(req.Code),
+		Description: req.Description,
+		Type:        req.Type,
+		IsActive:    req.IsActive == nil || *req.IsActive,
+	}
+
+	var value int64
+	if req.Value != "" {
+		var err error
+		value, err = util.ParseMoney(req.Value)
+		if err != nil {
+			return arg, err
+		}
+	}
+
+	switch {
+	case req.Type == util.CouponTypePercentage && (value <= 0 || value > 10000):
+		return arg, fmt.Errorf("%w: a percentage is between 0 and 100", util.ErrInvalidAmount)
+	case req.Type == util.CouponTypeFixed && value <= 0:
+		return arg, fmt.Errorf("%w: a fixed discount must be positive", util.ErrInvalidAmount)
+	case req.Type == util.CouponTypeFreeShipping:
+		value = 0
+	}
+	arg.Value = util.FormatMoney(value)
+
+	var minOrder int64
+	if req.MinOrderAmount != "" {
+		var err error
+		minOrder, err = util.ParseMoney(req.MinOrderAmount)
+		if err != nil {
+			return arg, err
+		}
+		if minOrder < 0 {
+			return arg, fmt.Errorf("%w: %q", util.ErrInvalidAmount, req.MinOrderAmount)
+		}
+	}
+	arg.MinOrderAmount = util.FormatMoney(minOrder)
+
+	if req.StartsAt != nil && req.EndsAt != nil && !req.EndsAt.After(*req.StartsAt) {
+		return arg, errors.New("ends_at must be after starts_at")
+	}
+	if req.StartsAt != nil {
+		arg.StartsAt = sql.NullTime{Time: *req.StartsAt, Valid: true}
+	}
+	if req.EndsAt != nil {
+		arg.EndsAt = sql.NullTime{Time: *req.EndsAt, Valid: true}
+	}
+
+	if req.UsageLimit != nil {
+		arg.UsageLimit = util.ToInt32ToNullInt32(*req.UsageLimit)
+	}
+	if req.PerUserLimit != nil {
+		arg.PerUserLimit = util.ToInt32ToNullInt32(*req.PerUserLimit)
+	}
+
+	return arg, nil
+}
+
+func (server *Server) createCoupon(ctx *gin.Context) {
+	var req couponRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	arg, err := req.params()
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	result, err := server.store.CreateCouponTx(ctx, db.CreateCouponTxParams{
+		CreateCouponParams: arg,
+		ProductIDs:         req.ProductIDs,
+		CategoryIDs:        req.CategoryIDs,
+	})
+	if err != nil {
+		ctx.JSON(couponErrorStatus(err), errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, couponNotation(result.Coupon, result.ProductIDs, result.CategoryIDs))
+}
+
+// GetCoupon godoc
+// @Summary Get a coupon
+// @Tags coupons
+// @Produce  json
+// @Param id path int true "Coupon ID"
+// @Success 200 {object} couponResponse
+// @Router /coupons/{id} [get]
+
+type getCouponRequest struct {
+	ID int32 `uri:"id" binding:"required,min=1"`
+}
+
+func (server *Server) getCoupon(ctx *gin.Context) {
+	var uri getCouponRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	coupon, err := server.store.GetCouponById(ctx, uri.ID)
+	if err != nil {
+		ctx.JSON(couponErrorStatus(err), errorResponse(err))
+		return
+	}
+
+	productIDs, err := server.store.ListCouponProductIds(ctx, coupon.ID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	categoryIDs, err := server.store.ListCouponCategoryIds(ctx, coupon.ID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, couponNotation(coupon, productIDs, categoryIDs))
+}
+
+// ListCoupons godoc
+// @Summary List coupons
+// @Tags coupons
+// @Produce  json
+// @Param page_id query int true "Page ID"
+// @Param page_size query int true "Page Size"
+// @Success 200 {array} couponResponse
+// @Router /coupons [get]
+
+type listCouponsRequest struct {
+	PageID   int32 `form:"page_id" binding:"required,min=1"`
+	PageSize int32 `form:"page_size" binding:"required,min=5,max=10"`
+}
+
+func (server *Server) listCoupons(ctx *gin.Context) {
+	var req listCouponsRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	coupons, err := server.store.ListCoupons(ctx, db.ListCouponsParams{
+		Limit:  req.PageSize,
+		Offset: (req.PageID - 1) * req.PageSize,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	// restrictions are only listed on single coupons
+	rsp := make([]couponResponse, len(coupons))
+	for i, coupon := range coupons {
+		rsp[i] = couponNotation(coupon, nil, nil)
+	}
+
+	ctx.JSON(http.StatusOK, rsp)
+}
+
+// UpdateCoupon godoc
+// @Summary Update a coupon
+// @Tags coupons
+// @Description replace a coupon and its restrictions, orders that already used it keep their discount
+// @Accept  json
+// @Produce  json
+// @Param id path int true "Coupon ID"
+// @Param input body couponRequest true "Coupon"
+// @Success 200 {object} couponResponse
+// @Router /coupons/{id} [put]
+func (server *Server) updateCoupon(ctx *gin.Context) {
+	var uri getCouponRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var req couponRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	arg, err := req.params()
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	result, err := server.store.UpdateCouponTx(ctx, db.UpdateCouponTxParams{
+		UpdateCouponParams: db.UpdateCouponParams{
+			ID:             uri.ID,
+			Code:           arg.Code,
+			Description:    arg.Description,
+			Type:           arg.Type,
+			Value:          arg.Value,
+			MinOrderAmount: arg.MinOrderAmount,
+			StartsAt:       arg.StartsAt,
+			EndsAt:         arg.EndsAt,
+			UsageLimit:     arg.UsageLimit,
+			PerUserLimit:   arg.PerUserLimit,
+			IsActive:       arg.IsActive,
+		},
+		ProductIDs:  req.ProductIDs,
+		CategoryIDs: req.CategoryIDs,
+	})
+	if err != nil {
+		ctx.JSON(couponErrorStatus(err), errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, couponNotation(result.Coupon, result.ProductIDs, result.CategoryIDs))
+}
+
+// DeleteCoupon godoc
+// @Summary Delete a coupon
+// @Tags coupons
+// @Description delete a coupon that was never redeemed, redeemed coupons can only be deactivated
+// @Param id path int true "Coupon ID"
+// @Success 200
+// @Router /coupons/{id} [delete]
+func (server *Server) deleteCoupon(ctx *gin.Context) {
+	var uri getCouponRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if _, err := server.store.GetCouponById(ctx, uri.ID); err != nil {
+		ctx.JSON(couponErrorStatus(err), errorResponse(err))
+		return
+	}
+
+	if err := server.store.DeleteCoupon(ctx, uri.ID); err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code.Name() == "foreign_key_violation" {
+			ctx.JSON(http.StatusConflict, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
@@ -0,0 +1,66 @@
+package api
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cihanalici/api/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCouponRequestParams(t *testing.T) {
+	start := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	end := start.Add(24 * time.Hour)
+	limit := int32(3)
+	inactive := false
+
+	testCases := []struct {
+		name  string
+		req   couponRequest
+		err   bool
+		check func(t *testing.T, req couponRequest)
+	}{
+		{
+			name: "Percentage",
+			req:  couponRequest{Code: " summer10 ", Type: util.CouponTypePercentage, Value: "10", MinOrderAmount: "50", StartsAt: &start, EndsAt: &end, PerUserLimit: &limit},
+			check: func(t *testing.T, req couponRequest) {
+				arg, err := req.params()
+				require.NoError(t, err)
+				require.Equal(t, "SUMMER10", arg.Code)
+				require.Equal(t, "10.00", arg.Value)
+				require.Equal(t, "50.00", arg.MinOrderAmount)
+				require.True(t, arg.StartsAt.Valid)
+				require.True(t, arg.EndsAt.Valid)
+				require.False(t, arg.UsageLimit.Valid)
+				require.Equal(t, limit, arg.PerUserLimit.Int32)
+				require.True(t, arg.IsActive)
+			},
+		},
+		{
+			name: "FreeShippingIgnoresValue",
+			req:  couponRequest{Code: "SHIP", Type: util.CouponTypeFreeShipping, Value: "5", IsActive: &inactive},
+			check: func(t *testing.T, req couponRequest) {
+				arg, err := req.params()
+				require.NoError(t, err)
+				require.Equal(t, "0.00", arg.Value)
+				require.Equal(t, "0.00", arg.MinOrderAmount)
+				require.False(t, arg.IsActive)
+			},
+		},
+		{name: "PercentageAbove100", req: couponRequest{Code: "X", Type: util.CouponTypePercentage, Value: "100.01"}, err: true},
+		{name: "FixedWithoutValue", req: couponRequest{Code: "X", Type: util.CouponTypeFixed}, err: true},
+		{name: "NegativeMinimum", req: couponRequest{Code: "X", Type: util.CouponTypeFixed, Value: "5", MinOrderAmount: "-1"}, err: true},
+		{name: "EndsBeforeStart", req: couponRequest{Code: "X", Type: util.CouponTypeFixed, Value: "5", StartsAt: &end, EndsAt: &start}, err: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.err {
+				_, err := tc.req.params()
+				require.Error(t, err)
+				return
+			}
+			tc.check(t, tc.req)
+		})
+	}
+}
@@ -0,0 +1,156 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	db "github.com/cihanalici/api/db/sqlc"
+	"github.com/cihanalici/api/mail"
+	"github.com/cihanalici/api/token"
+	"github.com/cihanalici/api/util"
+	"github.com/gin-gonic/gin"
+)
+
+// verificationRequestedMessage is returned whether or not the email belongs
+// to an unverified account so the endpoint cannot be used to discover users
+const verificationRequestedMessage = "if an unverified account exists for this email, a verification link has been sent"
+
+// defaultEmailVerificationDuration is used when EMAIL_VERIFICATION_DURATION is
+// not set
+const defaultEmailVerificationDuration = 24 * time.Hour
+
+func (server *Server) emailVerificationDuration() time.Duration {
+	if server.config.EmailVerificationDuration <= 0 {
+		return defaultEmailVerificationDuration
+	}
+	return server.config.EmailVerificationDuration
+}
+
+// sendEmailVerification stores a fresh verification token for the user and
+// emails them the link to confirm their address
+func (server *Server) sendEmailVerification(ctx *gin.Context, user db.User) error {
+	verificationToken, err := token.GenerateRandomToken(32)
+	if err != nil {
+		return err
+	}
+
+	duration := server.emailVerificationDuration()
+
+	_, err = server.store.CreateEmailVerificationTx(ctx, db.CreateEmailVerificationParams{
+		UserID:            user.ID,
+		VerificationToken: token.HashToken(verificationToken),
+		ExpiresAt:         time.Now().Add(duration),
+	})
+	if err != nil {
+		return err
+	}
+
+	server.sendEmail(ctx, user.Email, mail.TemplateEmailVerification, mail.EmailVerificationData{
+		Name:       user.Name,
+		VerifyLink: linkWithToken(server.config.EmailVerificationRedirectURL, verificationToken),
+		ExpiresIn:  duration,
+	})
+
+	return nil
+}
+
+// requireVerifiedEmail stops users with an unverified email address when the
+// config asks for it. It returns false after writing the response.
+func (server *Server) requireVerifiedEmail(ctx *gin.Context, userID int32) bool {
+	if !server.config.RequireVerifiedEmailCheckout {
+		return true
+	}
+
+	user, err := server.store.GetUserById(ctx, userID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return false
+	}
+
+	if !user.EmailVerifiedAt.Valid {
+		ctx.JSON(http.StatusForbidden, errorResponse(util.ErrEmailNotVerified))
+		return false
+	}
+
+	return true
+}
+
+// VerifyEmail godoc
+// @Summary Verify an email address
+// @Description Consume the token from the verification email and mark the address as verified
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param request body verifyEmailRequest true "Verification token"
+// @Success 200 {object} createUserResponse
+// @Router /users/verify-email [post]
+
+type verifyEmailRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+func (server *Server) verifyEmail(ctx *gin.Context) {
+	var req verifyEmailRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	user, err := server.store.VerifyEmailTx(ctx, token.HashToken(req.Token))
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			ctx.JSON(http.StatusBadRequest, errorResponse(util.ErrInvalidToken))
+		case errors.Is(err, util.ErrExpiredToken):
+			ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		default:
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusOK, userResponse(user))
+}
+
+// ResendEmailVerification godoc
+// @Summary Resend the verification email
+// @Description Send a new verification link, invalidating earlier ones. The response is the same whether or not the email has an unverified account.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param request body resendEmailVerificationRequest true "Email"
+// @Success 200 {object} map[string]string
+// @Router /users/resend-verification [post]
+
+type resendEmailVerificationRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+func (server *Server) resendEmailVerification(ctx *gin.Context) {
+	var req resendEmailVerificationRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	user, err := server.store.GetUserByEmail(ctx, req.Email)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusOK, gin.H{"status": verificationRequestedMessage})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	if !user.EmailVerifiedAt.Valid {
+		if err := server.sendEmailVerification(ctx, user); err != nil {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"status": verificationRequestedMessage})
+}
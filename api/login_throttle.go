@@ -0,0 +1,324 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	db "github.com/cihanalici/api/db/sqlc"
+	"github.com/cihanalici/api/mail"
+	"github.com/cihanalici/api/token"
+	"github.com/cihanalici/api/util"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// failed logins are tracked per account (by email, so unknown emails behave
+// exactly like real ones) and per client IP
+const (
+	loginScopeAccount = "account"
+	loginScopeIP      = "ip"
+)
+
+// defaults used when the login throttling settings are not configured
+const (
+	defaultLoginMaxAttempts      = 5
+	defaultLoginMaxAttemptsPerIP = 20
+	defaultLoginAttemptWindow    = 15 * time.Minute
+	defaultLoginLockoutDuration  = 15 * time.Minute
+)
+
+// progressive delay: the first failures are free, after that every failure
+// doubles the wait before the next attempt
+const (
+	loginFreeAttempts = 2
+	loginBaseDelay    = time.Second
+	loginMaxDelay     = 30 * time.Second
+)
+
+// loginRetryDelay is how long a client has to wait after its last failed
+// attempt before it may try again
+func loginRetryDelay(failedAttempts int32) time.Duration {
+	if failedAttempts <= loginFreeAttempts {
+		return 0
+	}
+
+	delay := loginBaseDelay
+	for i := failedAttempts - loginFreeAttempts - 1; i > 0 && delay < loginMaxDelay; i-- {
+		delay *= 2
+	}
+	return min(delay, loginMaxDelay)
+}
+
+type loginLimits struct {
+	maxAttempts      int32
+	maxAttemptsPerIP int32
+	window           time.Duration
+	lockout          time.Duration
+}
+
+func (server *Server) loginLimits() loginLimits {
+	limits := loginLimits{
+		maxAttempts:      int32(server.config.LoginMaxAttempts),
+		maxAttemptsPerIP: int32(server.config.LoginMaxAttemptsPerIP),
+		window:           server.config.LoginAttemptWindow,
+		lockout:          server.config.LoginLockoutDuration,
+	}
+
+	if limits.maxAttempts <= 0 {
+		limits.maxAttempts = defaultLoginMaxAttempts
+	}
+	if limits.maxAttemptsPerIP <= 0 {
+		limits.maxAttemptsPerIP = defaultLoginMaxAttemptsPerIP
+	}
+	if limits.window <= 0 {
+		limits.window = defaultLoginAttemptWindow
+	}
+	if limits.lockout <= 0 {
+		limits.lockout = defaultLoginLockoutDuration
+	}
+
+	return limits
+}
+
+// loginSubjects returns the scope/subject pairs a login attempt counts against
+func loginSubjects(ctx *gin.Context, email string) [][2]string {
+	return [][2]string{
+		{loginScopeAccount, strings.ToLower(email)},
+		{loginScopeIP, ctx.ClientIP()},
+	}
+}
+
+// checkLoginAllowed rejects the attempt while the account or IP is locked or
+// still has to wait after its last failure. It returns false after writing
+// the response.
+func (server *Server) checkLoginAllowed(ctx *gin.Context, email string) bool {
+	limits := server.loginLimits()
+	now := time.Now()
+
+	for _, subject := range loginSubjects(ctx, email) {
+		failure, err := server.store.GetLoginFailure(ctx, db.GetLoginFailureParams{
+			Scope:   subject[0],
+			Subject: subject[1],
+		})
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				continue
+			}
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return false
+		}
+
+		retryAt := failure.LastFailedAt.Add(loginRetryDelay(failure.FailedAttempts))
+		if failure.LastFailedAt.Before(now.Add(-limits.window)) {
+			retryAt = now
+		}
+		if failure.LockedUntil.Valid && failure.LockedUntil.Time.After(retryAt) {
+			retryAt = failure.LockedUntil.Time
+		}
+
+		if retryAt.After(now) {
+			retryAfter := int(math.Ceil(retryAt.Sub(now).Seconds()))
+			ctx.Header("Retry-After", strconv.Itoa(retryAfter))
+			ctx.JSON(http.StatusTooManyRequests, errorResponse(util.ErrTooManyLoginAttempts))
+			return false
+		}
+	}
+
+	return true
+}
+
+// recordLoginFailure counts a failed attempt against the account and the IP
+// and locks whichever reached its limit. The owner of a locked account gets
+// an email with a link to unlock it.
+func (server *Server) recordLoginFailure(ctx *gin.Context, email string, user *db.User) error {
+	limits := server.loginLimits()
+	maxAttempts := map[string]int32{
+		loginScopeAccount: limits.maxAttempts,
+		loginScopeIP:      limits.maxAttemptsPerIP,
+	}
+
+	for _, subject := range loginSubjects(ctx, email) {
+		failure, err := server.store.RecordLoginFailure(ctx, db.RecordLoginFailureParams{
+			Scope:       subject[0],
+			Subject:     subject[1],
+			WindowStart: time.Now().Add(-limits.window),
+		})
+		if err != nil {
+			return err
+		}
+
+		if failure.FailedAttempts < maxAttempts[failure.Scope] {
+			continue
+		}
+
+		_, err = server.store.LockLoginFailure(ctx, db.LockLoginFailureParams{
+			ID:          failure.ID,
+			LockedUntil: sql.NullTime{Time: time.Now().Add(limits.lockout), Valid: true},
+		})
+		if err != nil {
+			return err
+		}
+
+		if failure.Scope == loginScopeAccount && user != nil {
+			server.sendAccountLockedEmail(ctx, *user, limits.lockout)
+		}
+	}
+
+	return nil
+}
+
+// clearAccountLoginFailures forgets the failed attempts of an account. The IP
+// counter is left alone so a valid login cannot be used to reset it.
+func (server *Server) clearAccountLoginFailures(ctx *gin.Context, email string) error {
+	return server.store.DeleteLoginFailure(ctx, db.DeleteLoginFailureParams{
+		Scope:   loginScopeAccount,
+		Subject: strings.ToLower(email),
+	})
+}
+
+func (server *Server) sendAccountLockedEmail(ctx *gin.Context, user db.User, lockout time.Duration) {
+	unlockToken, _, err := server.tokenMaker.CreateToken(user.ID, user.Role, uuid.Nil, token.TokenTypeUnlockAccount, lockout)
+	if err != nil {
+		log.Printf("cannot create unlock token for user %d: %v", user.ID, err)
+		return
+	}
+
+	server.sendEmail(ctx, user.Email, mail.TemplateAccountLocked, mail.AccountLockedData{
+		Name:       user.Name,
+		UnlockLink: linkWithToken(server.config.AccountUnlockRedirectURL, unlockToken),
+		LockedFor:  lockout,
+	})
+}
+
+var (
+	dummyPasswordHashOnce sync.Once
+	dummyPasswordHash     string
+)
+
+// compareDummyPassword spends as long as a real password check so unknown
+// emails cannot be told apart by response time
+func compareDummyPassword(password string) {
+	dummyPasswordHashOnce.Do(func() {
+		dummyPasswordHash, _ = util.HashPassword(uuid.NewString())
+	})
+	_ = util.CheckPassword(password, dummyPasswordHash)
+}
+
+// UnlockAccount godoc
+// @Summary Unlock an account after too many failed logins
+// @Description Use the token from the account locked email to lift the lockout early
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param request body unlockAccountRequest true "Unlock token"
+// @Success 200 {object} map[string]string
+// @Router /users/unlock [post]
+
+type unlockAccountRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+func (server *Server) unlockAccount(ctx *gin.Context) {
+	var req unlockAccountRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	payload, err := server.tokenMaker.VerifyToken(req.Token, token.TokenTypeUnlockAccount)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(util.ErrInvalidToken))
+		return
+	}
+
+	user, err := server.store.GetUserById(ctx, payload.UserID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusBadRequest, errorResponse(util.ErrInvalidToken))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	if err := server.clearAccountLoginFailures(ctx, user.Email); err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"status": "account unlocked"})
+}
+
+// ListLoginLockouts godoc
+// @Summary List failed login counters and lockouts
+// @Description Locked accounts and IPs first, then the most recent failures
+// @Tags users
+// @Produce json
+// @Param page_id query int true "Page ID"
+// @Param page_size query int true "Page Size"
+// @Success 200 {array} db.LoginFailure
+// @Router /login-lockouts [get]
+
+type listLoginLockoutsRequest struct {
+	PageID   int32 `form:"page_id" binding:"required,min=1"`
+	PageSize int32 `form:"page_size" binding:"required,min=5,max=50"`
+}
+
+func (server *Server) listLoginLockouts(ctx *gin.Context) {
+	var req listLoginLockoutsRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	failures, err := server.store.ListLoginFailures(ctx, db.ListLoginFailuresParams{
+		Limit:  req.PageSize,
+		Offset: (req.PageID - 1) * req.PageSize,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, failures)
+}
+
+// ClearLoginLockout godoc
+// @Summary Clear a failed login counter or lockout
+// @Tags users
+// @Produce json
+// @Param id path int true "Login failure ID"
+// @Success 200 {object} map[string]string
+// @Router /login-lockouts/{id} [delete]
+
+type loginLockoutUriRequest struct {
+	ID int32 `uri:"id" binding:"required,min=1"`
+}
+
+func (server *Server) clearLoginLockout(ctx *gin.Context) {
+	var uri loginLockoutUriRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	deleted, err := server.store.DeleteLoginFailureById(ctx, uri.ID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	if deleted == 0 {
+		ctx.JSON(http.StatusNotFound, errorResponse(sql.ErrNoRows))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
@@ -0,0 +1,69 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/cihanalici/api/mail"
+	"github.com/cihanalici/api/payments"
+	"github.com/cihanalici/api/util"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoginRetryDelay(t *testing.T) {
+	testCases := []struct {
+		failedAttempts int32
+		expectedDelay  time.Duration
+	}{
+		{failedAttempts: 0, expectedDelay: 0},
+		{failedAttempts: 1, expectedDelay: 0},
+		{failedAttempts: 2, expectedDelay: 0},
+		{failedAttempts: 3, expectedDelay: time.Second},
+		{failedAttempts: 4, expectedDelay: 2 * time.Second},
+		{failedAttempts: 5, expectedDelay: 4 * time.Second},
+		{failedAttempts: 8, expectedDelay: 30 * time.Second},
+		{failedAttempts: 1000, expectedDelay: 30 * time.Second},
+	}
+
+	for _, tc := range testCases {
+		require.Equal(t, tc.expectedDelay, loginRetryDelay(tc.failedAttempts), "failed attempts: %d", tc.failedAttempts)
+	}
+}
+
+func TestLoginSubjectsIgnoreUntrustedForwardedFor(t *testing.T) {
+	testCases := []struct {
+		name           string
+		trustedProxies []string
+		expectedIP     string
+	}{
+		{name: "NoTrustedProxies", expectedIP: "10.0.0.1"},
+		{name: "TrustedProxy", trustedProxies: []string{"10.0.0.0/8"}, expectedIP: "203.0.113.7"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			config := util.Config{
+				TokenSymmetricKey: "12345678901234567890123456789012",
+				TrustedProxies:    tc.trustedProxies,
+			}
+
+			server, err := NewServer(config, nil, mail.NewMemoryMailer(), payments.NewFakeGateway())
+			require.NoError(t, err)
+
+			var subjects [][2]string
+			server.router.GET("/test/login-subjects", func(ctx *gin.Context) {
+				subjects = loginSubjects(ctx, "User@Example.com")
+			})
+
+			request := httptest.NewRequest(http.MethodGet, "/test/login-subjects", nil)
+			request.RemoteAddr = "10.0.0.1:4321"
+			request.Header.Set("X-Forwarded-For", "203.0.113.7")
+			server.router.ServeHTTP(httptest.NewRecorder(), request)
+
+			require.Equal(t, [][2]string{{loginScopeAccount, "user@example.com"}, {loginScopeIP, tc.expectedIP}}, subjects)
+		})
+	}
+}
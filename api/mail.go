@@ -0,0 +1,72 @@
+package api
+
+import (
+	"fmt"
+	"log"
+
+	db "github.com/cihanalici/api/db/sqlc"
+	"github.com/cihanalici/api/mail"
+	"github.com/cihanalici/api/util"
+	"github.com/gin-gonic/gin"
+)
+
+// sendEmail renders and sends a transactional email. Delivery failures are
+// only logged because the action that triggered the email already succeeded.
+func (server *Server) sendEmail(ctx *gin.Context, to, template string, data any) {
+	msg, err := mail.Render(to, template, data)
+	if err == nil {
+		err = server.mailer.Send(ctx, msg)
+	}
+	if err != nil {
+		log.Printf("cannot send %s email: %v", template, err)
+	}
+}
+
+// sendOrderConfirmation emails the customer the contents of a freshly placed order
+func (server *Server) sendOrderConfirmation(ctx *gin.Context, result db.CheckoutTxResult) {
+	user, err := server.store.GetUserById(ctx, result.Order.UserID)
+	if err != nil {
+		log.Printf("cannot send %s email: %v", mail.TemplateOrderConfirmation, err)
+		return
+	}
+
+	data := mail.OrderConfirmationData{
+		Name:     user.Name,
+		OrderID:  result.Order.ID,
+		Items:    make([]mail.OrderLine, 0, len(result.OrderItems)),
+		Subtotal: result.Order.SubtotalAmount,
+		Total:    result.Order.TotalAmount,
+	}
+
+	if discount, err := util.ParseMoney(result.Order.DiscountAmount); err == nil && discount > 0 {
+		data.Discount = result.Order.DiscountAmount
+	}
+	if shipping, err := util.ParseMoney(result.Order.ShippingAmount); err == nil && shipping > 0 {
+		data.Shipping = result.Order.ShippingAmount
+	}
+
+	for _, item := range OrderItemsNotation(result.OrderItems) {
+		data.Items = append(data.Items, mail.OrderLine{
+			Description: fmt.Sprintf("%s (%s, %s)", item.ProductName, item.Color, item.Size),
+			Quantity:    item.Quantity,
+			UnitPrice:   item.Price,
+			LineTotal:   item.LineTotal,
+		})
+	}
+
+	server.sendEmail(ctx, user.Email, mail.TemplateOrderConfirmation, data)
+}
+
+// sendShippingNotification tells the customer their order is on its way
+func (server *Server) sendShippingNotification(ctx *gin.Context, order db.Order) {
+	user, err := server.store.GetUserById(ctx, order.UserID)
+	if err != nil {
+		log.Printf("cannot send %s email: %v", mail.TemplateShippingNotification, err)
+		return
+	}
+
+	server.sendEmail(ctx, user.Email, mail.TemplateShippingNotification, mail.ShippingNotificationData{
+		Name:    user.Name,
+		OrderID: order.ID,
+	})
+}
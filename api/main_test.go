@@ -5,6 +5,8 @@ import (
 	"testing"
 
 	"github.com/cihanalici/api/db/sqlc"
+	"github.com/cihanalici/api/mail"
+	"github.com/cihanalici/api/payments"
 	"github.com/cihanalici/api/util"
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/require"
@@ -13,7 +15,7 @@ import (
 func newTestServer(t *testing.T, store sqlc.Store) *Server {
 	config := util.Config{}
 
-	server, err := NewServer(config, store)
+	server, err := NewServer(config, store, mail.NewMemoryMailer(), payments.NewFakeGateway())
 	require.NoError(t, err)
 
 	return server
@@ -0,0 +1,513 @@
+package api
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"encoding/base32"
+	"errors"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	db "github.com/cihanalici/api/db/sqlc"
+	"github.com/cihanalici/api/token"
+	"github.com/cihanalici/api/util"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const (
+	defaultMfaIssuer          = "Ecommerce API"
+	defaultMfaPendingDuration = 5 * time.Minute
+	mfaRecoveryCodeCount      = 10
+	mfaRecoveryCodeLength     = 10
+)
+
+var recoveryCodeEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+func (server *Server) mfaIssuer() string {
+	if server.config.MfaIssuer == "" {
+		return defaultMfaIssuer
+	}
+	return server.config.MfaIssuer
+}
+
+func (server *Server) mfaPendingDuration() time.Duration {
+	if server.config.MfaPendingDuration <= 0 {
+		return defaultMfaPendingDuration
+	}
+	return server.config.MfaPendingDuration
+}
+
+// roleRequiresMfa tells whether users with the role must enable two-factor
+// authentication before they can use privileged routes
+func (server *Server) roleRequiresMfa(role string) bool {
+	return slices.Contains(server.config.MfaRequiredRoles, role)
+}
+
+// requireMfaEnrollment rejects users whose role requires two-factor
+// authentication until they enabled it. It must be registered after authMiddleware.
+func (server *Server) requireMfaEnrollment() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		payload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+		if !server.roleRequiresMfa(payload.Role) {
+			ctx.Next()
+			return
+		}
+
+		mfa, err := server.store.GetUserMfa(ctx, payload.UserID)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+
+		if err != nil || !mfa.EnabledAt.Valid {
+			ctx.AbortWithStatusJSON(http.StatusForbidden, errorResponse(util.ErrMfaRequired))
+			return
+		}
+
+		ctx.Next()
+	}
+}
+
+// getEnabledMfa returns the user's enrollment, or ErrMfaNotEnabled when two-factor
+// authentication is not switched on
+func (server *Server) getEnabledMfa(ctx *gin.Context, userID int32) (db.UserMfa, error) {
+	mfa, err := server.store.GetUserMfa(ctx, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return mfa, util.ErrMfaNotEnabled
+		}
+		return mfa, err
+	}
+
+	if !mfa.EnabledAt.Valid {
+		return mfa, util.ErrMfaNotEnabled
+	}
+
+	return mfa, nil
+}
+
+// checkMfaCode accepts either a code from the authenticator app or an unused
+// recovery code. Both can be used only once.
+func (server *Server) checkMfaCode(ctx *gin.Context, mfa db.UserMfa, code, recoveryCode string) (bool, error) {
+	if code != "" {
+		step, ok := util.ValidateTOTP(mfa.Secret, code, time.Now())
+		if !ok {
+			return false, nil
+		}
+
+		used, err := server.store.UseUserMfaStep(ctx, db.UseUserMfaStepParams{
+			UserID:       mfa.UserID,
+			LastUsedStep: step,
+		})
+		return used == 1, err
+	}
+
+	if recoveryCode != "" {
+		used, err := server.store.UseMfaRecoveryCode(ctx, db.UseMfaRecoveryCodeParams{
+			UserID:   mfa.UserID,
+			CodeHash: token.HashToken(normalizeRecoveryCode(recoveryCode)),
+		})
+		return used == 1, err
+	}
+
+	return false, nil
+}
+
+// generateRecoveryCodes returns codes to show to the user once and the hashes
+// to store in their place
+func generateRecoveryCodes() ([]string, []string, error) {
+	codes := make([]string, 0, mfaRecoveryCodeCount)
+	hashes := make([]string, 0, mfaRecoveryCodeCount)
+
+	for i := 0; i < mfaRecoveryCodeCount; i++ {
+		buf := make([]byte, mfaRecoveryCodeLength)
+		if _, err := rand.Read(buf); err != nil {
+			return nil, nil, err
+		}
+
+		code := strings.ToLower(recoveryCodeEncoding.EncodeToString(buf))[:mfaRecoveryCodeLength]
+		half := mfaRecoveryCodeLength / 2
+		codes = append(codes, code[:half]+"-"+code[half:])
+		hashes = append(hashes, token.HashToken(code))
+	}
+
+	return codes, hashes, nil
+}
+
+// normalizeRecoveryCode drops the separator and case so codes can be typed
+// the way they are shown or without the dash
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(code)
+	return strings.NewReplacer("-", "", " ", "").Replace(code)
+}
+
+// SetupMfa godoc
+// @Summary Start two-factor authentication enrollment
+// @Description Create a new TOTP secret. Add it to an authenticator app using the provisioning URI, then confirm it with a code.
+// @Tags users
+// @Produce json
+// @Success 200 {object} setupMfaResponse
+// @Router /users/mfa/setup [post]
+
+type setupMfaResponse struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"`
+}
+
+func (server *Server) setupMfa(ctx *gin.Context) {
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	user, err := server.store.GetUserById(ctx, authPayload.UserID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	secret, err := util.GenerateTOTPSecret()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	// an existing enrollment is only replaced while it is not confirmed yet
+	mfa, err := server.store.UpsertUserMfa(ctx, db.UpsertUserMfaParams{
+		UserID: user.ID,
+		Secret: secret,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusConflict, errorResponse(util.ErrMfaAlreadyEnabled))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, setupMfaResponse{
+		Secret:          mfa.Secret,
+		ProvisioningURI: util.TOTPProvisioningURI(server.mfaIssuer(), user.Email, mfa.Secret),
+	})
+}
+
+// ConfirmMfa godoc
+// @Summary Enable two-factor authentication
+// @Description Confirm the enrollment with a code from the authenticator app. The recovery codes are returned only once.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param request body confirmMfaRequest true "TOTP code"
+// @Success 200 {object} mfaRecoveryCodesResponse
+// @Router /users/mfa/confirm [post]
+
+type confirmMfaRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+type mfaRecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+func (server *Server) confirmMfa(ctx *gin.Context) {
+	var req confirmMfaRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	mfa, err := server.store.GetUserMfa(ctx, authPayload.UserID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusBadRequest, errorResponse(util.ErrMfaNotEnabled))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	if mfa.EnabledAt.Valid {
+		ctx.JSON(http.StatusConflict, errorResponse(util.ErrMfaAlreadyEnabled))
+		return
+	}
+
+	step, ok := util.ValidateTOTP(mfa.Secret, req.Code, time.Now())
+	if !ok {
+		ctx.JSON(http.StatusBadRequest, errorResponse(util.ErrInvalidMfaCode))
+		return
+	}
+
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	_, err = server.store.EnableMfaTx(ctx, db.EnableMfaTxParams{
+		UserID:             mfa.UserID,
+		Step:               step,
+		RecoveryCodeHashes: hashes,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, mfaRecoveryCodesResponse{RecoveryCodes: codes})
+}
+
+// DisableMfa godoc
+// @Summary Disable two-factor authentication
+// @Description Requires the password and either a TOTP code or a recovery code
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param request body disableMfaRequest true "Password and second factor"
+// @Success 200 {object} map[string]string
+// @Router /users/mfa/disable [post]
+
+type disableMfaRequest struct {
+	Password     string `json:"password" binding:"required"`
+	Code         string `json:"code" binding:"required_without=RecoveryCode"`
+	RecoveryCode string `json:"recovery_code"`
+}
+
+func (server *Server) disableMfa(ctx *gin.Context) {
+	var req disableMfaRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	user, err := server.store.GetUserById(ctx, authPayload.UserID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	if err := util.CheckPassword(req.Password, user.Password); err != nil {
+		ctx.JSON(http.StatusUnauthorized, errorResponse(util.ErrInvalidCredentials))
+		return
+	}
+
+	mfa, err := server.getEnabledMfa(ctx, user.ID)
+	if err != nil {
+		if errors.Is(err, util.ErrMfaNotEnabled) {
+			ctx.JSON(http.StatusBadRequest, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ok, err := server.checkMfaCode(ctx, mfa, req.Code, req.RecoveryCode)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, errorResponse(util.ErrInvalidMfaCode))
+		return
+	}
+
+	if _, err := server.store.DeleteUserMfa(ctx, user.ID); err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+// RegenerateMfaRecoveryCodes godoc
+// @Summary Replace the two-factor recovery codes
+// @Description Invalidate every recovery code and return a new set. Requires a TOTP code.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param request body confirmMfaRequest true "TOTP code"
+// @Success 200 {object} mfaRecoveryCodesResponse
+// @Router /users/mfa/recovery-codes [post]
+func (server *Server) regenerateMfaRecoveryCodes(ctx *gin.Context) {
+	var req confirmMfaRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	mfa, err := server.getEnabledMfa(ctx, authPayload.UserID)
+	if err != nil {
+		if errors.Is(err, util.ErrMfaNotEnabled) {
+			ctx.JSON(http.StatusBadRequest, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ok, err := server.checkMfaCode(ctx, mfa, req.Code, "")
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, errorResponse(util.ErrInvalidMfaCode))
+		return
+	}
+
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	if err := server.store.ReplaceMfaRecoveryCodesTx(ctx, mfa.UserID, hashes); err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, mfaRecoveryCodesResponse{RecoveryCodes: codes})
+}
+
+// ResetUserMfa godoc
+// @Summary Remove a user's two-factor authentication
+// @Description For users who lost both their device and their recovery codes. They can enroll again after logging in with their password.
+// @Tags users
+// @Produce json
+// @Param id path int true "User ID"
+// @Success 200 {object} map[string]string
+// @Router /users/{id}/mfa [delete]
+func (server *Server) resetUserMfa(ctx *gin.Context) {
+	var uri getUserRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	deleted, err := server.store.DeleteUserMfa(ctx, uri.ID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	if deleted == 0 {
+		ctx.JSON(http.StatusNotFound, errorResponse(util.ErrMfaNotEnabled))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+type loginMfaRequiredResponse struct {
+	MfaRequired       bool      `json:"mfa_required"`
+	MfaToken          string    `json:"mfa_token"`
+	MfaTokenExpiresAt time.Time `json:"mfa_token_expires_at"`
+}
+
+// startMfaLogin answers a correct password of a user with two-factor
+// authentication with a short lived token for the second step
+func (server *Server) startMfaLogin(ctx *gin.Context, user db.User) {
+	mfaToken, payload, err := server.tokenMaker.CreateToken(
+		user.ID, user.Role, uuid.Nil, token.TokenTypeMfaPending, server.mfaPendingDuration(),
+	)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, loginMfaRequiredResponse{
+		MfaRequired:       true,
+		MfaToken:          mfaToken,
+		MfaTokenExpiresAt: payload.ExpiredAt,
+	})
+}
+
+// LoginUserMfa godoc
+// @Summary Second step of a login with two-factor authentication
+// @Description Exchange the mfa_token returned by /users/login and a TOTP or recovery code for the session tokens
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param request body loginUserMfaRequest true "MFA token and code"
+// @Success 200 {object} loginUserResponse
+// @Router /users/login/mfa [post]
+
+type loginUserMfaRequest struct {
+	MfaToken     string `json:"mfa_token" binding:"required"`
+	Code         string `json:"code" binding:"required_without=RecoveryCode"`
+	RecoveryCode string `json:"recovery_code"`
+	// CartToken identifies a guest cart to merge into the user's cart
+	CartToken string `json:"cart_token"`
+}
+
+func (server *Server) loginUserMfa(ctx *gin.Context) {
+	var req loginUserMfaRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	payload, err := server.tokenMaker.VerifyToken(req.MfaToken, token.TokenTypeMfaPending)
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+
+	// mfa tokens are single use, the finished login revokes them
+	if !checkTokenNotRevoked(ctx, server.store, payload) {
+		return
+	}
+
+	user, err := server.store.GetUserById(ctx, payload.UserID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusUnauthorized, errorResponse(util.ErrInvalidToken))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	if !server.checkLoginAllowed(ctx, user.Email) {
+		return
+	}
+
+	mfa, err := server.getEnabledMfa(ctx, user.ID)
+	if err != nil {
+		if errors.Is(err, util.ErrMfaNotEnabled) {
+			ctx.JSON(http.StatusUnauthorized, errorResponse(util.ErrInvalidToken))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ok, err := server.checkMfaCode(ctx, mfa, req.Code, req.RecoveryCode)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	if !ok {
+		if err := server.recordLoginFailure(ctx, user.Email, &user); err != nil {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+
+		ctx.JSON(http.StatusUnauthorized, errorResponse(util.ErrInvalidMfaCode))
+		return
+	}
+
+	if err := server.revokeToken(ctx, payload); err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	server.completeLogin(ctx, user, req.CartToken)
+}
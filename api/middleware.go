@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"strings"
 
+	db "github.com/cihanalici/api/db/sqlc"
 	"github.com/cihanalici/api/token"
 	"github.com/cihanalici/api/util"
 	"github.com/gin-gonic/gin"
@@ -14,10 +15,19 @@ const (
 	authorizationHeaderKey  = "authorization"
 	authorizationTypeBearer = "bearer"
 	authorizationPayloadKey = "authorization_payload"
+	authorizationTypeApiKey = "apikey"
+	apiKeyHeaderKey         = "X-API-Key"
 )
 
-func authMiddleware(tokenMaker token.Maker) gin.HandlerFunc {
+// authMiddleware requires a valid access token that has not been revoked by a
+// logout, or a valid API key
+func authMiddleware(tokenMaker token.Maker, store db.Store) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
+		if apiKey, ok := apiKeyFromRequest(ctx); ok {
+			authenticateApiKey(ctx, store, apiKey)
+			return
+		}
+
 		authorizationHeader := ctx.GetHeader(authorizationHeaderKey)
 		if len(authorizationHeader) == 0 {
 			err := util.ErrNoAuthorizationHeader
@@ -25,30 +35,118 @@ func authMiddleware(tokenMaker token.Maker) gin.HandlerFunc {
 			return
 		}
 
-		fields := strings.Fields(authorizationHeader)
-		if len(fields) < 2 {
-			err := util.ErrInvalidAuthFormat
+		payload, err := verifyAuthorizationHeader(tokenMaker, authorizationHeader)
+		if err != nil {
 			ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(err))
 			return
 		}
 
-		authorizationType := strings.ToLower(fields[0])
-		if authorizationType != authorizationTypeBearer {
-			err := fmt.Errorf("unsupported authorization type %s", authorizationType)
-			ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(err))
+		if !checkTokenNotRevoked(ctx, store, payload) {
+			return
+		}
+
+		ctx.Set(authorizationPayloadKey, payload)
+
+		ctx.Set("userId", payload.UserID)
+		ctx.Next()
+	}
+}
+
+// optionalAuthMiddleware authenticates the request when an authorization header
+// is present and lets anonymous requests through otherwise
+func optionalAuthMiddleware(tokenMaker token.Maker, store db.Store) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		authorizationHeader := ctx.GetHeader(authorizationHeaderKey)
+		if len(authorizationHeader) == 0 {
+			ctx.Next()
 			return
 		}
 
-		accessToken := fields[1]
-		payload, err := tokenMaker.VerifyToken(accessToken)
+		payload, err := verifyAuthorizationHeader(tokenMaker, authorizationHeader)
 		if err != nil {
 			ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(err))
 			return
 		}
 
+		if !checkTokenNotRevoked(ctx, store, payload) {
+			return
+		}
+
 		ctx.Set(authorizationPayloadKey, payload)
 
 		ctx.Set("userId", payload.UserID)
 		ctx.Next()
 	}
 }
+
+func verifyAuthorizationHeader(tokenMaker token.Maker, authorizationHeader string) (*token.Payload, error) {
+	fields := strings.Fields(authorizationHeader)
+	if len(fields) < 2 {
+		return nil, util.ErrInvalidAuthFormat
+	}
+
+	authorizationType := strings.ToLower(fields[0])
+	if authorizationType != authorizationTypeBearer {
+		return nil, fmt.Errorf("unsupported authorization type %s", authorizationType)
+	}
+
+	accessToken := fields[1]
+	return tokenMaker.VerifyToken(accessToken, token.TokenTypeAccessToken)
+}
+
+// checkTokenNotRevoked rejects access tokens that were revoked by a logout or
+// whose session has been blocked. It returns false after aborting the request.
+func checkTokenNotRevoked(ctx *gin.Context, store db.Store, payload *token.Payload) bool {
+	revoked, err := store.IsTokenRevoked(ctx, db.IsTokenRevokedParams{
+		TokenID:   payload.ID,
+		SessionID: payload.SessionID,
+	})
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, errorResponse(err))
+		return false
+	}
+
+	if revoked {
+		ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(util.ErrRevokedToken))
+		return false
+	}
+
+	return true
+}
+
+// requireAccess aborts the request unless the authenticated user has one of the
+// group's roles, or the API key has its scope. It must be registered after authMiddleware.
+func requireAccess(group accessGroup) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		payload, ok := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+		if !ok {
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(util.ErrUnauthorized))
+			return
+		}
+
+		if hasAccess(payload, group, isWriteRequest(ctx)) {
+			ctx.Next()
+			return
+		}
+
+		err := fmt.Errorf("role %q is not allowed to access this resource", payload.Role)
+		if payload.Type == token.TokenTypeApiKey {
+			err = fmt.Errorf("api key is missing the %q scope", group.scope)
+		}
+		ctx.AbortWithStatusJSON(http.StatusForbidden, errorResponse(err))
+	}
+}
+
+// requireUserToken rejects API keys on routes that act on the caller's own
+// account. It must be registered after authMiddleware.
+func requireUserToken() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		payload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+		if payload.Type == token.TokenTypeApiKey {
+			ctx.AbortWithStatusJSON(http.StatusForbidden, errorResponse(util.ErrApiKeyNotAllowed))
+			return
+		}
+
+		ctx.Next()
+	}
+}
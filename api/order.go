@@ -1,45 +1,69 @@
 package api
 
 import (
+	"database/sql"
+	"errors"
 	"fmt"
 	"strconv"
 	"time"
 
 	db "github.com/cihanalici/api/db/sqlc"
+	"github.com/cihanalici/api/token"
+	"github.com/cihanalici/api/util"
 	"github.com/gin-gonic/gin"
 )
 
+// orderRequest carries no money fields or status; prices and totals are
+// computed server-side from product_variants.price and orders start pending.
+// With a PaymentMethod the new order is paid right away.
 type orderRequest struct {
-	UserID      int32               `json:"user_id" binding:"required"`
-	TotalAmount string              `json:"total_amount" binding:"required"`
-	Status      string              `json:"status" binding:"required"`
-	Items       []orderItemsRequest `json:"items" binding:"required"`
+	Items []orderItemsRequest `json:"items" binding:"required,min=1,dive"`
+	checkoutAddressRequest
+	PaymentMethod string `json:"payment_method" binding:"max=255"`
+	CouponCode    string `json:"coupon_code" binding:"max=50"`
 }
 
 type orderItemsRequest struct {
-	ProductVariantID int32  `json:"product_variant_id"`
-	Quantity         int32  `json:"quantity"`
-	Price            string `json:"price"`
+	ProductVariantID int32 `json:"product_variant_id" binding:"required,min=1"`
+	Quantity         int32 `json:"quantity" binding:"required,min=1"`
 }
 
 type orderResponse struct {
-	ID          int32     `json:"id"`
-	UserID      int32     `json:"user_id"`
-	TotalAmount string    `json:"total_amount"`
-	Status      string    `json:"status"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	ID              int32                `json:"id"`
+	UserID          int32                `json:"user_id"`
+	SubtotalAmount  string               `json:"subtotal_amount"`
+	DiscountAmount  string               `json:"discount_amount"`
+	ShippingAmount  string               `json:"shipping_amount"`
+	TotalAmount     string               `json:"total_amount"`
+	CouponCode      string               `json:"coupon_code,omitempty"`
+	Status          string               `json:"status"`
+	ShippingAddress *postalAddress       `json:"shipping_address"`
+	BillingAddress  *postalAddress       `json:"billing_address"`
+	Items           []OrderItemResponse  `json:"items,omitempty"`
+	Totals          *orderTotalsResponse `json:"totals,omitempty"`
+	Payment         *paymentResponse     `json:"payment,omitempty"`
+	// PaymentError tells why paying at checkout failed, the order is
+	// placed anyway and can be paid again
+	PaymentError string    `json:"payment_error,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
 }
 
 func orderNotation(order db.Order) orderResponse {
 
 	return orderResponse{
-		ID:          order.ID,
-		UserID:      order.UserID,
-		TotalAmount: order.TotalAmount,
-		Status:      order.Status,
-		CreatedAt:   order.CreatedAt,
-		UpdatedAt:   order.UpdatedAt,
+		ID:              order.ID,
+		UserID:          order.UserID,
+		SubtotalAmount:  order.SubtotalAmount,
+		DiscountAmount:  order.DiscountAmount,
+		ShippingAmount:  order.ShippingAmount,
+		TotalAmount:     order.TotalAmount,
+		CouponCode:      order.CouponCode,
+		Status:          order.Status,
+		ShippingAddress: orderAddressNotation(order.ShippingAddress),
+		BillingAddress:  orderAddressNotation(order.BillingAddress),
+		CreatedAt:       order.CreatedAt,
+		UpdatedAt:       order.UpdatedAt,
 	}
 }
 
@@ -56,7 +80,7 @@ func ordersNotation(orders []db.Order) []orderResponse {
 // CreateOrder godoc
 // @Summary Create a new order
 // @Tags orders
-// @Description create a new order
+// @Description create a new order, the shipping and billing addresses are copied onto it
 // @Accept  json
 // @Produce  json
 // @Param input body orderRequest true "Order Request"
@@ -70,54 +94,99 @@ func (server *Server) createOrder(ctx *gin.Context) {
 		return
 	}
 
-	arg := db.CreateOrderParams{
-		UserID:      req.UserID,
-		TotalAmount: req.TotalAmount,
-		Status:      req.Status,
+	items := make([]db.CheckoutItem, len(req.Items))
+	for i, item := range req.Items {
+		items[i] = db.CheckoutItem{
+			ProductVariantID: item.ProductVariantID,
+			Quantity:         item.Quantity,
+		}
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if !server.requireVerifiedEmail(ctx, authPayload.UserID) {
+		return
+	}
+
+	shipping, billing, ok := server.orderAddresses(ctx, authPayload.UserID, req.checkoutAddressRequest)
+	if !ok {
+		return
 	}
 
-	order, err := server.store.CreateOrder(ctx, arg)
+	result, err := server.store.CheckoutTx(ctx, db.CheckoutTxParams{
+		UserID:          authPayload.UserID,
+		Items:           items,
+		ShippingAddress: shipping,
+		BillingAddress:  billing,
+		ShippingAmount:  server.config.ShippingFlatRate,
+		CouponCode:      req.CouponCode,
+	})
 	if err != nil {
-		ctx.JSON(500, errorResponse(err))
+		ctx.JSON(checkoutErrorStatus(err), errorResponse(err))
 		return
 	}
 
-	fmt.Println(req.Items)
+	server.sendOrderConfirmation(ctx, result)
 
-	// create order items
-	for _, item := range req.Items {
-		itemArg := db.CreateOrderItemParams{
-			OrderID:          order.ID,
-			ProductVariantID: item.ProductVariantID,
-			Quantity:         item.Quantity,
-			Price:            item.Price,
-		}
+	ctx.JSON(200, server.payAtCheckout(ctx, result, authPayload.UserID, req.PaymentMethod))
+}
 
-		orderItem, err := server.store.CreateOrderItem(ctx, itemArg)
-		fmt.Println(orderItem)
+// payAtCheckout charges a freshly placed order if a payment method came with
+// it. A failed payment does not fail the checkout, it is reported in the
+// response and the order stays pending.
+func (server *Server) payAtCheckout(ctx *gin.Context, result db.CheckoutTxResult, userID int32, paymentMethod string) orderResponse {
+	order := result.Order
 
-		if err != nil {
-			ctx.JSON(500, errorResponse(err))
-			return
-		}
+	var payment *db.Payment
+	var paymentErr error
+	if paymentMethod != "" {
+		payment, order, _, paymentErr = server.chargeOrder(ctx, order, userID, paymentMethod)
 	}
 
-	ctx.JSON(200, orderNotation(order))
+	rsp := orderNotation(order)
+	rsp.Items = OrderItemsNotation(result.OrderItems)
+	if payment != nil {
+		paymentRsp := paymentNotation(*payment)
+		rsp.Payment = &paymentRsp
+	}
+	if paymentErr != nil {
+		rsp.PaymentError = paymentErr.Error()
+	}
+
+	return rsp
+}
+
+// checkoutErrorStatus maps errors returned by the checkout transactions to an HTTP status
+func checkoutErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return 404
+	case errors.Is(err, util.ErrEmptyOrder), errors.Is(err, util.ErrInvalidQuantity), errors.Is(err, util.ErrInvalidAmount),
+		errors.Is(err, util.ErrInvalidCoupon):
+		return 400
+	case errors.Is(err, util.ErrInsufficientStock), errors.Is(err, util.ErrVariantUnavailable), errors.Is(err, util.ErrCouponNotApplicable):
+		return 409
+	}
+	return 500
 }
 
 // GetOrder godoc
 // @Summary Get an order by ID
 // @Tags orders
-// @Description get an order by ID
+// @Description get an order by ID, expand=items,variants,products adds the items with their current variant and product in one response
 // @Accept  json
 // @Produce  json
 // @Param id path int true "Order ID"
+// @Param expand query string false "Comma separated: items, variants, products"
 // @Success 200 {object} orderResponse
 
 type getOrderRequest struct {
 	ID int32 `uri:"id" binding:"required,min=1"`
 }
 
+type getOrderQuery struct {
+	Expand string `form:"expand"`
+}
+
 func (server *Server) getOrder(ctx *gin.Context) {
 	var req getOrderRequest
 	if err := ctx.ShouldBindUri(&req); err != nil {
@@ -125,19 +194,50 @@ func (server *Server) getOrder(ctx *gin.Context) {
 		return
 	}
 
+	var query getOrderQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		ctx.JSON(400, errorResponse(err))
+		return
+	}
+
+	expand, err := parseOrderExpand(query.Expand)
+	if err != nil {
+		ctx.JSON(400, errorResponse(err))
+		return
+	}
+
 	order, err := server.store.GetOrderById(ctx, req.ID)
 	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(404, errorResponse(err))
+			return
+		}
 		ctx.JSON(500, errorResponse(err))
 		return
 	}
 
-	ctx.JSON(200, orderNotation(order))
+	if !authorizeOwner(ctx, order.UserID, orderManagers) {
+		return
+	}
+
+	if !expand.items {
+		ctx.JSON(200, orderNotation(order))
+		return
+	}
+
+	items, err := server.store.ListOrderItemDetailsByOrderId(ctx, order.ID)
+	if err != nil {
+		ctx.JSON(500, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(200, orderDetailNotation(order, items, expand))
 }
 
 // ListOrders godoc
-// @Summary List all orders
+// @Summary List orders
 // @Tags orders
-// @Description list all orders
+// @Description list all orders for order managers, or the caller's own orders
 // @Accept  json
 // @Produce  json
 // @Param user_id query int false "User ID"
@@ -156,12 +256,22 @@ func (server *Server) ListOrders(ctx *gin.Context) {
 		return
 	}
 
-	arg := db.ListOrdersParams{
-		Limit:  req.PageSize,
-		Offset: (req.PageID - 1) * req.PageSize,
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	var orders []db.Order
+	var err error
+	if hasAccess(authPayload, orderManagers, false) {
+		orders, err = server.store.ListOrders(ctx, db.ListOrdersParams{
+			Limit:  req.PageSize,
+			Offset: (req.PageID - 1) * req.PageSize,
+		})
+	} else {
+		orders, err = server.store.GetOrdersByUserId(ctx, db.GetOrdersByUserIdParams{
+			UserID: authPayload.UserID,
+			Limit:  req.PageSize,
+			Offset: (req.PageID - 1) * req.PageSize,
+		})
 	}
-
-	orders, err := server.store.ListOrders(ctx, arg)
 	if err != nil {
 		ctx.JSON(500, errorResponse(err))
 		return
@@ -171,20 +281,18 @@ func (server *Server) ListOrders(ctx *gin.Context) {
 }
 
 // UpdateOrder godoc
-// @Summary Update an order
+// @Summary Update the status of an order
 // @Tags orders
-// @Description update an order
+// @Description move an order to a new status of its lifecycle. Cancelling restocks the order, voids open authorizations and refunds what was captured.
 // @Accept  json
 // @Produce  json
 // @Param id path int true "Order ID"
-// @Param input body orderRequest true "Order Request"
+// @Param input body updateOrderRequest true "Order Status Request"
 // @Success 200 {object} orderResponse
 
 type updateOrderRequest struct {
-	ID          int32  `json:"id"`
-	UserID      int32  `json:"user_id"`
-	TotalAmount string `json:"total_amount"`
-	Status      string `json:"status"`
+	Status string `json:"status" binding:"required"`
+	Note   string `json:"note"`
 }
 
 func (server *Server) updateOrder(ctx *gin.Context) {
@@ -194,39 +302,217 @@ func (server *Server) updateOrder(ctx *gin.Context) {
 		return
 	}
 
-	order, err := server.store.GetOrderById(ctx, int32(orderId))
-	if err != nil {
+	var req updateOrderRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
 		ctx.JSON(400, errorResponse(err))
 		return
 	}
 
-	if order.ID != int32(orderId) {
+	if req.Status == util.OrderStatusPaid {
+		ctx.JSON(409, errorResponse(util.ErrPaymentRequired))
+		return
+	}
+	if req.Status == util.OrderStatusRefunded {
+		ctx.JSON(409, errorResponse(util.ErrRefundRequired))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	var order db.Order
+	if req.Status == util.OrderStatusCancelled {
+		// cancellations must restock and give the payment back, so they go
+		// through the cancel transaction like cancelOrder
+		order, err = server.store.CancelOrderTx(ctx, db.CancelOrderTxParams{
+			OrderID:         int32(orderId),
+			CancelledBy:     authPayload.UserID,
+			Reason:          req.Note,
+			AllowedStatuses: managerCancellableStatuses,
+		})
+	} else {
+		order, err = server.store.UpdateOrderStatusTx(ctx, db.UpdateOrderStatusTxParams{
+			OrderID:   int32(orderId),
+			Status:    req.Status,
+			ChangedBy: authPayload.UserID,
+			Note:      req.Note,
+		})
+	}
+	if err != nil {
+		ctx.JSON(orderStatusErrorStatus(err), errorResponse(err))
+		return
+	}
+
+	if req.Status == util.OrderStatusCancelled && !server.giveBackCancelledOrder(ctx, order, authPayload.UserID) {
+		return
+	}
+
+	if order.Status == util.OrderStatusShipped {
+		server.sendShippingNotification(ctx, order)
+	}
+
+	ctx.JSON(200, orderNotation(order))
+}
+
+// orderStatusErrorStatus maps errors returned by order status changes to an HTTP status
+func orderStatusErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return 404
+	case errors.Is(err, util.ErrInvalidOrderStatus):
+		return 400
+	case errors.Is(err, util.ErrInvalidTransition):
+		return 409
+	}
+	return 500
+}
+
+// statuses from which an order can be cancelled
+var (
+	ownerCancellableStatuses   = []string{util.OrderStatusPending}
+	managerCancellableStatuses = []string{util.OrderStatusPending, util.OrderStatusPaid, util.OrderStatusFulfilled}
+)
+
+// CancelOrder godoc
+// @Summary Cancel an order
+// @Tags orders
+// @Description cancel an order and return its items to stock. Owners can cancel pending orders,
+// @Description order managers can cancel any order that has not shipped yet. Open payment
+// @Description authorizations are voided and what is left of a captured payment is refunded.
+// @Accept  json
+// @Produce  json
+// @Param id path int true "Order ID"
+// @Param input body cancelOrderRequest true "Cancel Order Request"
+// @Success 200 {object} orderResponse
+
+type cancelOrderRequest struct {
+	Reason string `json:"reason" binding:"required,max=500"`
+}
+
+func (server *Server) cancelOrder(ctx *gin.Context) {
+	var uri getOrderRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
 		ctx.JSON(400, errorResponse(err))
 		return
 	}
 
-	var req updateOrderRequest
+	var req cancelOrderRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil {
 		ctx.JSON(400, errorResponse(err))
 		return
 	}
 
-	arg := db.UpdateOrderParams{
-		ID:          int32(orderId),
-		TotalAmount: req.TotalAmount,
-		Status:      req.Status,
-		UserID:      req.UserID,
+	order, err := server.store.GetOrderById(ctx, uri.ID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(404, errorResponse(err))
+			return
+		}
+		ctx.JSON(500, errorResponse(err))
+		return
+	}
+
+	if !authorizeOwner(ctx, order.UserID, orderManagers) {
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	allowedStatuses := ownerCancellableStatuses
+	if hasAccess(authPayload, orderManagers, true) {
+		allowedStatuses = managerCancellableStatuses
 	}
 
-	order, err = server.store.UpdateOrder(ctx, arg)
+	order, err = server.store.CancelOrderTx(ctx, db.CancelOrderTxParams{
+		OrderID:         order.ID,
+		CancelledBy:     authPayload.UserID,
+		Reason:          req.Reason,
+		AllowedStatuses: allowedStatuses,
+	})
 	if err != nil {
-		ctx.JSON(500, errorResponse(err))
+		ctx.JSON(orderStatusErrorStatus(err), errorResponse(err))
+		return
+	}
+
+	if !server.giveBackCancelledOrder(ctx, order, authPayload.UserID) {
 		return
 	}
 
 	ctx.JSON(200, orderNotation(order))
 }
 
+// giveBackCancelledOrder releases the payments of an order that was just
+// cancelled. It returns false after writing the response when that failed.
+func (server *Server) giveBackCancelledOrder(ctx *gin.Context, order db.Order, userID int32) bool {
+	if status, err := server.releaseOrderPayments(ctx, order, userID); err != nil {
+		err = fmt.Errorf("order cancelled but its payment was not given back, refund it to retry: %w", err)
+		ctx.JSON(status, errorResponse(err))
+		return false
+	}
+	return true
+}
+
+// GetOrderTimeline godoc
+// @Summary Get the status history of an order
+// @Tags orders
+// @Description list every status change of an order, oldest first
+// @Produce  json
+// @Param id path int true "Order ID"
+// @Success 200 {array} orderTimelineEntry
+
+type orderTimelineEntry struct {
+	FromStatus string    `json:"from_status,omitempty"`
+	ToStatus   string    `json:"to_status"`
+	ChangedBy  int32     `json:"changed_by,omitempty"`
+	Note       string    `json:"note,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+func orderTimelineNotation(history []db.OrderStatusHistory) []orderTimelineEntry {
+	result := make([]orderTimelineEntry, len(history))
+
+	for i, entry := range history {
+		result[i] = orderTimelineEntry{
+			FromStatus: entry.FromStatus.String,
+			ToStatus:   entry.ToStatus,
+			ChangedBy:  entry.ChangedBy.Int32,
+			Note:       entry.Note,
+			CreatedAt:  entry.CreatedAt,
+		}
+	}
+
+	return result
+}
+
+func (server *Server) getOrderTimeline(ctx *gin.Context) {
+	var req getOrderRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(400, errorResponse(err))
+		return
+	}
+
+	order, err := server.store.GetOrderById(ctx, req.ID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(404, errorResponse(err))
+			return
+		}
+		ctx.JSON(500, errorResponse(err))
+		return
+	}
+
+	if !authorizeOwner(ctx, order.UserID, orderManagers) {
+		return
+	}
+
+	history, err := server.store.ListOrderStatusHistory(ctx, order.ID)
+	if err != nil {
+		ctx.JSON(500, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(200, orderTimelineNotation(history))
+}
+
 // DeleteOrder godoc
 // @Summary Delete an order
 // @Tags orders
@@ -0,0 +1,190 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	db "github.com/cihanalici/api/db/sqlc"
+	"github.com/cihanalici/api/util"
+	"github.com/gin-gonic/gin"
+)
+
+// checkoutAddressRequest picks the addresses copied onto a new order, either
+// from the address book by ID or inline. Without a shipping address the
+// default shipping address is used, without a billing address the default
+// billing address and then the shipping address.
+type checkoutAddressRequest struct {
+	ShippingAddressID int32          `json:"shipping_address_id" binding:"omitempty,min=1,excluded_with=ShippingAddress"`
+	ShippingAddress   *postalAddress `json:"shipping_address"`
+	BillingAddressID  int32          `json:"billing_address_id" binding:"omitempty,min=1,excluded_with=BillingAddress"`
+	BillingAddress    *postalAddress `json:"billing_address"`
+}
+
+// orderAddresses resolves the addresses of a checkout into the snapshots
+// stored on the order. It returns false after writing the response.
+func (server *Server) orderAddresses(ctx *gin.Context, userID int32, req checkoutAddressRequest) (shipping, billing json.RawMessage, ok bool) {
+	var book []db.UserAddress
+	if req.ShippingAddress == nil || req.BillingAddress == nil {
+		var err error
+		book, err = server.store.ListUserAddresses(ctx, userID)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return nil, nil, false
+		}
+	}
+
+	shippingAddress, err := pickAddress(book, req.ShippingAddressID, req.ShippingAddress, func(address db.UserAddress) bool {
+		return address.IsDefaultShipping
+	})
+	if err != nil {
+		ctx.JSON(orderAddressErrorStatus(err), errorResponse(err))
+		return nil, nil, false
+	}
+	if shippingAddress == nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(util.ErrMissingAddress))
+		return nil, nil, false
+	}
+
+	billingAddress, err := pickAddress(book, req.BillingAddressID, req.BillingAddress, func(address db.UserAddress) bool {
+		return address.IsDefaultBilling
+	})
+	if err != nil {
+		ctx.JSON(orderAddressErrorStatus(err), errorResponse(err))
+		return nil, nil, false
+	}
+	if billingAddress == nil {
+		billingAddress = shippingAddress
+	}
+
+	shipping, err = json.Marshal(shippingAddress)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return nil, nil, false
+	}
+
+	billing, err = json.Marshal(billingAddress)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return nil, nil, false
+	}
+
+	return shipping, billing, true
+}
+
+// pickAddress returns the inline address, the address book entry with the
+// given ID or the default entry, in that order. It returns nil if there is
+// nothing to pick.
+func pickAddress(book []db.UserAddress, id int32, inline *postalAddress, isDefault func(db.UserAddress) bool) (*postalAddress, error) {
+	if inline != nil {
+		if err := inline.normalize(); err != nil {
+			return nil, err
+		}
+		return inline, nil
+	}
+
+	for _, address := range book {
+		if (id != 0 && address.ID == id) || (id == 0 && isDefault(address)) {
+			picked := newPostalAddress(address)
+			return &picked, nil
+		}
+	}
+
+	if id != 0 {
+		return nil, sql.ErrNoRows
+	}
+	return nil, nil
+}
+
+// orderAddressErrorStatus maps an address that cannot be used on an order to
+// an HTTP status, anything but a missing address book entry is invalid input
+func orderAddressErrorStatus(err error) int {
+	if errors.Is(err, sql.ErrNoRows) {
+		return http.StatusNotFound
+	}
+	return http.StatusBadRequest
+}
+
+// orderAddressNotation decodes an address snapshot. Orders placed before
+// snapshots existed have an empty one and get nil.
+func orderAddressNotation(snapshot json.RawMessage) *postalAddress {
+	var address postalAddress
+	if err := json.Unmarshal(snapshot, &address); err != nil || address == (postalAddress{}) {
+		return nil
+	}
+	return &address
+}
+
+// UpdateOrderAddresses godoc
+// @Summary Correct the addresses of an order
+// @Tags orders
+// @Description replace the shipping and billing snapshots of an order that is not fulfilled yet, the billing address defaults to the shipping address
+// @Accept  json
+// @Produce  json
+// @Param id path int true "Order ID"
+// @Param input body updateOrderAddressesRequest true "Order Addresses Request"
+// @Success 200 {object} orderResponse
+// @Router /orders/{id}/addresses [put]
+
+type updateOrderAddressesRequest struct {
+	ShippingAddress postalAddress  `json:"shipping_address" binding:"required"`
+	BillingAddress  *postalAddress `json:"billing_address"`
+}
+
+func (server *Server) updateOrderAddresses(ctx *gin.Context) {
+	orderId, err := strconv.ParseInt(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var req updateOrderAddressesRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if req.BillingAddress == nil {
+		req.BillingAddress = &req.ShippingAddress
+	}
+
+	for _, address := range []*postalAddress{&req.ShippingAddress, req.BillingAddress} {
+		if err := address.normalize(); err != nil {
+			ctx.JSON(http.StatusBadRequest, errorResponse(err))
+			return
+		}
+	}
+
+	shipping, err := json.Marshal(req.ShippingAddress)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	billing, err := json.Marshal(req.BillingAddress)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	order, err := server.store.UpdateOrderAddressesTx(ctx, db.UpdateOrderAddressesParams{
+		ID:              int32(orderId),
+		ShippingAddress: shipping,
+		BillingAddress:  billing,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+		case errors.Is(err, util.ErrOrderAddressLocked):
+			ctx.JSON(http.StatusConflict, errorResponse(err))
+		default:
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusOK, orderNotation(order))
+}
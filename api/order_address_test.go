@@ -0,0 +1,64 @@
+package api
+
+import (
+	"database/sql"
+	"testing"
+
+	db "github.com/cihanalici/api/db/sqlc"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPickAddress(t *testing.T) {
+	book := []db.UserAddress{
+		{ID: 1, FullName: "Home", Line1: "1 Main St", City: "Springfield", Country: "US", IsDefaultShipping: true},
+		{ID: 2, FullName: "Office", Line1: "2 Side St", City: "Springfield", Country: "US"},
+	}
+	isDefaultShipping := func(address db.UserAddress) bool { return address.IsDefaultShipping }
+	isDefaultBilling := func(address db.UserAddress) bool { return address.IsDefaultBilling }
+
+	testCases := []struct {
+		name      string
+		id        int32
+		inline    *postalAddress
+		isDefault func(db.UserAddress) bool
+		expected  string
+		err       error
+		invalid   bool
+	}{
+		{name: "Default", isDefault: isDefaultShipping, expected: "Home"},
+		{name: "ByID", id: 2, isDefault: isDefaultShipping, expected: "Office"},
+		{name: "UnknownID", id: 3, isDefault: isDefaultShipping, err: sql.ErrNoRows},
+		{name: "NoDefault", isDefault: isDefaultBilling},
+		{
+			name:      "Inline",
+			inline:    &postalAddress{FullName: "Gift", Line1: "3 High St", City: "London", PostalCode: " SW1A 1AA ", Country: "GB"},
+			isDefault: isDefaultShipping,
+			expected:  "Gift",
+		},
+		{
+			name:      "InvalidInline",
+			inline:    &postalAddress{FullName: "Gift", Line1: "3 High St", City: "London", Country: "GB"},
+			isDefault: isDefaultShipping,
+			invalid:   true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			address, err := pickAddress(book, tc.id, tc.inline, tc.isDefault)
+			switch {
+			case tc.err != nil:
+				require.ErrorIs(t, err, tc.err)
+			case tc.invalid:
+				require.Error(t, err)
+			case tc.expected == "":
+				require.NoError(t, err)
+				require.Nil(t, address)
+			default:
+				require.NoError(t, err)
+				require.NotNil(t, address)
+				require.Equal(t, tc.expected, address.FullName)
+			}
+		})
+	}
+}
@@ -0,0 +1,115 @@
+package api
+
+import (
+	"fmt"
+	"strings"
+
+	db "github.com/cihanalici/api/db/sqlc"
+	"github.com/cihanalici/api/util"
+)
+
+// orderExpand lists what GET /orders/:id hydrates on top of the order.
+// Variants and products hang off the items, so asking for them implies items.
+type orderExpand struct {
+	items    bool
+	variants bool
+	products bool
+}
+
+// parseOrderExpand reads a comma separated expand parameter such as
+// "items,variants,products"
+func parseOrderExpand(raw string) (orderExpand, error) {
+	var expand orderExpand
+
+	for _, value := range strings.Split(raw, ",") {
+		switch strings.TrimSpace(value) {
+		case "":
+		case "items":
+			expand.items = true
+		case "variants":
+			expand.items, expand.variants = true, true
+		case "products":
+			expand.items, expand.products = true, true
+		default:
+			return expand, fmt.Errorf("%w: %q", util.ErrInvalidExpand, strings.TrimSpace(value))
+		}
+	}
+
+	return expand, nil
+}
+
+// orderTotalsResponse breaks the amount of an order down
+type orderTotalsResponse struct {
+	ItemCount      int32  `json:"item_count"`
+	SubtotalAmount string `json:"subtotal_amount"`
+	DiscountAmount string `json:"discount_amount"`
+	ShippingAmount string `json:"shipping_amount"`
+	TotalAmount    string `json:"total_amount"`
+}
+
+// orderDetailNotation adds the expanded items, with their current variant
+// and product if asked for, and the totals to an order
+func orderDetailNotation(order db.Order, rows []db.ListOrderItemDetailsByOrderIdRow, expand orderExpand) orderResponse {
+	rsp := orderNotation(order)
+	totals := orderTotalsResponse{
+		SubtotalAmount: order.SubtotalAmount,
+		DiscountAmount: order.DiscountAmount,
+		ShippingAmount: order.ShippingAmount,
+		TotalAmount:    order.TotalAmount,
+	}
+
+	rsp.Items = make([]OrderItemResponse, len(rows))
+	for i, row := range rows {
+		item := OrderItemNotation(db.OrderItem{
+			ID:               row.ID,
+			OrderID:          row.OrderID,
+			ProductVariantID: row.ProductVariantID,
+			Quantity:         row.Quantity,
+			Price:            row.Price,
+			CreatedAt:        row.CreatedAt,
+			UpdatedAt:        row.UpdatedAt,
+			ProductName:      row.ProductName,
+			Color:            row.Color,
+			Size:             row.Size,
+			Sku:              row.Sku,
+			DiscountAmount:   row.DiscountAmount,
+			Discounts:        row.Discounts,
+		})
+
+		if expand.variants {
+			variant := productVariantNotation(db.ProductVariant{
+				ID:         row.ProductVariantID,
+				ProductID:  row.ProductID,
+				Color:      row.VariantColor,
+				Size:       row.VariantSize,
+				Stock:      row.VariantStock,
+				Price:      row.VariantPrice,
+				CreatedAt:  row.VariantCreatedAt,
+				UpdatedAt:  row.VariantUpdatedAt,
+				Sku:        row.VariantSku,
+				ArchivedAt: row.VariantArchivedAt,
+			})
+			item.Variant = &variant
+		}
+
+		if expand.products {
+			product := productNotation(db.Product{
+				ID:          row.ProductID,
+				Name:        row.CurrentProductName,
+				Description: row.ProductDescription,
+				Price:       row.ProductPrice,
+				Stock:       row.ProductStock,
+				CategoryID:  row.ProductCategoryID,
+				CreatedAt:   row.ProductCreatedAt,
+				UpdatedAt:   row.ProductUpdatedAt,
+			})
+			item.Product = &product
+		}
+
+		rsp.Items[i] = item
+		totals.ItemCount += row.Quantity
+	}
+
+	rsp.Totals = &totals
+	return rsp
+}
@@ -0,0 +1,35 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/cihanalici/api/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseOrderExpand(t *testing.T) {
+	testCases := []struct {
+		name     string
+		raw      string
+		expected orderExpand
+		err      error
+	}{
+		{name: "Empty", raw: ""},
+		{name: "Items", raw: "items", expected: orderExpand{items: true}},
+		{name: "VariantsImplyItems", raw: "variants", expected: orderExpand{items: true, variants: true}},
+		{name: "All", raw: "items, variants,products,", expected: orderExpand{items: true, variants: true, products: true}},
+		{name: "Unknown", raw: "items,payments", err: util.ErrInvalidExpand},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			expand, err := parseOrderExpand(tc.raw)
+			if tc.err != nil {
+				require.ErrorIs(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, expand)
+		})
+	}
+}
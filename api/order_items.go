@@ -1,11 +1,15 @@
 package api
 
 import (
+	"database/sql"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"strconv"
 	"time"
 
 	db "github.com/cihanalici/api/db/sqlc"
+	"github.com/cihanalici/api/util"
 	"github.com/gin-gonic/gin"
 )
 
@@ -16,29 +20,64 @@ type OrderItemRequest struct {
 	Price            string `json:"price"`
 }
 
+// OrderItemResponse describes the item as it was sold: Price is the unit
+// price and the product fields are copied at checkout
 type OrderItemResponse struct {
 	ID               int32     `json:"id"`
 	OrderID          int32     `json:"order_id"`
 	ProductVariantID int32     `json:"product_variant_id"`
+	ProductName      string    `json:"product_name"`
+	Color            string    `json:"color"`
+	Size             string    `json:"size"`
+	Sku              string    `json:"sku"`
 	Quantity         int32     `json:"quantity"`
 	Price            string    `json:"price"`
+	LineTotal        string    `json:"line_total"`
+	DiscountAmount   string    `json:"discount_amount"`
 	CreatedAt        time.Time `json:"created_at"`
 	UpdatedAt        time.Time `json:"updated_at"`
+	// Discounts explains DiscountAmount, one entry per promotion or coupon
+	Discounts []db.OrderItemDiscount `json:"discounts"`
+	// Variant and Product are the current catalog entries, only set when an
+	// order is fetched with expand
+	Variant *productVariantResponse `json:"variant,omitempty"`
+	Product *productResponse        `json:"product,omitempty"`
 }
 
 func OrderItemNotation(orderItem db.OrderItem) OrderItemResponse {
+	lineTotal := orderItem.Price
+	if unitPrice, err := util.ParseMoney(orderItem.Price); err == nil {
+		lineTotal = util.FormatMoney(unitPrice * int64(orderItem.Quantity))
+	}
 
 	return OrderItemResponse{
 		ID:               orderItem.ID,
 		OrderID:          orderItem.OrderID,
 		ProductVariantID: orderItem.ProductVariantID,
+		ProductName:      orderItem.ProductName,
+		Color:            orderItem.Color,
+		Size:             orderItem.Size,
+		Sku:              orderItem.Sku,
 		Quantity:         orderItem.Quantity,
 		Price:            orderItem.Price,
+		LineTotal:        lineTotal,
+		DiscountAmount:   orderItem.DiscountAmount,
+		Discounts:        orderItemDiscountsNotation(orderItem.Discounts),
 		CreatedAt:        orderItem.CreatedAt,
 		UpdatedAt:        orderItem.UpdatedAt,
 	}
 }
 
+// orderItemDiscountsNotation decodes the explanation of a line discount.
+// Items ordered before explanations were stored get an empty list.
+func orderItemDiscountsNotation(snapshot json.RawMessage) []db.OrderItemDiscount {
+	discounts := []db.OrderItemDiscount{}
+	if err := json.Unmarshal(snapshot, &discounts); err != nil || discounts == nil {
+		return []db.OrderItemDiscount{}
+	}
+	return discounts
+}
+
 func OrderItemsNotation(orderItems []db.OrderItem) []OrderItemResponse {
 	result := make([]OrderItemResponse, len(orderItems))
 
@@ -111,10 +150,24 @@ func (server *Server) getOrderItem(ctx *gin.Context) {
 	orderItem, err := server.store.GetOrderItemById(ctx, req.ID)
 
 	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(404, errorResponse(err))
+			return
+		}
 		ctx.JSON(400, errorResponse(err))
 		return
 	}
 
+	order, err := server.store.GetOrderById(ctx, orderItem.OrderID)
+	if err != nil {
+		ctx.JSON(500, errorResponse(err))
+		return
+	}
+
+	if !authorizeOwner(ctx, order.UserID, orderManagers) {
+		return
+	}
+
 	ctx.JSON(200, OrderItemNotation(orderItem))
 }
 
@@ -147,6 +200,20 @@ func (server *Server) getOrderItemsByOrderId(ctx *gin.Context) {
 		return
 	}
 
+	order, err := server.store.GetOrderById(ctx, int32(orderId))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(404, errorResponse(err))
+			return
+		}
+		ctx.JSON(500, errorResponse(err))
+		return
+	}
+
+	if !authorizeOwner(ctx, order.UserID, orderManagers) {
+		return
+	}
+
 	arg := db.GetOrderItemsByOrderIdParams{
 		OrderID: int32(orderId),
 		Limit:   req.Limit,
@@ -0,0 +1,52 @@
+package api
+
+import (
+	"database/sql"
+	"net/http"
+	"slices"
+
+	"github.com/cihanalici/api/token"
+	"github.com/gin-gonic/gin"
+)
+
+// accessGroup is one row of the permission matrix: the roles that manage a
+// group of resources and the API key scope resource that grants the same access
+type accessGroup struct {
+	roles []string
+	scope string
+}
+
+// hasAccess reports whether the authenticated caller holds one of the group's
+// roles or, for API keys, its scope. The write scope also grants reading.
+func hasAccess(payload *token.Payload, group accessGroup, write bool) bool {
+	if payload.Type == token.TokenTypeApiKey {
+		if slices.Contains(payload.Scopes, group.scope+":"+scopeWrite) {
+			return true
+		}
+		return !write && slices.Contains(payload.Scopes, group.scope+":"+scopeRead)
+	}
+
+	return slices.Contains(group.roles, payload.Role)
+}
+
+// isWriteRequest tells whether the request may change data, which decides the
+// scope an API key needs
+func isWriteRequest(ctx *gin.Context) bool {
+	return ctx.Request.Method != http.MethodGet && ctx.Request.Method != http.MethodHead
+}
+
+// authorizeOwner checks that the caller owns a resource or has access through
+// the override group. Foreign resources are reported as not found so that their
+// existence is not revealed. It returns false after writing the response.
+func authorizeOwner(ctx *gin.Context, ownerID int32, override accessGroup) bool {
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	// API keys act for their creator but never own anything through them
+	isOwner := authPayload.Type != token.TokenTypeApiKey && authPayload.UserID == ownerID
+	if isOwner || hasAccess(authPayload, override, isWriteRequest(ctx)) {
+		return true
+	}
+
+	ctx.JSON(http.StatusNotFound, errorResponse(sql.ErrNoRows))
+	return false
+}
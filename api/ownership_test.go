@@ -0,0 +1,95 @@
+package api
+
+import (
+	"cmp"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/cihanalici/api/token"
+	"github.com/cihanalici/api/util"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuthorizeOwner(t *testing.T) {
+	testCases := []struct {
+		name          string
+		payload       *token.Payload
+		method        string
+		ownerID       int32
+		expectAllowed bool
+	}{
+		{
+			name:          "Owner",
+			payload:       &token.Payload{UserID: 1, Role: util.UserRole},
+			ownerID:       1,
+			expectAllowed: true,
+		},
+		{
+			name:          "ForeignResource",
+			payload:       &token.Payload{UserID: 2, Role: util.UserRole},
+			ownerID:       1,
+			expectAllowed: false,
+		},
+		{
+			name:          "OverrideRole",
+			payload:       &token.Payload{UserID: 2, Role: util.AdminRole},
+			ownerID:       1,
+			expectAllowed: true,
+		},
+		{
+			name:          "RoleWithoutOverride",
+			payload:       &token.Payload{UserID: 2, Role: util.StaffRole},
+			ownerID:       1,
+			expectAllowed: false,
+		},
+		{
+			name:          "ApiKeyReadScope",
+			payload:       &token.Payload{UserID: 2, Type: token.TokenTypeApiKey, Scopes: []string{"users:read"}},
+			ownerID:       1,
+			expectAllowed: true,
+		},
+		{
+			name:          "ApiKeyReadScopeCannotWrite",
+			payload:       &token.Payload{UserID: 2, Type: token.TokenTypeApiKey, Scopes: []string{"users:read"}},
+			method:        http.MethodPut,
+			ownerID:       1,
+			expectAllowed: false,
+		},
+		{
+			name:          "ApiKeyWriteScope",
+			payload:       &token.Payload{UserID: 2, Type: token.TokenTypeApiKey, Scopes: []string{"users:write"}},
+			method:        http.MethodDelete,
+			ownerID:       1,
+			expectAllowed: true,
+		},
+		{
+			name:          "ApiKeyOtherScope",
+			payload:       &token.Payload{UserID: 2, Type: token.TokenTypeApiKey, Scopes: []string{"orders:write"}},
+			ownerID:       1,
+			expectAllowed: false,
+		},
+		{
+			name:          "ApiKeyDoesNotOwnCreatorResources",
+			payload:       &token.Payload{UserID: 1, Type: token.TokenTypeApiKey, Scopes: []string{"orders:read"}},
+			ownerID:       1,
+			expectAllowed: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			ctx, _ := gin.CreateTestContext(recorder)
+			ctx.Request = httptest.NewRequest(cmp.Or(tc.method, http.MethodGet), "/", nil)
+			ctx.Set(authorizationPayloadKey, tc.payload)
+
+			allowed := authorizeOwner(ctx, tc.ownerID, userManagers)
+			require.Equal(t, tc.expectAllowed, allowed)
+			if !tc.expectAllowed {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			}
+		})
+	}
+}
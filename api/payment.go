@@ -0,0 +1,410 @@
+package api
+
+import (
+	"cmp"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	db "github.com/cihanalici/api/db/sqlc"
+	"github.com/cihanalici/api/payments"
+	"github.com/cihanalici/api/token"
+	"github.com/cihanalici/api/util"
+	"github.com/gin-gonic/gin"
+	"github.com/lib/pq"
+)
+
+// defaultPaymentCurrency is used when PAYMENT_CURRENCY is not set
+const defaultPaymentCurrency = "USD"
+
+type paymentResponse struct {
+	ID             int32     `json:"id"`
+	OrderID        int32     `json:"order_id"`
+	Provider       string    `json:"provider"`
+	Amount         string    `json:"amount"`
+	CapturedAmount string    `json:"captured_amount"`
+	RefundedAmount string    `json:"refunded_amount"`
+	Currency       string    `json:"currency"`
+	Status         string    `json:"status"`
+	FailureReason  string    `json:"failure_reason,omitempty"`
+	NextActionURL  string    `json:"next_action_url,omitempty"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+func paymentNotation(payment db.Payment) paymentResponse {
+	return paymentResponse{
+		ID:             payment.ID,
+		OrderID:        payment.OrderID,
+		Provider:       payment.Provider,
+		Amount:         payment.Amount,
+		CapturedAmount: payment.CapturedAmount,
+		RefundedAmount: payment.RefundedAmount,
+		Currency:       payment.Currency,
+		Status:         payment.Status,
+		FailureReason:  payment.FailureReason,
+		NextActionURL:  payment.NextActionUrl,
+		CreatedAt:      payment.CreatedAt,
+		UpdatedAt:      payment.UpdatedAt,
+	}
+}
+
+// chargeOrder authorizes the total of a pending order on the payment method
+// and captures it; only then is the order marked paid. It returns the payment
+// recorded for the attempt, if any, the order as it is afterwards and the
+// HTTP status describing the outcome.
+func (server *Server) chargeOrder(ctx *gin.Context, order db.Order, userID int32, paymentMethod string) (*db.Payment, db.Order, int, error) {
+	if order.Status != util.OrderStatusPending {
+		return nil, order, http.StatusConflict, util.ErrOrderNotPayable
+	}
+
+	amount, err := util.ParseMoney(order.TotalAmount)
+	if err != nil {
+		return nil, order, http.StatusInternalServerError, err
+	}
+
+	currency := cmp.Or(server.config.PaymentCurrency, defaultPaymentCurrency)
+
+	auth, err := server.gateway.Authorize(ctx, payments.AuthorizeRequest{
+		Amount:        amount,
+		Currency:      currency,
+		PaymentMethod: paymentMethod,
+		Reference:     fmt.Sprintf("order-%d", order.ID),
+	})
+	if err != nil && !errors.Is(err, payments.ErrDeclined) {
+		if errors.Is(err, payments.ErrInvalidPayment) || errors.Is(err, payments.ErrInvalidAmount) {
+			return nil, order, http.StatusBadRequest, err
+		}
+		return nil, order, http.StatusBadGateway, err
+	}
+
+	arg := db.CreatePaymentParams{
+		OrderID:           order.ID,
+		Provider:          server.gateway.Name(),
+		ProviderPaymentID: auth.ID,
+		Amount:            order.TotalAmount,
+		Currency:          currency,
+		Status:            string(auth.Status),
+		NextActionUrl:     auth.NextActionURL,
+	}
+	if err != nil {
+		arg.Status = string(payments.StatusDeclined)
+		arg.FailureReason = err.Error()
+	}
+
+	payment, createErr := server.store.CreatePayment(ctx, arg)
+	if createErr != nil {
+		if auth.Status == payments.StatusAuthorized {
+			server.releasePayment(ctx, auth.ID)
+		}
+		if pqErr, ok := createErr.(*pq.Error); ok && pqErr.Code.Name() == "unique_violation" {
+			return nil, order, http.StatusConflict, util.ErrOrderNotPayable
+		}
+		return nil, order, http.StatusInternalServerError, createErr
+	}
+
+	switch {
+	case err != nil:
+		return &payment, order, http.StatusPaymentRequired, err
+	case auth.Status == payments.StatusRequiresAction:
+		return &payment, order, http.StatusAccepted, nil
+	}
+
+	return server.capturePayment(ctx, order, payment, userID, amount)
+}
+
+// capturePayment collects an authorized payment and marks the order paid. If
+// the order cannot be paid anymore the money is given back.
+func (server *Server) capturePayment(ctx *gin.Context, order db.Order, payment db.Payment, userID int32, amount int64) (*db.Payment, db.Order, int, error) {
+	if _, err := server.gateway.Capture(ctx, payment.ProviderPaymentID, amount); err != nil {
+		server.releasePayment(ctx, payment.ProviderPaymentID)
+		payment = server.failPayment(ctx, payment, payments.StatusFailed, err)
+		return &payment, order, http.StatusBadGateway, err
+	}
+
+	result, err := server.store.CapturePaymentTx(ctx, db.CapturePaymentTxParams{
+		PaymentID:      payment.ID,
+		CapturedAmount: order.TotalAmount,
+		ChangedBy:      userID,
+	})
+	if err != nil {
+		// the money was taken but the order cannot be paid anymore
+		if _, refundErr := server.gateway.Refund(ctx, payment.ProviderPaymentID, amount); refundErr != nil {
+			log.Printf("cannot refund payment %d: %v", payment.ID, refundErr)
+			payment = server.failPayment(ctx, payment, payments.StatusCaptured, err)
+		} else {
+			payment = server.failPayment(ctx, payment, payments.StatusRefunded, err)
+		}
+		if errors.Is(err, util.ErrInvalidTransition) {
+			return &payment, order, http.StatusConflict, err
+		}
+		return &payment, order, http.StatusInternalServerError, err
+	}
+
+	return &result.Payment, result.Order, http.StatusOK, nil
+}
+
+// confirmPayment completes a payment that required an action of the customer
+// and captures it like chargeOrder does
+func (server *Server) confirmPayment(ctx *gin.Context, order db.Order, payment db.Payment, userID int32) (*db.Payment, db.Order, int, error) {
+	if order.Status != util.OrderStatusPending {
+		return nil, order, http.StatusConflict, util.ErrOrderNotPayable
+	}
+
+	if payment.Status != string(payments.StatusRequiresAction) {
+		return nil, order, http.StatusConflict, fmt.Errorf("%w: payment is %s", payments.ErrInvalidPaymentOp, payment.Status)
+	}
+
+	amount, err := util.ParseMoney(order.TotalAmount)
+	if err != nil {
+		return nil, order, http.StatusInternalServerError, err
+	}
+
+	if _, err := server.gateway.Confirm(ctx, payment.ProviderPaymentID); err != nil {
+		switch {
+		case errors.Is(err, payments.ErrDeclined):
+			payment = server.failPayment(ctx, payment, payments.StatusDeclined, err)
+			return &payment, order, http.StatusPaymentRequired, err
+		case errors.Is(err, payments.ErrInvalidPaymentOp):
+			return nil, order, http.StatusConflict, err
+		}
+		return nil, order, http.StatusBadGateway, err
+	}
+
+	authorized, err := server.store.UpdatePaymentStatus(ctx, db.UpdatePaymentStatusParams{
+		ID:     payment.ID,
+		Status: string(payments.StatusAuthorized),
+	})
+	if err != nil {
+		server.releasePayment(ctx, payment.ProviderPaymentID)
+		return nil, order, http.StatusInternalServerError, err
+	}
+
+	return server.capturePayment(ctx, order, authorized, userID, amount)
+}
+
+// releasePayment voids an authorization that will not be captured, a failure
+// only leaves a hold that expires at the provider
+func (server *Server) releasePayment(ctx *gin.Context, providerPaymentID string) {
+	if _, err := server.gateway.Void(ctx, providerPaymentID); err != nil {
+		log.Printf("cannot void payment %s: %v", providerPaymentID, err)
+	}
+}
+
+// releaseOrderPayments gives back what a cancelled order was charged: open
+// authorizations are voided and whatever is left of a capture is refunded.
+// It returns the HTTP status describing a failure.
+func (server *Server) releaseOrderPayments(ctx *gin.Context, order db.Order, userID int32) (int, error) {
+	list, err := server.store.ListPaymentsByOrderId(ctx, order.ID)
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+
+	captured := false
+	for _, payment := range list {
+		switch payments.Status(payment.Status) {
+		case payments.StatusAuthorized, payments.StatusRequiresAction:
+			server.releasePayment(ctx, payment.ProviderPaymentID)
+			server.failPayment(ctx, payment, payments.StatusVoided, errors.New("order cancelled"))
+		case payments.StatusCaptured:
+			// a payment refunded in full is refunded, not captured
+			captured = true
+		}
+	}
+	if !captured {
+		return http.StatusOK, nil
+	}
+
+	_, status, err := server.refundOrder(ctx, db.CreateRefundTxParams{
+		OrderID:   order.ID,
+		Reason:    "order cancelled",
+		CreatedBy: userID,
+	})
+	return status, err
+}
+
+// failPayment records why a payment did not go through
+func (server *Server) failPayment(ctx *gin.Context, payment db.Payment, status payments.Status, reason error) db.Payment {
+	updated, err := server.store.UpdatePaymentStatus(ctx, db.UpdatePaymentStatusParams{
+		ID:            payment.ID,
+		Status:        string(status),
+		FailureReason: reason.Error(),
+	})
+	if err != nil {
+		log.Printf("cannot update payment %d: %v", payment.ID, err)
+		return payment
+	}
+	return updated
+}
+
+// PayOrder godoc
+// @Summary Pay a pending order
+// @Tags payments
+// @Description authorize and capture the order total on a payment method, the order becomes paid once the capture succeeds. 202 means the customer has to finish at next_action_url and confirm the payment afterwards, 402 that the payment was declined.
+// @Accept  json
+// @Produce  json
+// @Param id path int true "Order ID"
+// @Param input body payOrderRequest true "Payment method"
+// @Success 200 {object} orderResponse
+// @Router /orders/{id}/pay [post]
+
+type payOrderRequest struct {
+	PaymentMethod string `json:"payment_method" binding:"required,max=255"`
+}
+
+func (server *Server) payOrder(ctx *gin.Context) {
+	var uri getOrderRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var req payOrderRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	order, err := server.store.GetOrderById(ctx, uri.ID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	// only the customer pays for their order
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if order.UserID != authPayload.UserID {
+		ctx.JSON(http.StatusNotFound, errorResponse(sql.ErrNoRows))
+		return
+	}
+
+	payment, order, status, err := server.chargeOrder(ctx, order, authPayload.UserID, req.PaymentMethod)
+	if err != nil {
+		ctx.JSON(status, errorResponse(err))
+		return
+	}
+
+	rsp := orderNotation(order)
+	if payment != nil {
+		paymentRsp := paymentNotation(*payment)
+		rsp.Payment = &paymentRsp
+	}
+
+	ctx.JSON(status, rsp)
+}
+
+// ConfirmOrderPayment godoc
+// @Summary Confirm a payment after the customer action
+// @Tags payments
+// @Description complete a payment that answered 202 once the customer finished at its next_action_url, it is captured and the order becomes paid. 402 means the provider declined it.
+// @Produce  json
+// @Param id path int true "Order ID"
+// @Param payment_id path int true "Payment ID"
+// @Success 200 {object} orderResponse
+// @Router /orders/{id}/payments/{payment_id}/confirm [post]
+
+type confirmPaymentRequest struct {
+	ID        int32 `uri:"id" binding:"required,min=1"`
+	PaymentID int32 `uri:"payment_id" binding:"required,min=1"`
+}
+
+func (server *Server) confirmOrderPayment(ctx *gin.Context) {
+	var uri confirmPaymentRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	order, err := server.store.GetOrderById(ctx, uri.ID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	// only the customer pays for their order
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if order.UserID != authPayload.UserID {
+		ctx.JSON(http.StatusNotFound, errorResponse(sql.ErrNoRows))
+		return
+	}
+
+	payment, err := server.store.GetPayment(ctx, uri.PaymentID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	if payment.OrderID != order.ID {
+		ctx.JSON(http.StatusNotFound, errorResponse(sql.ErrNoRows))
+		return
+	}
+
+	captured, order, status, err := server.confirmPayment(ctx, order, payment, authPayload.UserID)
+	if err != nil {
+		ctx.JSON(status, errorResponse(err))
+		return
+	}
+
+	rsp := orderNotation(order)
+	paymentRsp := paymentNotation(*captured)
+	rsp.Payment = &paymentRsp
+
+	ctx.JSON(status, rsp)
+}
+
+// ListOrderPayments godoc
+// @Summary List the payment attempts of an order
+// @Tags payments
+// @Produce  json
+// @Param id path int true "Order ID"
+// @Success 200 {array} paymentResponse
+// @Router /orders/{id}/payments [get]
+func (server *Server) listOrderPayments(ctx *gin.Context) {
+	var uri getOrderRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	order, err := server.store.GetOrderById(ctx, uri.ID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	if !authorizeOwner(ctx, order.UserID, orderManagers) {
+		return
+	}
+
+	list, err := server.store.ListPaymentsByOrderId(ctx, order.ID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	rsp := make([]paymentResponse, len(list))
+	for i, payment := range list {
+		rsp[i] = paymentNotation(payment)
+	}
+
+	ctx.JSON(http.StatusOK, rsp)
+}
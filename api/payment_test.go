@@ -0,0 +1,141 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	db "github.com/cihanalici/api/db/sqlc"
+	"github.com/cihanalici/api/payments"
+	"github.com/cihanalici/api/util"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+// paymentStore keeps payments in memory; calling anything else panics
+type paymentStore struct {
+	db.Store
+	payments []db.Payment
+}
+
+func (store *paymentStore) CreatePayment(ctx context.Context, arg db.CreatePaymentParams) (db.Payment, error) {
+	payment := db.Payment{
+		ID:                int32(len(store.payments) + 1),
+		OrderID:           arg.OrderID,
+		Provider:          arg.Provider,
+		ProviderPaymentID: arg.ProviderPaymentID,
+		Amount:            arg.Amount,
+		CapturedAmount:    "0.00",
+		Currency:          arg.Currency,
+		Status:            arg.Status,
+		FailureReason:     arg.FailureReason,
+		NextActionUrl:     arg.NextActionUrl,
+	}
+	store.payments = append(store.payments, payment)
+	return payment, nil
+}
+
+func (store *paymentStore) CapturePaymentTx(ctx context.Context, arg db.CapturePaymentTxParams) (db.CapturePaymentTxResult, error) {
+	payment := &store.payments[arg.PaymentID-1]
+	payment.Status = string(payments.StatusCaptured)
+	payment.CapturedAmount = arg.CapturedAmount
+
+	return db.CapturePaymentTxResult{
+		Payment: *payment,
+		Order:   db.Order{ID: payment.OrderID, Status: util.OrderStatusPaid, TotalAmount: arg.CapturedAmount},
+	}, nil
+}
+
+func TestChargeOrder(t *testing.T) {
+	testCases := []struct {
+		name          string
+		card          string
+		orderStatus   string
+		status        int
+		paymentStatus payments.Status
+		expectStatus  string
+	}{
+		{name: "Captured", card: payments.FakeCardSuccess, orderStatus: util.OrderStatusPending, status: http.StatusOK, paymentStatus: payments.StatusCaptured, expectStatus: util.OrderStatusPaid},
+		{name: "Declined", card: payments.FakeCardDeclined, orderStatus: util.OrderStatusPending, status: http.StatusPaymentRequired, paymentStatus: payments.StatusDeclined, expectStatus: util.OrderStatusPending},
+		{name: "RequiresAction", card: payments.FakeCardRequiresAction, orderStatus: util.OrderStatusPending, status: http.StatusAccepted, paymentStatus: payments.StatusRequiresAction, expectStatus: util.OrderStatusPending},
+		{name: "InvalidCard", card: "1234", orderStatus: util.OrderStatusPending, status: http.StatusBadRequest, expectStatus: util.OrderStatusPending},
+		{name: "AlreadyPaid", card: payments.FakeCardSuccess, orderStatus: util.OrderStatusPaid, status: http.StatusConflict, expectStatus: util.OrderStatusPaid},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			store := &paymentStore{}
+			server := &Server{store: store, gateway: payments.NewFakeGateway()}
+			ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+
+			order := db.Order{ID: 7, UserID: 1, Status: tc.orderStatus, TotalAmount: "25.50"}
+			payment, order, status, err := server.chargeOrder(ctx, order, 1, tc.card)
+
+			require.Equal(t, tc.status, status)
+			require.Equal(t, tc.expectStatus, order.Status)
+			require.Equal(t, status >= http.StatusBadRequest, err != nil)
+
+			if tc.paymentStatus == "" {
+				require.Nil(t, payment)
+				require.Empty(t, store.payments)
+				return
+			}
+
+			require.NotNil(t, payment)
+			require.Equal(t, string(tc.paymentStatus), payment.Status)
+			require.Equal(t, "25.50", payment.Amount)
+			require.Equal(t, defaultPaymentCurrency, payment.Currency)
+		})
+	}
+}
+
+func (store *paymentStore) UpdatePaymentStatus(ctx context.Context, arg db.UpdatePaymentStatusParams) (db.Payment, error) {
+	payment := &store.payments[arg.ID-1]
+	payment.Status = arg.Status
+	payment.FailureReason = arg.FailureReason
+	return *payment, nil
+}
+
+func TestConfirmPayment(t *testing.T) {
+	testCases := []struct {
+		name          string
+		card          string
+		confirmTwice  bool
+		status        int
+		paymentStatus payments.Status
+		expectStatus  string
+	}{
+		{name: "Captured", card: payments.FakeCardRequiresAction, status: http.StatusOK, paymentStatus: payments.StatusCaptured, expectStatus: util.OrderStatusPaid},
+		{name: "AlreadyConfirmed", card: payments.FakeCardRequiresAction, confirmTwice: true, status: http.StatusConflict, paymentStatus: payments.StatusCaptured, expectStatus: util.OrderStatusPending},
+		{name: "NoActionRequired", card: payments.FakeCardSuccess, status: http.StatusConflict, paymentStatus: payments.StatusCaptured, expectStatus: util.OrderStatusPending},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			store := &paymentStore{}
+			server := &Server{store: store, gateway: payments.NewFakeGateway()}
+			ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+
+			order := db.Order{ID: 7, UserID: 1, Status: util.OrderStatusPending, TotalAmount: "25.50"}
+			payment, _, _, err := server.chargeOrder(ctx, order, 1, tc.card)
+			require.NoError(t, err)
+
+			if tc.confirmTwice {
+				_, _, status, err := server.confirmPayment(ctx, order, *payment, 1)
+				require.NoError(t, err)
+				require.Equal(t, http.StatusOK, status)
+			}
+
+			confirmed, order, status, err := server.confirmPayment(ctx, order, *payment, 1)
+
+			require.Equal(t, tc.status, status)
+			require.Equal(t, tc.expectStatus, order.Status)
+			require.Equal(t, status >= http.StatusBadRequest, err != nil)
+			require.Equal(t, string(tc.paymentStatus), store.payments[0].Status)
+			if err == nil {
+				require.Equal(t, "25.50", confirmed.CapturedAmount)
+			}
+		})
+	}
+}
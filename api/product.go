@@ -5,6 +5,7 @@ import (
 
 	db "github.com/cihanalici/api/db/sqlc"
 	"github.com/gin-gonic/gin"
+	"github.com/lib/pq"
 )
 
 type productRequest struct {
@@ -215,6 +216,11 @@ func (server *Server) deleteProduct(ctx *gin.Context) {
 
 	err := server.store.DeleteProduct(ctx, req.ID)
 	if err != nil {
+		// variants that were ordered are kept, and so is their product
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code.Name() == "foreign_key_violation" {
+			ctx.JSON(409, errorResponse(err))
+			return
+		}
 		ctx.JSON(400, errorResponse(err))
 		return
 	}
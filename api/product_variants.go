@@ -1,46 +1,72 @@
 package api
 
 import (
+	"cmp"
+	"database/sql"
+	"errors"
+	"fmt"
 	"strconv"
+	"strings"
 	"time"
 
 	db "github.com/cihanalici/api/db/sqlc"
 	"github.com/gin-gonic/gin"
+	"github.com/lib/pq"
 )
 
+// productVariantRequest leaves Sku optional, new variants then get one
+// built from the product, color and size and updates keep the current one
 type productVariantRequest struct {
 	ProductID int32  `json:"product_id"`
 	Color     string `json:"color" binding:"required"`
 	Size      string `json:"size" binding:"required"`
 	Stock     int32  `json:"stock" binding:"required"`
 	Price     string `json:"price" binding:"required"`
+	Sku       string `json:"sku" binding:"max=64"`
 }
 
 type productVariantResponse struct {
-	ID        int32     `json:"id"`
-	ProductID int32     `json:"product_id"`
-	Color     string    `json:"color"`
-	Size      string    `json:"size"`
-	Stock     int32     `json:"stock"`
-	Price     string    `json:"price"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID         int32      `json:"id"`
+	ProductID  int32      `json:"product_id"`
+	Color      string     `json:"color"`
+	Size       string     `json:"size"`
+	Stock      int32      `json:"stock"`
+	Price      string     `json:"price"`
+	Sku        string     `json:"sku"`
+	ArchivedAt *time.Time `json:"archived_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
 }
 
 func productVariantNotation(productVariant db.ProductVariant) productVariantResponse {
 
 	return productVariantResponse{
-		ID:        productVariant.ID,
-		ProductID: productVariant.ProductID,
-		Color:     productVariant.Color,
-		Size:      productVariant.Size,
-		Stock:     productVariant.Stock,
-		Price:     productVariant.Price,
-		CreatedAt: productVariant.CreatedAt,
-		UpdatedAt: productVariant.UpdatedAt,
+		ID:         productVariant.ID,
+		ProductID:  productVariant.ProductID,
+		Color:      productVariant.Color,
+		Size:       productVariant.Size,
+		Stock:      productVariant.Stock,
+		Price:      productVariant.Price,
+		Sku:        productVariant.Sku,
+		ArchivedAt: nullTimePtr(productVariant.ArchivedAt),
+		CreatedAt:  productVariant.CreatedAt,
+		UpdatedAt:  productVariant.UpdatedAt,
 	}
 }
 
+// defaultVariantSku is unique because product, color and size are
+func defaultVariantSku(productID int32, color, size string) string {
+	return fmt.Sprintf("%d-%s-%s", productID, color, size)
+}
+
+// productVariantErrorStatus maps a duplicate sku or color and size to a conflict
+func productVariantErrorStatus(err error) int {
+	if pqErr, ok := err.(*pq.Error); ok && pqErr.Code.Name() == "unique_violation" {
+		return 409
+	}
+	return 500
+}
+
 func productVariantsNotation(productVariants []db.ProductVariant) []productVariantResponse {
 	result := make([]productVariantResponse, len(productVariants))
 
@@ -76,6 +102,7 @@ func (server *Server) createProductVariant(ctx *gin.Context) {
 		Size:      req.Size,
 		Stock:     req.Stock,
 		Price:     req.Price,
+		Sku:       cmp.Or(strings.TrimSpace(req.Sku), defaultVariantSku(req.ProductID, req.Color, req.Size)),
 	}
 
 	// if req.ProductID != nil {
@@ -84,7 +111,7 @@ func (server *Server) createProductVariant(ctx *gin.Context) {
 
 	productVariant, err := server.store.CreateProductVariant(ctx, arg)
 	if err != nil {
-		ctx.JSON(500, errorResponse(err))
+		ctx.JSON(productVariantErrorStatus(err), errorResponse(err))
 		return
 	}
 
@@ -192,12 +219,13 @@ func (server *Server) updateProductVariant(ctx *gin.Context) {
 		Stock:     req.Stock,
 		Price:     req.Price,
 		ProductID: req.ProductID,
+		Sku:       cmp.Or(strings.TrimSpace(req.Sku), variant.Sku),
 	}
 
 	variant, err = server.store.UpdateProductVariant(ctx, arg)
 
 	if err != nil {
-		ctx.JSON(500, errorResponse(err))
+		ctx.JSON(productVariantErrorStatus(err), errorResponse(err))
 		return
 	}
 
@@ -206,7 +234,7 @@ func (server *Server) updateProductVariant(ctx *gin.Context) {
 
 // DeleteProductVariant godoc
 // @Summary Delete a product variant
-// @Description Delete a product variant by ID
+// @Description Delete a product variant by ID. Variants that were ordered are archived instead, they leave the listing and can no longer be bought.
 // @ID delete-product-variant
 // @Accept  json
 // @Produce  json
@@ -220,12 +248,21 @@ func (server *Server) deleteProductVariant(ctx *gin.Context) {
 		return
 	}
 
-	err = server.store.DeleteProductVariant(ctx, int32(variantId))
+	result, err := server.store.DeleteProductVariantTx(ctx, int32(variantId))
 
 	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(404, errorResponse(err))
+			return
+		}
 		ctx.JSON(500, errorResponse(err))
 		return
 	}
 
+	if result.Archived {
+		ctx.JSON(200, gin.H{"status": "archived", "variant": productVariantNotation(result.Variant)})
+		return
+	}
+
 	ctx.JSON(200, gin.H{"status": "ok"})
 }
@@ -0,0 +1,279 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	db "github.com/cihanalici/api/db/sqlc"
+	"github.com/cihanalici/api/promotions"
+	"github.com/gin-gonic/gin"
+)
+
+type promotionResponse struct {
+	ID          int32           `json:"id"`
+	Name        string          `json:"name"`
+	Description string          `json:"description"`
+	Type        string          `json:"type"`
+	Priority    int32           `json:"priority"`
+	Exclusive   bool            `json:"exclusive"`
+	Conditions  json.RawMessage `json:"conditions"`
+	StartsAt    *time.Time      `json:"starts_at"`
+	EndsAt      *time.Time      `json:"ends_at"`
+	IsActive    bool            `json:"is_active"`
+	CreatedAt   time.Time       `json:"created_at"`
+	UpdatedAt   time.Time       `json:"updated_at"`
+}
+
+func promotionNotation(promotion db.Promotion) promotionResponse {
+	return promotionResponse{
+		ID:          promotion.ID,
+		Name:        promotion.Name,
+		Description: promotion.Description,
+		Type:        promotion.Type,
+		Priority:    promotion.Priority,
+		Exclusive:   promotion.Exclusive,
+		Conditions:  promotion.Conditions,
+		StartsAt:    nullTimePtr(promotion.StartsAt),
+		EndsAt:      nullTimePtr(promotion.EndsAt),
+		IsActive:    promotion.IsActive,
+		CreatedAt:   promotion.CreatedAt,
+		UpdatedAt:   promotion.UpdatedAt,
+	}
+}
+
+// CreatePromotion godoc
+// @Summary Create an automatic promotion
+// @Tags promotions
+// @Description create a promotion applied at checkout without a code. buy_x_get_y uses buy, get, percent, product_ids and category_ids; tiered uses tiers, product_ids and category_ids; bundle uses bundle_product_ids and bundle_price. Promotions are evaluated by descending priority, buy_x_get_y and bundle promotions never share a unit and an exclusive promotion only applies alone.
+// @Accept  json
+// @Produce  json
+// @Param input body promotionRequest true "Promotion"
+// @Success 201 {object} promotionResponse
+// @Router /promotions [post]
+
+type promotionRequest struct {
+	Name        string                `json:"name" binding:"required,max=100"`
+	Description string                `json:"description" binding:"max=500"`
+	Type        string                `json:"type" binding:"required,oneof=buy_x_get_y tiered bundle"`
+	Priority    int32                 `json:"priority"`
+	Exclusive   bool                  `json:"exclusive"`
+	Conditions  promotions.Conditions `json:"conditions"`
+	StartsAt    *time.Time            `json:"starts_at"`
+	EndsAt      *time.Time            `json:"ends_at"`
+	// IsActive defaults to true
+	IsActive *bool `json:"is_active"`
+}
+
+// params validates the rule and the validity window and converts the
+// request to the stored promotion
+func (req promotionRequest) params() (db.CreatePromotionParams, error) {
+	arg := db.CreatePromotionParams{
+		Name:        req.Name,
+		Description: req.Description,
+		Type:        req.Type,
+		Priority:    req.Priority,
+		Exclusive:   req.Exclusive,
+		IsActive:    req.IsActive == nil || *req.IsActive,
+	}
+
+	_, err := promotions.NewRule(0, req.Name, promotions.Type(req.Type), req.Priority, req.Exclusive, req.Conditions)
+	if err != nil {
+		return arg, err
+	}
+
+	arg.Conditions, err = json.Marshal(req.Conditions)
+	if err != nil {
+		return arg, err
+	}
+
+	if req.StartsAt != nil && req.EndsAt != nil && !req.EndsAt.After(*req.StartsAt) {
+		return arg, errors.New("ends_at must be after starts_at")
+	}
+	if req.StartsAt != nil {
+		arg.StartsAt = sql.NullTime{Time: *req.StartsAt, Valid: true}
+	}
+	if req.EndsAt != nil {
+		arg.EndsAt = sql.NullTime{Time: *req.EndsAt, Valid: true}
+	}
+
+	return arg, nil
+}
+
+func (server *Server) createPromotion(ctx *gin.Context) {
+	var req promotionRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	arg, err := req.params()
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	promotion, err := server.store.CreatePromotion(ctx, arg)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, promotionNotation(promotion))
+}
+
+// GetPromotion godoc
+// @Summary Get an automatic promotion
+// @Tags promotions
+// @Produce  json
+// @Param id path int true "Promotion ID"
+// @Success 200 {object} promotionResponse
+// @Router /promotions/{id} [get]
+
+type getPromotionRequest struct {
+	ID int32 `uri:"id" binding:"required,min=1"`
+}
+
+func (server *Server) getPromotion(ctx *gin.Context) {
+	var uri getPromotionRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	promotion, err := server.store.GetPromotionById(ctx, uri.ID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, promotionNotation(promotion))
+}
+
+// ListPromotions godoc
+// @Summary List automatic promotions
+// @Tags promotions
+// @Produce  json
+// @Param page_id query int true "Page ID"
+// @Param page_size query int true "Page Size"
+// @Success 200 {array} promotionResponse
+// @Router /promotions [get]
+
+type listPromotionsRequest struct {
+	PageID   int32 `form:"page_id" binding:"required,min=1"`
+	PageSize int32 `form:"page_size" binding:"required,min=5,max=10"`
+}
+
+func (server *Server) listPromotions(ctx *gin.Context) {
+	var req listPromotionsRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	list, err := server.store.ListPromotions(ctx, db.ListPromotionsParams{
+		Limit:  req.PageSize,
+		Offset: (req.PageID - 1) * req.PageSize,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	rsp := make([]promotionResponse, len(list))
+	for i, promotion := range list {
+		rsp[i] = promotionNotation(promotion)
+	}
+
+	ctx.JSON(http.StatusOK, rsp)
+}
+
+// UpdatePromotion godoc
+// @Summary Update an automatic promotion
+// @Tags promotions
+// @Description replace a promotion, orders that already got it keep their discount
+// @Accept  json
+// @Produce  json
+// @Param id path int true "Promotion ID"
+// @Param input body promotionRequest true "Promotion"
+// @Success 200 {object} promotionResponse
+// @Router /promotions/{id} [put]
+func (server *Server) updatePromotion(ctx *gin.Context) {
+	var uri getPromotionRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var req promotionRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	arg, err := req.params()
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	promotion, err := server.store.UpdatePromotion(ctx, db.UpdatePromotionParams{
+		ID:          uri.ID,
+		Name:        arg.Name,
+		Description: arg.Description,
+		Type:        arg.Type,
+		Priority:    arg.Priority,
+		Exclusive:   arg.Exclusive,
+		Conditions:  arg.Conditions,
+		StartsAt:    arg.StartsAt,
+		EndsAt:      arg.EndsAt,
+		IsActive:    arg.IsActive,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, promotionNotation(promotion))
+}
+
+// DeletePromotion godoc
+// @Summary Delete an automatic promotion
+// @Tags promotions
+// @Description delete a promotion, order items keep the explanation of the discount it gave
+// @Param id path int true "Promotion ID"
+// @Success 200
+// @Router /promotions/{id} [delete]
+func (server *Server) deletePromotion(ctx *gin.Context) {
+	var uri getPromotionRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if _, err := server.store.GetPromotionById(ctx, uri.ID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	if err := server.store.DeletePromotion(ctx, uri.ID); err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
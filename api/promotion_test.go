@@ -0,0 +1,39 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/cihanalici/api/promotions"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPromotionRequestConditions(t *testing.T) {
+	testCases := []struct {
+		name       string
+		ruleType   promotions.Type
+		conditions promotions.Conditions
+		valid      bool
+	}{
+		{name: "BuyXGetY", ruleType: promotions.TypeBuyXGetY, conditions: promotions.Conditions{CategoryIDs: []int32{5}, Buy: 2, Get: 1}, valid: true},
+		{name: "BuyXGetYWithoutGet", ruleType: promotions.TypeBuyXGetY, conditions: promotions.Conditions{Buy: 2}},
+		{name: "Tiered", ruleType: promotions.TypeTiered, conditions: promotions.Conditions{Tiers: []promotions.TierConditions{{MinAmount: "500", Percent: "10"}}}, valid: true},
+		{name: "TieredWithoutTiers", ruleType: promotions.TypeTiered},
+		{name: "TieredPercentOver100", ruleType: promotions.TypeTiered, conditions: promotions.Conditions{Tiers: []promotions.TierConditions{{MinAmount: "500", Percent: "110"}}}},
+		{name: "Bundle", ruleType: promotions.TypeBundle, conditions: promotions.Conditions{BundleProductIDs: []int32{1, 2}, BundlePrice: "15.00"}, valid: true},
+		{name: "BundleWithoutPrice", ruleType: promotions.TypeBundle, conditions: promotions.Conditions{BundleProductIDs: []int32{1, 2}}},
+		{name: "BundleOfOneProduct", ruleType: promotions.TypeBundle, conditions: promotions.Conditions{BundleProductIDs: []int32{1}, BundlePrice: "15.00"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := promotionRequest{Name: tc.name, Type: string(tc.ruleType), Conditions: tc.conditions}
+
+			_, err := req.params()
+			if tc.valid {
+				require.NoError(t, err)
+			} else {
+				require.ErrorIs(t, err, promotions.ErrInvalidRule)
+			}
+		})
+	}
+}
@@ -0,0 +1,222 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	db "github.com/cihanalici/api/db/sqlc"
+	"github.com/cihanalici/api/token"
+	"github.com/cihanalici/api/util"
+	"github.com/gin-gonic/gin"
+)
+
+type refundItemResponse struct {
+	OrderItemID int32  `json:"order_item_id"`
+	Quantity    int32  `json:"quantity"`
+	Amount      string `json:"amount"`
+}
+
+type refundResponse struct {
+	ID            int32                `json:"id"`
+	OrderID       int32                `json:"order_id"`
+	PaymentID     int32                `json:"payment_id"`
+	Amount        string               `json:"amount"`
+	Reason        string               `json:"reason,omitempty"`
+	Restock       bool                 `json:"restock"`
+	Status        string               `json:"status"`
+	FailureReason string               `json:"failure_reason,omitempty"`
+	Items         []refundItemResponse `json:"items"`
+	CreatedAt     time.Time            `json:"created_at"`
+	UpdatedAt     time.Time            `json:"updated_at"`
+}
+
+func refundNotation(refund db.Refund, items []db.RefundItem) refundResponse {
+	rsp := refundResponse{
+		ID:            refund.ID,
+		OrderID:       refund.OrderID,
+		PaymentID:     refund.PaymentID,
+		Amount:        refund.Amount,
+		Reason:        refund.Reason,
+		Restock:       refund.Restock,
+		Status:        refund.Status,
+		FailureReason: refund.FailureReason,
+		Items:         []refundItemResponse{},
+		CreatedAt:     refund.CreatedAt,
+		UpdatedAt:     refund.UpdatedAt,
+	}
+
+	for _, item := range items {
+		if item.RefundID != refund.ID {
+			continue
+		}
+		rsp.Items = append(rsp.Items, refundItemResponse{
+			OrderItemID: item.OrderItemID,
+			Quantity:    item.Quantity,
+			Amount:      item.Amount,
+		})
+	}
+
+	return rsp
+}
+
+// refundErrorStatus maps errors returned by refund transactions to an HTTP status
+func refundErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return http.StatusNotFound
+	case errors.Is(err, util.ErrInvalidAmount), errors.Is(err, util.ErrInvalidRefundItem):
+		return http.StatusBadRequest
+	case errors.Is(err, util.ErrOrderNotRefundable), errors.Is(err, util.ErrRefundExceedsCaptured), errors.Is(err, util.ErrReturnTransition):
+		return http.StatusConflict
+	}
+	return http.StatusInternalServerError
+}
+
+// CreateRefund godoc
+// @Summary Refund an order
+// @Tags payments
+// @Description refund item quantities, an arbitrary amount, or, with neither, everything left of the captured payment. The order becomes refunded once the payment is refunded in full, partial refunds show on its timeline. restock returns the refunded items to stock.
+// @Accept  json
+// @Produce  json
+// @Param id path int true "Order ID"
+// @Param input body createRefundRequest true "What to refund"
+// @Success 201 {object} refundResponse
+// @Router /orders/{id}/refunds [post]
+
+type refundItemRequest struct {
+	OrderItemID int32 `json:"order_item_id" binding:"required,min=1"`
+	Quantity    int32 `json:"quantity" binding:"required,min=1"`
+}
+
+type createRefundRequest struct {
+	Items   []refundItemRequest `json:"items" binding:"omitempty,dive"`
+	Amount  string              `json:"amount" binding:"excluded_with=Items"`
+	Reason  string              `json:"reason" binding:"max=500"`
+	Restock bool                `json:"restock"`
+}
+
+func (server *Server) createRefund(ctx *gin.Context) {
+	var uri getOrderRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var req createRefundRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if req.Restock && req.Amount != "" {
+		ctx.JSON(http.StatusBadRequest, errorResponse(fmt.Errorf("%w: restock needs items, not an amount", util.ErrInvalidRefundItem)))
+		return
+	}
+
+	arg := db.CreateRefundTxParams{
+		OrderID:   uri.ID,
+		Amount:    req.Amount,
+		Reason:    req.Reason,
+		Restock:   req.Restock,
+		CreatedBy: ctx.MustGet(authorizationPayloadKey).(*token.Payload).UserID,
+	}
+	for _, item := range req.Items {
+		arg.Items = append(arg.Items, db.RefundItemParams{
+			OrderItemID: item.OrderItemID,
+			Quantity:    item.Quantity,
+		})
+	}
+
+	refund, status, err := server.refundOrder(ctx, arg)
+	if err != nil {
+		ctx.JSON(status, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(status, refund)
+}
+
+// refundOrder reserves the refund, asks the gateway for the money and
+// records the outcome. It returns the refund and the HTTP status describing it.
+func (server *Server) refundOrder(ctx *gin.Context, arg db.CreateRefundTxParams) (refundResponse, int, error) {
+	created, err := server.store.CreateRefundTx(ctx, arg)
+	if err != nil {
+		return refundResponse{}, refundErrorStatus(err), err
+	}
+
+	amount, err := util.ParseMoney(created.Refund.Amount)
+	if err != nil {
+		return refundResponse{}, http.StatusInternalServerError, err
+	}
+
+	if _, err := server.gateway.Refund(ctx, created.Payment.ProviderPaymentID, amount); err != nil {
+		if _, failErr := server.store.FailRefundTx(ctx, created.Refund.ID, err.Error()); failErr != nil {
+			log.Printf("cannot fail refund %d: %v", created.Refund.ID, failErr)
+		}
+		return refundResponse{}, http.StatusBadGateway, err
+	}
+
+	completed, err := server.store.CompleteRefundTx(ctx, db.CompleteRefundTxParams{
+		RefundID:  created.Refund.ID,
+		ChangedBy: arg.CreatedBy,
+	})
+	if err != nil {
+		// the money is back with the customer, the refund stays pending
+		log.Printf("cannot complete refund %d: %v", created.Refund.ID, err)
+		return refundResponse{}, http.StatusInternalServerError, err
+	}
+
+	return refundNotation(completed.Refund, created.Items), http.StatusCreated, nil
+}
+
+// ListOrderRefunds godoc
+// @Summary List the refunds of an order
+// @Tags payments
+// @Produce  json
+// @Param id path int true "Order ID"
+// @Success 200 {array} refundResponse
+// @Router /orders/{id}/refunds [get]
+func (server *Server) listOrderRefunds(ctx *gin.Context) {
+	var uri getOrderRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	order, err := server.store.GetOrderById(ctx, uri.ID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	if !authorizeOwner(ctx, order.UserID, orderManagers) {
+		return
+	}
+
+	refunds, err := server.store.ListRefundsByOrderId(ctx, order.ID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	items, err := server.store.ListRefundItemsByOrderId(ctx, order.ID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	rsp := make([]refundResponse, len(refunds))
+	for i, refund := range refunds {
+		rsp[i] = refundNotation(refund, items)
+	}
+
+	ctx.JSON(http.StatusOK, rsp)
+}
@@ -0,0 +1,236 @@
+package api
+
+import (
+	"cmp"
+	"context"
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	db "github.com/cihanalici/api/db/sqlc"
+	"github.com/cihanalici/api/payments"
+	"github.com/cihanalici/api/token"
+	"github.com/cihanalici/api/util"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+// refundStore records the refund transactions; calling anything else panics
+type refundStore struct {
+	db.Store
+	providerPaymentID string
+	status            string
+	// remaining is refunded when no amount is given
+	remaining string
+}
+
+func (store *refundStore) CreateRefundTx(ctx context.Context, arg db.CreateRefundTxParams) (db.CreateRefundTxResult, error) {
+	store.status = db.RefundStatusPending
+	return db.CreateRefundTxResult{
+		Refund:  db.Refund{ID: 1, OrderID: arg.OrderID, PaymentID: 1, Amount: cmp.Or(arg.Amount, store.remaining), Status: store.status},
+		Payment: db.Payment{ID: 1, OrderID: arg.OrderID, ProviderPaymentID: store.providerPaymentID},
+	}, nil
+}
+
+func (store *refundStore) CompleteRefundTx(ctx context.Context, arg db.CompleteRefundTxParams) (db.CompleteRefundTxResult, error) {
+	store.status = db.RefundStatusSucceeded
+	return db.CompleteRefundTxResult{Refund: db.Refund{ID: arg.RefundID, Status: store.status}}, nil
+}
+
+func (store *refundStore) FailRefundTx(ctx context.Context, refundID int32, reason string) (db.Refund, error) {
+	store.status = db.RefundStatusFailed
+	return db.Refund{ID: refundID, Status: store.status, FailureReason: reason}, nil
+}
+
+func TestRefundOrder(t *testing.T) {
+	testCases := []struct {
+		name         string
+		capture      int64
+		amount       string
+		status       int
+		refundStatus string
+	}{
+		{name: "Refunded", capture: 2550, amount: "10.00", status: http.StatusCreated, refundStatus: db.RefundStatusSucceeded},
+		{name: "GatewayRejects", capture: 500, amount: "10.00", status: http.StatusBadGateway, refundStatus: db.RefundStatusFailed},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			gateway := payments.NewFakeGateway()
+			auth, err := gateway.Authorize(context.Background(), payments.AuthorizeRequest{Amount: tc.capture, Currency: "USD", PaymentMethod: payments.FakeCardSuccess})
+			require.NoError(t, err)
+			_, err = gateway.Capture(context.Background(), auth.ID, tc.capture)
+			require.NoError(t, err)
+
+			store := &refundStore{providerPaymentID: auth.ID}
+			server := &Server{store: store, gateway: gateway}
+			ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+
+			refund, status, err := server.refundOrder(ctx, db.CreateRefundTxParams{OrderID: 7, Amount: tc.amount, CreatedBy: 1})
+
+			require.Equal(t, tc.status, status)
+			require.Equal(t, tc.refundStatus, store.status)
+			if tc.status != http.StatusCreated {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, db.RefundStatusSucceeded, refund.Status)
+			require.Empty(t, refund.Items)
+		})
+	}
+}
+
+// cancelStore also knows the payments of the cancelled order
+type cancelStore struct {
+	refundStore
+	payment db.Payment
+}
+
+func (store *cancelStore) ListPaymentsByOrderId(ctx context.Context, orderID int32) ([]db.Payment, error) {
+	return []db.Payment{store.payment}, nil
+}
+
+func (store *cancelStore) UpdatePaymentStatus(ctx context.Context, arg db.UpdatePaymentStatusParams) (db.Payment, error) {
+	store.payment.Status = arg.Status
+	return store.payment, nil
+}
+
+func TestReleaseOrderPayments(t *testing.T) {
+	testCases := []struct {
+		name          string
+		card          string
+		capture       bool
+		paymentStatus payments.Status
+		refundStatus  string
+	}{
+		{name: "CapturedIsRefunded", card: payments.FakeCardSuccess, capture: true, paymentStatus: payments.StatusCaptured, refundStatus: db.RefundStatusSucceeded},
+		{name: "AuthorizationIsVoided", card: payments.FakeCardSuccess, paymentStatus: payments.StatusVoided},
+		{name: "ActionRequiredIsVoided", card: payments.FakeCardRequiresAction, paymentStatus: payments.StatusVoided},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			gateway := payments.NewFakeGateway()
+			auth, err := gateway.Authorize(context.Background(), payments.AuthorizeRequest{Amount: 2550, Currency: "USD", PaymentMethod: tc.card})
+			require.NoError(t, err)
+			if tc.capture {
+				auth, err = gateway.Capture(context.Background(), auth.ID, 2550)
+				require.NoError(t, err)
+			}
+
+			store := &cancelStore{
+				refundStore: refundStore{providerPaymentID: auth.ID, remaining: "25.50"},
+				payment:     db.Payment{ID: 1, OrderID: 7, ProviderPaymentID: auth.ID, Status: string(auth.Status)},
+			}
+			server := &Server{store: store, gateway: gateway}
+			ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+
+			status, err := server.releaseOrderPayments(ctx, db.Order{ID: 7}, 1)
+			require.NoError(t, err)
+			require.Less(t, status, http.StatusBadRequest)
+			require.Equal(t, string(tc.paymentStatus), store.payment.Status)
+			require.Equal(t, tc.refundStatus, store.status)
+
+			// nothing is left at the gateway either
+			_, err = gateway.Void(context.Background(), auth.ID)
+			require.ErrorIs(t, err, payments.ErrInvalidPaymentOp)
+		})
+	}
+}
+
+// returnStore knows the refund linked to the return and what was created
+type returnStore struct {
+	refundStore
+	linked  db.Refund
+	created *db.CreateRefundTxParams
+}
+
+func (store *returnStore) GetRefund(ctx context.Context, id int32) (db.Refund, error) {
+	return store.linked, nil
+}
+
+func (store *returnStore) ListRefundItemsByOrderId(ctx context.Context, orderID int32) ([]db.RefundItem, error) {
+	return nil, nil
+}
+
+func (store *returnStore) CreateRefundTx(ctx context.Context, arg db.CreateRefundTxParams) (db.CreateRefundTxResult, error) {
+	store.created = &arg
+	return store.refundStore.CreateRefundTx(ctx, arg)
+}
+
+func TestRefundReturn(t *testing.T) {
+	testCases := []struct {
+		name         string
+		linked       string
+		expectCreate bool
+	}{
+		{name: "FirstAttempt", expectCreate: true},
+		{name: "PendingIsCompleted", linked: db.RefundStatusPending},
+		{name: "SucceededIsReused", linked: db.RefundStatusSucceeded},
+		{name: "FailedIsReplaced", linked: db.RefundStatusFailed, expectCreate: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			gateway := payments.NewFakeGateway()
+			auth, err := gateway.Authorize(context.Background(), payments.AuthorizeRequest{Amount: 2550, Currency: "USD", PaymentMethod: payments.FakeCardSuccess})
+			require.NoError(t, err)
+			_, err = gateway.Capture(context.Background(), auth.ID, 2550)
+			require.NoError(t, err)
+
+			store := &returnStore{refundStore: refundStore{providerPaymentID: auth.ID, remaining: "25.50"}}
+			ret := db.Return{ID: 3, OrderID: 7, Status: util.ReturnStatusReceived}
+			if tc.linked != "" {
+				store.linked = db.Refund{ID: 1, OrderID: 7, PaymentID: 1, Amount: "25.50", Status: tc.linked}
+				ret.RefundID = sql.NullInt32{Int32: 1, Valid: true}
+			}
+			server := &Server{store: store, gateway: gateway}
+			ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+
+			refund, status, err := server.refundReturn(ctx, ret, []db.ReturnItem{{OrderItemID: 5, Quantity: 1}}, 1)
+			require.NoError(t, err)
+			require.Less(t, status, http.StatusBadRequest)
+			require.Equal(t, db.RefundStatusSucceeded, refund.Status)
+
+			if !tc.expectCreate {
+				require.Nil(t, store.created)
+				return
+			}
+			require.NotNil(t, store.created)
+			require.Equal(t, ret.ID, store.created.ReturnID)
+		})
+	}
+}
+
+func (store *cancelStore) CancelOrderTx(ctx context.Context, arg db.CancelOrderTxParams) (db.Order, error) {
+	return db.Order{ID: arg.OrderID, Status: util.OrderStatusCancelled}, nil
+}
+
+func TestUpdateOrderCancelReleasesPayments(t *testing.T) {
+	gateway := payments.NewFakeGateway()
+	auth, err := gateway.Authorize(context.Background(), payments.AuthorizeRequest{Amount: 2550, Currency: "USD", PaymentMethod: payments.FakeCardSuccess})
+	require.NoError(t, err)
+	auth, err = gateway.Capture(context.Background(), auth.ID, 2550)
+	require.NoError(t, err)
+
+	store := &cancelStore{
+		refundStore: refundStore{providerPaymentID: auth.ID, remaining: "25.50"},
+		payment:     db.Payment{ID: 1, OrderID: 7, ProviderPaymentID: auth.ID, Status: string(auth.Status)},
+	}
+	server := &Server{store: store, gateway: gateway}
+
+	recorder := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(recorder)
+	ctx.Request = httptest.NewRequest(http.MethodPut, "/orders/7", strings.NewReader(`{"status":"cancelled"}`))
+	ctx.Params = gin.Params{{Key: "id", Value: "7"}}
+	ctx.Set(authorizationPayloadKey, &token.Payload{UserID: 1, Role: util.AdminRole})
+
+	server.updateOrder(ctx)
+
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.Equal(t, db.RefundStatusSucceeded, store.status)
+}
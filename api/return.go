@@ -0,0 +1,508 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	db "github.com/cihanalici/api/db/sqlc"
+	"github.com/cihanalici/api/token"
+	"github.com/cihanalici/api/util"
+	"github.com/gin-gonic/gin"
+)
+
+type returnItemResponse struct {
+	OrderItemID int32 `json:"order_item_id"`
+	Quantity    int32 `json:"quantity"`
+}
+
+type returnResponse struct {
+	ID             int32                `json:"id"`
+	OrderID        int32                `json:"order_id"`
+	UserID         int32                `json:"user_id"`
+	Status         string               `json:"status"`
+	Reason         string               `json:"reason"`
+	ResolutionNote string               `json:"resolution_note,omitempty"`
+	RefundID       *int32               `json:"refund_id,omitempty"`
+	Items          []returnItemResponse `json:"items,omitempty"`
+	Refund         *refundResponse      `json:"refund,omitempty"`
+	CreatedAt      time.Time            `json:"created_at"`
+	UpdatedAt      time.Time            `json:"updated_at"`
+}
+
+func returnNotation(ret db.Return, items []db.ReturnItem) returnResponse {
+	rsp := returnResponse{
+		ID:             ret.ID,
+		OrderID:        ret.OrderID,
+		UserID:         ret.UserID,
+		Status:         ret.Status,
+		Reason:         ret.Reason,
+		ResolutionNote: ret.ResolutionNote,
+		CreatedAt:      ret.CreatedAt,
+		UpdatedAt:      ret.UpdatedAt,
+	}
+
+	if ret.RefundID.Valid {
+		rsp.RefundID = &ret.RefundID.Int32
+	}
+
+	for _, item := range items {
+		rsp.Items = append(rsp.Items, returnItemResponse{
+			OrderItemID: item.OrderItemID,
+			Quantity:    item.Quantity,
+		})
+	}
+
+	return rsp
+}
+
+func returnsNotation(returns []db.Return) []returnResponse {
+	rsp := make([]returnResponse, len(returns))
+	for i, ret := range returns {
+		rsp[i] = returnNotation(ret, nil)
+	}
+	return rsp
+}
+
+// returnErrorStatus maps errors returned by return transactions to an HTTP status
+func returnErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return http.StatusNotFound
+	case errors.Is(err, util.ErrInvalidReturnItem), errors.Is(err, util.ErrInvalidReturnStatus):
+		return http.StatusBadRequest
+	case errors.Is(err, util.ErrOrderNotReturnable), errors.Is(err, util.ErrReturnTransition):
+		return http.StatusConflict
+	}
+	return http.StatusInternalServerError
+}
+
+// CreateReturn godoc
+// @Summary Request a return
+// @Tags returns
+// @Description open a return request for items of a delivered order. The return is requested until an order manager approves or rejects it.
+// @Accept  json
+// @Produce  json
+// @Param id path int true "Order ID"
+// @Param input body createReturnRequest true "Items to return"
+// @Success 201 {object} returnResponse
+// @Router /orders/{id}/returns [post]
+
+type returnItemRequest struct {
+	OrderItemID int32 `json:"order_item_id" binding:"required,min=1"`
+	Quantity    int32 `json:"quantity" binding:"required,min=1"`
+}
+
+type createReturnRequest struct {
+	Reason string              `json:"reason" binding:"required,max=500"`
+	Items  []returnItemRequest `json:"items" binding:"required,min=1,dive"`
+}
+
+func (server *Server) createReturn(ctx *gin.Context) {
+	var uri getOrderRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var req createReturnRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	order, err := server.store.GetOrderById(ctx, uri.ID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	// only the customer returns their order
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if order.UserID != authPayload.UserID {
+		ctx.JSON(http.StatusNotFound, errorResponse(sql.ErrNoRows))
+		return
+	}
+
+	arg := db.CreateReturnTxParams{
+		OrderID: order.ID,
+		UserID:  authPayload.UserID,
+		Reason:  req.Reason,
+	}
+	for _, item := range req.Items {
+		arg.Items = append(arg.Items, db.ReturnItemParams{
+			OrderItemID: item.OrderItemID,
+			Quantity:    item.Quantity,
+		})
+	}
+
+	result, err := server.store.CreateReturnTx(ctx, arg)
+	if err != nil {
+		ctx.JSON(returnErrorStatus(err), errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, returnNotation(result.Return, result.Items))
+}
+
+// ListOrderReturns godoc
+// @Summary List the returns of an order
+// @Tags returns
+// @Produce  json
+// @Param id path int true "Order ID"
+// @Success 200 {array} returnResponse
+// @Router /orders/{id}/returns [get]
+func (server *Server) listOrderReturns(ctx *gin.Context) {
+	var uri getOrderRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	order, err := server.store.GetOrderById(ctx, uri.ID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	if !authorizeOwner(ctx, order.UserID, orderManagers) {
+		return
+	}
+
+	returns, err := server.store.ListReturnsByOrderId(ctx, order.ID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, returnsNotation(returns))
+}
+
+// ListReturns godoc
+// @Summary List returns
+// @Tags returns
+// @Description list all returns for order managers, optionally by status, or the caller's own returns
+// @Produce  json
+// @Param page_id query int true "Page ID"
+// @Param page_size query int true "Page Size"
+// @Param status query string false "Status"
+// @Success 200 {array} returnResponse
+// @Router /returns [get]
+
+type listReturnsRequest struct {
+	PageID   int32  `form:"page_id" binding:"required,min=1"`
+	PageSize int32  `form:"page_size" binding:"required,min=5,max=10"`
+	Status   string `form:"status"`
+}
+
+func (server *Server) listReturns(ctx *gin.Context) {
+	var req listReturnsRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if req.Status != "" && !util.IsSupportedReturnStatus(req.Status) {
+		ctx.JSON(http.StatusBadRequest, errorResponse(fmt.Errorf("%w: %q", util.ErrInvalidReturnStatus, req.Status)))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	limit, offset := req.PageSize, (req.PageID-1)*req.PageSize
+
+	var returns []db.Return
+	var err error
+	switch {
+	case !hasAccess(authPayload, orderManagers, false):
+		returns, err = server.store.ListReturnsByUserId(ctx, db.ListReturnsByUserIdParams{
+			UserID: authPayload.UserID,
+			Limit:  limit,
+			Offset: offset,
+		})
+	case req.Status != "":
+		returns, err = server.store.ListReturnsByStatus(ctx, db.ListReturnsByStatusParams{
+			Status: req.Status,
+			Limit:  limit,
+			Offset: offset,
+		})
+	default:
+		returns, err = server.store.ListReturns(ctx, db.ListReturnsParams{
+			Limit:  limit,
+			Offset: offset,
+		})
+	}
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, returnsNotation(returns))
+}
+
+// GetReturn godoc
+// @Summary Get a return
+// @Tags returns
+// @Produce  json
+// @Param id path int true "Return ID"
+// @Success 200 {object} returnResponse
+// @Router /returns/{id} [get]
+
+type getReturnRequest struct {
+	ID int32 `uri:"id" binding:"required,min=1"`
+}
+
+func (server *Server) getReturn(ctx *gin.Context) {
+	ret, ok := server.loadReturn(ctx)
+	if !ok {
+		return
+	}
+
+	items, err := server.store.ListReturnItemsByReturnId(ctx, ret.ID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, returnNotation(ret, items))
+}
+
+// loadReturn binds the return id of the request and loads the return for
+// its owner or an order manager, it writes the error response otherwise
+func (server *Server) loadReturn(ctx *gin.Context) (db.Return, bool) {
+	var uri getReturnRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return db.Return{}, false
+	}
+
+	ret, err := server.store.GetReturn(ctx, uri.ID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return ret, false
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return ret, false
+	}
+
+	if !authorizeOwner(ctx, ret.UserID, orderManagers) {
+		return ret, false
+	}
+
+	return ret, true
+}
+
+// CancelReturn godoc
+// @Summary Cancel a return
+// @Tags returns
+// @Description withdraw a return that has not been received yet
+// @Produce  json
+// @Param id path int true "Return ID"
+// @Success 200 {object} returnResponse
+// @Router /returns/{id}/cancel [post]
+func (server *Server) cancelReturn(ctx *gin.Context) {
+	ret, ok := server.loadReturn(ctx)
+	if !ok {
+		return
+	}
+
+	server.updateReturnStatus(ctx, db.UpdateReturnStatusTxParams{
+		ReturnID: ret.ID,
+		Status:   util.ReturnStatusCancelled,
+	})
+}
+
+// ResolveReturn godoc
+// @Summary Approve or reject a return
+// @Tags returns
+// @Description approve a requested return, the customer then sends the goods back, or reject it. A note is required to reject.
+// @Accept  json
+// @Produce  json
+// @Param id path int true "Return ID"
+// @Param input body resolveReturnRequest false "Resolution note"
+// @Success 200 {object} returnResponse
+// @Router /returns/{id}/approve [post]
+// @Router /returns/{id}/reject [post]
+
+type resolveReturnRequest struct {
+	Note string `json:"note" binding:"max=500"`
+}
+
+func (server *Server) approveReturn(ctx *gin.Context) {
+	server.resolveReturn(ctx, util.ReturnStatusApproved)
+}
+
+func (server *Server) rejectReturn(ctx *gin.Context) {
+	server.resolveReturn(ctx, util.ReturnStatusRejected)
+}
+
+func (server *Server) resolveReturn(ctx *gin.Context, status string) {
+	var uri getReturnRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var req resolveReturnRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if status == util.ReturnStatusRejected && req.Note == "" {
+		ctx.JSON(http.StatusBadRequest, errorResponse(errors.New("a note is required to reject a return")))
+		return
+	}
+
+	server.updateReturnStatus(ctx, db.UpdateReturnStatusTxParams{
+		ReturnID: uri.ID,
+		Status:   status,
+		Note:     req.Note,
+	})
+}
+
+func (server *Server) updateReturnStatus(ctx *gin.Context, arg db.UpdateReturnStatusTxParams) {
+	ret, err := server.store.UpdateReturnStatusTx(ctx, arg)
+	if err != nil {
+		ctx.JSON(returnErrorStatus(err), errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, returnNotation(ret, nil))
+}
+
+// ReceiveReturn godoc
+// @Summary Receive the goods of a return
+// @Tags returns
+// @Description mark an approved return received, restock its items and refund them. If the refund fails the return stays received and calling this again retries it, reusing the refund already linked to the return unless that one failed.
+// @Accept  json
+// @Produce  json
+// @Param id path int true "Return ID"
+// @Param input body resolveReturnRequest false "Note"
+// @Success 200 {object} returnResponse
+// @Router /returns/{id}/receive [post]
+func (server *Server) receiveReturn(ctx *gin.Context) {
+	var uri getReturnRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var req resolveReturnRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	ret, err := server.store.GetReturn(ctx, uri.ID)
+	if err != nil {
+		ctx.JSON(returnErrorStatus(err), errorResponse(err))
+		return
+	}
+
+	var items []db.ReturnItem
+	switch ret.Status {
+	case util.ReturnStatusApproved:
+		result, err := server.store.ReceiveReturnTx(ctx, db.ReceiveReturnTxParams{
+			ReturnID: ret.ID,
+			Note:     req.Note,
+		})
+		if err != nil {
+			ctx.JSON(returnErrorStatus(err), errorResponse(err))
+			return
+		}
+		ret, items = result.Return, result.Items
+	case util.ReturnStatusReceived:
+		// the goods are in, only the refund is retried
+		items, err = server.store.ListReturnItemsByReturnId(ctx, ret.ID)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+	default:
+		err := fmt.Errorf("%w: cannot receive a %s return", util.ErrReturnTransition, ret.Status)
+		ctx.JSON(http.StatusConflict, errorResponse(err))
+		return
+	}
+
+	userID := ctx.MustGet(authorizationPayloadKey).(*token.Payload).UserID
+	refund, status, err := server.refundReturn(ctx, ret, items, userID)
+	if err != nil {
+		ctx.JSON(status, errorResponse(err))
+		return
+	}
+
+	ret, err = server.store.MarkReturnRefunded(ctx, db.MarkReturnRefundedParams{
+		ID:       ret.ID,
+		RefundID: util.ToInt32ToNullInt32(refund.ID),
+	})
+	if err != nil {
+		log.Printf("cannot mark return %d refunded by refund %d: %v", uri.ID, refund.ID, err)
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	rsp := returnNotation(ret, items)
+	rsp.Refund = &refund
+	ctx.JSON(http.StatusOK, rsp)
+}
+
+// refundReturn gives back the money of the received return. A retry reuses
+// the refund linked to the return: a pending one already got its money back
+// from the gateway and is only completed, a succeeded one is done. A new
+// refund is only created when there is none or the linked one failed.
+func (server *Server) refundReturn(ctx *gin.Context, ret db.Return, items []db.ReturnItem, userID int32) (refundResponse, int, error) {
+	if ret.RefundID.Valid {
+		refund, err := server.store.GetRefund(ctx, ret.RefundID.Int32)
+		if err != nil {
+			return refundResponse{}, http.StatusInternalServerError, err
+		}
+
+		if refund.Status == db.RefundStatusPending {
+			completed, err := server.store.CompleteRefundTx(ctx, db.CompleteRefundTxParams{
+				RefundID:  refund.ID,
+				ChangedBy: userID,
+			})
+			if err != nil {
+				log.Printf("cannot complete refund %d: %v", refund.ID, err)
+				return refundResponse{}, http.StatusInternalServerError, err
+			}
+			refund = completed.Refund
+		}
+
+		if refund.Status == db.RefundStatusSucceeded {
+			refundItems, err := server.store.ListRefundItemsByOrderId(ctx, refund.OrderID)
+			if err != nil {
+				return refundResponse{}, http.StatusInternalServerError, err
+			}
+			return refundNotation(refund, refundItems), http.StatusOK, nil
+		}
+	}
+
+	arg := db.CreateRefundTxParams{
+		OrderID:   ret.OrderID,
+		Reason:    fmt.Sprintf("return %d", ret.ID),
+		ReturnID:  ret.ID,
+		CreatedBy: userID,
+	}
+	for _, item := range items {
+		arg.Items = append(arg.Items, db.RefundItemParams{
+			OrderItemID: item.OrderItemID,
+			Quantity:    item.Quantity,
+		})
+	}
+
+	return server.refundOrder(ctx, arg)
+}
@@ -1,6 +1,8 @@
 package api
 
 import (
+	"database/sql"
+	"errors"
 	"strconv"
 	"time"
 
@@ -126,7 +128,21 @@ func (server *Server) deleteReview(ctx *gin.Context) {
 		return
 	}
 
-	err = server.store.DeleteReview(ctx, int32(reviewId))
+	review, err := server.store.GetReviewById(ctx, int32(reviewId))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(404, errorResponse(err))
+			return
+		}
+		ctx.JSON(500, errorResponse(err))
+		return
+	}
+
+	if !authorizeOwner(ctx, review.UserID, catalogManagers) {
+		return
+	}
+
+	err = server.store.DeleteReview(ctx, review.ID)
 	if err != nil {
 		ctx.JSON(500, errorResponse(err))
 		return
@@ -4,6 +4,8 @@ import (
 	"fmt"
 
 	"github.com/cihanalici/api/db/sqlc"
+	"github.com/cihanalici/api/mail"
+	"github.com/cihanalici/api/payments"
 	"github.com/cihanalici/api/token"
 	"github.com/cihanalici/api/util"
 	"github.com/gin-gonic/gin"
@@ -13,89 +15,212 @@ type Server struct {
 	config     util.Config
 	store      sqlc.Store
 	tokenMaker token.Maker
+	mailer     mail.Mailer
+	gateway    payments.Gateway
 	router     *gin.Engine
 }
 
-func NewServer(config util.Config, store sqlc.Store) (*Server, error) {
-	tokenMaker, err := token.NewJWTMaker(config.TokenSymmetricKey)
+// newTokenMaker picks the token.Maker named by TOKEN_TYPE, defaulting to JWT
+func newTokenMaker(config util.Config) (token.Maker, error) {
+	switch config.TokenType {
+	case "", "jwt":
+		return token.NewJWTMaker(config.TokenSymmetricKey)
+	case "paseto":
+		return token.NewPasetoMaker(config.TokenSymmetricKey)
+	case "jwt-asymmetric":
+		return token.NewAsymmetricJWTMakerFromFiles(config.TokenPrivateKeyPath, config.TokenPublicKeyPaths)
+	default:
+		return nil, fmt.Errorf("unsupported token type %q", config.TokenType)
+	}
+}
+
+func NewServer(config util.Config, store sqlc.Store, mailer mail.Mailer, gateway payments.Gateway) (*Server, error) {
+	tokenMaker, err := newTokenMaker(config)
 	if err != nil {
 		return nil, fmt.Errorf("cannot create token maker: %w", err)
 	}
 
+	if config.ShippingFlatRate != "" {
+		if _, err := util.ParseMoney(config.ShippingFlatRate); err != nil {
+			return nil, fmt.Errorf("invalid SHIPPING_FLAT_RATE: %w", err)
+		}
+	}
+
 	server := &Server{
 		config:     config,
 		store:      store,
 		tokenMaker: tokenMaker,
+		mailer:     mailer,
+		gateway:    gateway,
 	}
 
-	server.setupRouter()
+	if err := server.setupRouter(); err != nil {
+		return nil, err
+	}
 
 	return server, nil
 }
 
-func (server *Server) setupRouter() {
+// permission matrix: roles allowed on each group of privileged routes and the
+// API key scope that grants the same access
+var (
+	catalogManagers = accessGroup{roles: []string{util.AdminRole, util.StaffRole}, scope: "catalog"}
+	orderManagers   = accessGroup{roles: []string{util.AdminRole, util.StaffRole}, scope: "orders"}
+	orderAdmins     = accessGroup{roles: []string{util.AdminRole}, scope: "orders"}
+	userManagers    = accessGroup{roles: []string{util.AdminRole}, scope: "users"}
+)
+
+func (server *Server) setupRouter() error {
 	router := gin.Default()
 
+	// ClientIP only believes X-Forwarded-For from these proxies, by default
+	// none, so clients cannot pick the IP that logins are throttled by
+	if err := router.SetTrustedProxies(server.config.TrustedProxies); err != nil {
+		return fmt.Errorf("invalid TRUSTED_PROXIES: %w", err)
+	}
+
 	router.POST("/users", server.createUser)
 	router.POST("/users/login", server.loginUser)
+	router.POST("/users/login/mfa", server.loginUserMfa)
+	router.POST("/tokens/renew", server.renewAccessToken)
 
-	authRoutes := router.Group("/").Use(authMiddleware(server.tokenMaker))
-
-	authRoutes.GET("/users/:id", server.getUserByID)
-	authRoutes.GET("/users", server.getUsers)
-	authRoutes.PUT("/users/:id", server.updateUser)
-	authRoutes.DELETE("/users/:id", server.deleteUser)
+	if _, ok := server.tokenMaker.(token.KeySetProvider); ok {
+		router.GET("/.well-known/jwks.json", server.getKeySet)
+	}
 
-	authRoutes.POST("/categories", server.createCategory)
+	// authRoutes act on the caller's own account and are closed to API keys,
+	// on sharedRoutes handlers check ownership or access themselves
+	authRoutes := router.Group("/").Use(authMiddleware(server.tokenMaker, server.store), requireUserToken())
+	sharedRoutes := router.Group("/").Use(authMiddleware(server.tokenMaker, server.store))
+	catalogRoutes := router.Group("/").Use(authMiddleware(server.tokenMaker, server.store), requireAccess(catalogManagers), server.requireMfaEnrollment())
+	orderRoutes := router.Group("/").Use(authMiddleware(server.tokenMaker, server.store), requireAccess(orderManagers), server.requireMfaEnrollment())
+	orderAdminRoutes := router.Group("/").Use(authMiddleware(server.tokenMaker, server.store), requireAccess(orderAdmins), server.requireMfaEnrollment())
+	userRoutes := router.Group("/").Use(authMiddleware(server.tokenMaker, server.store), requireAccess(userManagers), server.requireMfaEnrollment())
+	// userAdminRoutes hand out or strip privileges, only an admin's own token
+	// may call them so an API key cannot raise anyone's access
+	userAdminRoutes := router.Group("/").Use(authMiddleware(server.tokenMaker, server.store), requireUserToken(), requireAccess(userManagers), server.requireMfaEnrollment())
+	cartRoutes := router.Group("/").Use(optionalAuthMiddleware(server.tokenMaker, server.store))
+
+	sharedRoutes.GET("/users/:id", server.getUserByID)
+	userRoutes.GET("/users", server.getUsers)
+	sharedRoutes.PUT("/users/:id", server.updateUser)
+	sharedRoutes.DELETE("/users/:id", server.deleteUser)
+	userAdminRoutes.PUT("/users/:id/role", server.updateUserRole)
+	router.POST("/users/unlock", server.unlockAccount)
+	userRoutes.GET("/login-lockouts", server.listLoginLockouts)
+	userRoutes.DELETE("/login-lockouts/:id", server.clearLoginLockout)
+	authRoutes.POST("/users/logout", server.logoutUser)
+	authRoutes.POST("/users/logout-all", server.logoutAllSessions)
+
+	// two-factor authentication
+	authRoutes.POST("/users/mfa/setup", server.setupMfa)
+	authRoutes.POST("/users/mfa/confirm", server.confirmMfa)
+	authRoutes.POST("/users/mfa/disable", server.disableMfa)
+	authRoutes.POST("/users/mfa/recovery-codes", server.regenerateMfaRecoveryCodes)
+	userAdminRoutes.DELETE("/users/:id/mfa", server.resetUserMfa)
+
+	// address book
+	authRoutes.GET("/users/me/addresses", server.listAddresses)
+	authRoutes.POST("/users/me/addresses", server.createAddress)
+	authRoutes.GET("/users/me/addresses/:id", server.getAddress)
+	authRoutes.PUT("/users/me/addresses/:id", server.updateAddress)
+	authRoutes.DELETE("/users/me/addresses/:id", server.deleteAddress)
+
+	// api keys
+	userAdminRoutes.POST("/api-keys", server.createApiKey)
+	userAdminRoutes.GET("/api-keys", server.listApiKeys)
+	userAdminRoutes.DELETE("/api-keys/:id", server.revokeApiKey)
+
+	catalogRoutes.POST("/categories", server.createCategory)
 	router.GET("/categories/:id", server.getCategory)
 	router.GET("/categories", server.getCategories)
-	authRoutes.PUT("/categories/:id", server.updateCategory)
-	authRoutes.DELETE("/categories/:id", server.deleteCategory)
+	catalogRoutes.PUT("/categories/:id", server.updateCategory)
+	catalogRoutes.DELETE("/categories/:id", server.deleteCategory)
 
-	authRoutes.POST("/products", server.createProduct)
+	catalogRoutes.POST("/products", server.createProduct)
 	router.GET("/products/:id", server.getProduct)
 	router.GET("/products", server.getProducts)
-	authRoutes.PUT("/products/:id", server.updateProduct)
-	authRoutes.DELETE("/products/:id", server.deleteProduct)
+	catalogRoutes.PUT("/products/:id", server.updateProduct)
+	catalogRoutes.DELETE("/products/:id", server.deleteProduct)
+
+	catalogRoutes.POST("/coupons", server.createCoupon)
+	catalogRoutes.GET("/coupons", server.listCoupons)
+	catalogRoutes.GET("/coupons/:id", server.getCoupon)
+	catalogRoutes.PUT("/coupons/:id", server.updateCoupon)
+	catalogRoutes.DELETE("/coupons/:id", server.deleteCoupon)
+
+	catalogRoutes.POST("/promotions", server.createPromotion)
+	catalogRoutes.GET("/promotions", server.listPromotions)
+	catalogRoutes.GET("/promotions/:id", server.getPromotion)
+	catalogRoutes.PUT("/promotions/:id", server.updatePromotion)
+	catalogRoutes.DELETE("/promotions/:id", server.deletePromotion)
 
 	authRoutes.POST("/orders", server.createOrder)
-	router.GET("/orders/:id", server.getOrder)
-	router.GET("/orders", server.ListOrders)
-	authRoutes.PUT("/orders/:id", server.updateOrder)
-	authRoutes.DELETE("/orders/:id", server.deleteOrder)
+	sharedRoutes.GET("/orders/:id", server.getOrder)
+	sharedRoutes.GET("/orders", server.ListOrders)
+	orderRoutes.PUT("/orders/:id", server.updateOrder)
+	orderAdminRoutes.PUT("/orders/:id/addresses", server.updateOrderAddresses)
+	sharedRoutes.GET("/orders/:id/timeline", server.getOrderTimeline)
+	sharedRoutes.POST("/orders/:id/cancel", server.cancelOrder)
+	orderRoutes.DELETE("/orders/:id", server.deleteOrder)
+	authRoutes.POST("/orders/:id/pay", server.payOrder)
+	sharedRoutes.GET("/orders/:id/payments", server.listOrderPayments)
+	authRoutes.POST("/orders/:id/payments/:payment_id/confirm", server.confirmOrderPayment)
+	orderRoutes.POST("/orders/:id/refunds", server.createRefund)
+	sharedRoutes.GET("/orders/:id/refunds", server.listOrderRefunds)
+	authRoutes.POST("/orders/:id/returns", server.createReturn)
+	sharedRoutes.GET("/orders/:id/returns", server.listOrderReturns)
+	sharedRoutes.GET("/returns", server.listReturns)
+	sharedRoutes.GET("/returns/:id", server.getReturn)
+	sharedRoutes.POST("/returns/:id/cancel", server.cancelReturn)
+	orderRoutes.POST("/returns/:id/approve", server.approveReturn)
+	orderRoutes.POST("/returns/:id/reject", server.rejectReturn)
+	orderRoutes.POST("/returns/:id/receive", server.receiveReturn)
 	authRoutes.GET("/orders/user", server.getOrdersByUserId)
 
 	//product variants
-	authRoutes.POST("/product_variants", server.createProductVariant)
+	catalogRoutes.POST("/product_variants", server.createProductVariant)
 	router.GET("/product_variants/:id", server.getProductVariant)
 	router.GET("/product_variants", server.listProductVariants)
-	authRoutes.PUT("/product_variants/:id", server.updateProductVariant)
-	authRoutes.DELETE("/product_variants/:id", server.deleteProductVariant)
+	catalogRoutes.PUT("/product_variants/:id", server.updateProductVariant)
+	catalogRoutes.DELETE("/product_variants/:id", server.deleteProductVariant)
 
 	//wishlist
 	authRoutes.POST("/wishlists", server.createWishlist)
-	router.GET("/wishlists/:id", server.getWishlist)
-	router.GET("/wishlists", server.listWishlist)
-	authRoutes.DELETE("/wishlists/:id", server.deleteWishlist)
+	sharedRoutes.GET("/wishlists/:id", server.getWishlist)
+	sharedRoutes.GET("/wishlists", server.listWishlist)
+	sharedRoutes.DELETE("/wishlists/:id", server.deleteWishlist)
 	authRoutes.GET("/wishlists/user", server.getWishlistByUser)
 
+	//cart
+	cartRoutes.GET("/cart", server.getCart)
+	cartRoutes.POST("/cart/items", server.addCartItem)
+	cartRoutes.PUT("/cart/items/:id", server.updateCartItem)
+	cartRoutes.DELETE("/cart/items/:id", server.deleteCartItem)
+	authRoutes.POST("/cart/checkout", server.checkoutCart)
+
 	//order items
-	authRoutes.GET("/order_items", server.listOrderItems)
-	router.GET("/order_items/:id", server.getOrderItem)
-	authRoutes.GET("/order_items/order/:id", server.getOrderItemsByOrderId)
+	orderRoutes.GET("/order_items", server.listOrderItems)
+	sharedRoutes.GET("/order_items/:id", server.getOrderItem)
+	sharedRoutes.GET("/order_items/order/:id", server.getOrderItemsByOrderId)
 
 	//reviews
 	authRoutes.POST("/reviews", server.createReview)
 	router.GET("/reviews/:id", server.getReview)
 	router.GET("/reviews", server.listReviews)
 	router.GET("/reviews/product/:id", server.getReviewsByProductId)
-	authRoutes.DELETE("/reviews/:id", server.deleteReview)
+	sharedRoutes.DELETE("/reviews/:id", server.deleteReview)
 
 	// reset password
-	authRoutes.POST("/users/request-password-reset", server.requestPasswordReset)
-	authRoutes.POST("/users/reset-password", server.resetPassword)
+	router.POST("/users/request-password-reset", server.requestPasswordReset)
+	router.POST("/users/reset-password", server.resetPassword)
+
+	// email verification
+	router.POST("/users/verify-email", server.verifyEmail)
+	router.POST("/users/resend-verification", server.resendEmailVerification)
 
 	server.router = router
+	return nil
 }
 
 func (server *Server) Start(address string) error {
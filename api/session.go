@@ -0,0 +1,245 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	db "github.com/cihanalici/api/db/sqlc"
+	"github.com/cihanalici/api/token"
+	"github.com/cihanalici/api/util"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// defaultRefreshTokenDuration is used when REFRESH_TOKEN_DURATION is not set
+const defaultRefreshTokenDuration = 24 * time.Hour
+
+func (server *Server) refreshTokenDuration() time.Duration {
+	if server.config.RefreshTokenDuration <= 0 {
+		return defaultRefreshTokenDuration
+	}
+	return server.config.RefreshTokenDuration
+}
+
+type sessionTokens struct {
+	SessionID             uuid.UUID `json:"session_id"`
+	AccessToken           string    `json:"access_token"`
+	AccessTokenExpiresAt  time.Time `json:"access_token_expires_at"`
+	RefreshToken          string    `json:"refresh_token"`
+	RefreshTokenExpiresAt time.Time `json:"refresh_token_expires_at"`
+}
+
+// newSessionTokens creates an access and a refresh token bound to a fresh
+// session id together with the parameters needed to persist that session
+func (server *Server) newSessionTokens(ctx *gin.Context, userID int32, role string) (sessionTokens, db.CreateSessionParams, error) {
+	var tokens sessionTokens
+
+	sessionID, err := uuid.NewRandom()
+	if err != nil {
+		return tokens, db.CreateSessionParams{}, err
+	}
+
+	accessToken, accessPayload, err := server.tokenMaker.CreateToken(
+		userID, role, sessionID, token.TokenTypeAccessToken, server.config.AccessTokenDuration,
+	)
+	if err != nil {
+		return tokens, db.CreateSessionParams{}, err
+	}
+
+	refreshToken, refreshPayload, err := server.tokenMaker.CreateToken(
+		userID, role, sessionID, token.TokenTypeRefreshToken, server.refreshTokenDuration(),
+	)
+	if err != nil {
+		return tokens, db.CreateSessionParams{}, err
+	}
+
+	tokens = sessionTokens{
+		SessionID:             sessionID,
+		AccessToken:           accessToken,
+		AccessTokenExpiresAt:  accessPayload.ExpiredAt,
+		RefreshToken:          refreshToken,
+		RefreshTokenExpiresAt: refreshPayload.ExpiredAt,
+	}
+
+	session := db.CreateSessionParams{
+		ID:           sessionID,
+		UserID:       userID,
+		RefreshToken: refreshToken,
+		UserAgent:    ctx.Request.UserAgent(),
+		ClientIp:     ctx.ClientIP(),
+		IsBlocked:    false,
+		ExpiresAt:    refreshPayload.ExpiredAt,
+	}
+
+	return tokens, session, nil
+}
+
+// startSession issues a new pair of tokens for a user that just logged in
+func (server *Server) startSession(ctx *gin.Context, user db.User) (sessionTokens, error) {
+	tokens, session, err := server.newSessionTokens(ctx, user.ID, user.Role)
+	if err != nil {
+		return tokens, err
+	}
+
+	_, err = server.store.CreateSession(ctx, session)
+	return tokens, err
+}
+
+// RenewAccessToken godoc
+// @Summary Renew the access token
+// @Description Exchange a refresh token for a new access token and a new refresh token. Each refresh token can be used only once.
+// @Tags tokens
+// @Accept json
+// @Produce json
+// @Param request body renewAccessTokenRequest true "Refresh token"
+// @Success 200 {object} sessionTokens
+// @Router /tokens/renew [post]
+
+type renewAccessTokenRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+func (server *Server) renewAccessToken(ctx *gin.Context) {
+	var req renewAccessTokenRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	refreshPayload, err := server.tokenMaker.VerifyToken(req.RefreshToken, token.TokenTypeRefreshToken)
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+
+	// the role may have changed since the refresh token was issued
+	user, err := server.store.GetUserById(ctx, refreshPayload.UserID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusUnauthorized, errorResponse(util.ErrInvalidToken))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	tokens, session, err := server.newSessionTokens(ctx, user.ID, user.Role)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	_, err = server.store.RotateSessionTx(ctx, db.RotateSessionTxParams{
+		SessionID:    refreshPayload.SessionID,
+		UserID:       refreshPayload.UserID,
+		RefreshToken: req.RefreshToken,
+		NewSession:   session,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			ctx.JSON(http.StatusUnauthorized, errorResponse(util.ErrInvalidToken))
+		case errors.Is(err, util.ErrSessionBlocked):
+			// a refresh token that was already rotated or logged out is being
+			// replayed, so every session of the user is treated as compromised
+			if err := server.store.BlockUserSessions(ctx, refreshPayload.UserID); err != nil {
+				ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+				return
+			}
+			ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		case errors.Is(err, util.ErrInvalidToken), errors.Is(err, util.ErrExpiredToken):
+			ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		default:
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusOK, tokens)
+}
+
+// GetKeySet godoc
+// @Summary Public keys used to sign tokens
+// @Description JSON Web Key Set for verifying access tokens offline. Only served when tokens are signed with an asymmetric key.
+// @Tags tokens
+// @Produce json
+// @Success 200 {object} token.JSONWebKeySet
+// @Router /.well-known/jwks.json [get]
+func (server *Server) getKeySet(ctx *gin.Context) {
+	keySetProvider, ok := server.tokenMaker.(token.KeySetProvider)
+	if !ok {
+		ctx.JSON(http.StatusNotFound, errorResponse(errors.New("token maker has no public keys")))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, keySetProvider.KeySet())
+}
+
+// revokeToken stores the id of a token so it is rejected until it expires.
+// Revoked tokens that expired in the meantime are pruned on the way, they
+// are rejected as expired anyway.
+func (server *Server) revokeToken(ctx *gin.Context, payload *token.Payload) error {
+	err := server.store.CreateRevokedToken(ctx, db.CreateRevokedTokenParams{
+		ID:        payload.ID,
+		UserID:    payload.UserID,
+		ExpiresAt: payload.ExpiredAt,
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := server.store.DeleteExpiredRevokedTokens(ctx); err != nil {
+		log.Printf("cannot delete expired revoked tokens: %v", err)
+	}
+
+	return nil
+}
+
+// LogoutUser godoc
+// @Summary Log out
+// @Description Revoke the current access token and block its session so the refresh token can no longer be used
+// @Tags users
+// @Produce json
+// @Success 200 {object} map[string]string
+// @Router /users/logout [post]
+func (server *Server) logoutUser(ctx *gin.Context) {
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	if err := server.revokeToken(ctx, authPayload); err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	if err := server.store.BlockSession(ctx, authPayload.SessionID); err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+// LogoutAllSessions godoc
+// @Summary Log out from all devices
+// @Description Block every session of the current user, revoking all of their access and refresh tokens
+// @Tags users
+// @Produce json
+// @Success 200 {object} map[string]string
+// @Router /users/logout-all [post]
+func (server *Server) logoutAllSessions(ctx *gin.Context) {
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	if err := server.revokeToken(ctx, authPayload); err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	if err := server.store.BlockUserSessions(ctx, authPayload.UserID); err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
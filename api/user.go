@@ -1,33 +1,28 @@
 package api
 
 import (
-	"encoding/json"
+	"database/sql"
+	"errors"
 	"fmt"
+	"log"
 	"net/http"
+	"net/url"
 	"strconv"
 	"time"
 
 	db "github.com/cihanalici/api/db/sqlc"
+	"github.com/cihanalici/api/mail"
 	"github.com/cihanalici/api/token"
 	"github.com/cihanalici/api/util"
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	"github.com/lib/pq"
 )
 
 type createUserRequest struct {
-	Name      string          `json:"name" binding:"required"`
-	Email     string          `json:"email" binding:"required,email"`
-	Password  string          `json:"password" binding:"required,min=6"`
-	Addresses json.RawMessage `json:"addresses"`
-}
-type Address struct {
-	Street   string `json:"street"`
-	City     string `json:"city"`
-	State    string `json:"state"`
-	Zip      string `json:"zip"`
-	Country  string `json:"country"`
-	Email    string `json:"email"`
-	FullName string `json:"full_name"`
+	Name     string `json:"name" binding:"required"`
+	Email    string `json:"email" binding:"required,email"`
+	Password string `json:"password" binding:"required,min=6"`
 }
 
 type createUserResponse struct {
@@ -35,26 +30,21 @@ type createUserResponse struct {
 	Name  string `json:"name"`
 	Email string `json:"email"`
 	Role  string `json:"role"`
-	// addresses json data type
-	Addresses json.RawMessage `json:"addresses"`
-	CreatedAt time.Time       `json:"created_at"`
-	UpdatedAt time.Time       `json:"updated_at"`
+	// EmailVerified tells whether the user confirmed their email address
+	EmailVerified bool      `json:"email_verified"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
 }
 
 func userResponse(user db.User) createUserResponse {
-	addressesBytes, err := json.Marshal(user.Addresses)
-	if err != nil {
-		return createUserResponse{}
-	}
-
 	return createUserResponse{
-		ID:        user.ID,
-		Name:      user.Name,
-		Email:     user.Email,
-		Role:      user.Role,
-		Addresses: addressesBytes,
-		CreatedAt: user.CreatedAt,
-		UpdatedAt: user.UpdatedAt,
+		ID:            user.ID,
+		Name:          user.Name,
+		Email:         user.Email,
+		Role:          user.Role,
+		EmailVerified: user.EmailVerifiedAt.Valid,
+		CreatedAt:     user.CreatedAt,
+		UpdatedAt:     user.UpdatedAt,
 	}
 }
 
@@ -81,11 +71,10 @@ func (server *Server) createUser(ctx *gin.Context) {
 	}
 
 	arg := db.CreateUserParams{
-		Name:      req.Name,
-		Email:     req.Email,
-		Password:  hashedPassword,
-		Role:      "user",
-		Addresses: req.Addresses,
+		Name:     req.Name,
+		Email:    req.Email,
+		Password: hashedPassword,
+		Role:     util.UserRole,
 	}
 
 	user, err := server.store.CreateUser(ctx, arg)
@@ -106,6 +95,12 @@ func (server *Server) createUser(ctx *gin.Context) {
 
 	fmt.Println(user)
 
+	server.sendEmail(ctx, user.Email, mail.TemplateWelcome, mail.WelcomeData{Name: user.Name})
+
+	if err := server.sendEmailVerification(ctx, user); err != nil {
+		log.Printf("cannot send email verification to user %d: %v", user.ID, err)
+	}
+
 	rsp := userResponse(user)
 	ctx.JSON(http.StatusOK, rsp)
 }
@@ -113,11 +108,21 @@ func (server *Server) createUser(ctx *gin.Context) {
 type loginUserRequest struct {
 	Email    string `json:"email" binding:"required,email"`
 	Password string `json:"password" binding:"required,min=6"`
+	// CartToken identifies a guest cart to merge into the user's cart
+	CartToken string `json:"cart_token"`
 }
 
 type loginUserResponse struct {
-	Token string             `json:"token"`
-	User  createUserResponse `json:"user"`
+	// Token is the access token, kept under its original name for existing clients
+	Token                 string             `json:"token"`
+	AccessTokenExpiresAt  time.Time          `json:"access_token_expires_at"`
+	RefreshToken          string             `json:"refresh_token"`
+	RefreshTokenExpiresAt time.Time          `json:"refresh_token_expires_at"`
+	SessionID             uuid.UUID          `json:"session_id"`
+	User                  createUserResponse `json:"user"`
+	// MfaEnrollmentRequired is set when the user's role requires two-factor
+	// authentication and it is not enabled yet
+	MfaEnrollmentRequired bool `json:"mfa_enrollment_required,omitempty"`
 }
 
 func (server *Server) loginUser(ctx *gin.Context) {
@@ -128,27 +133,97 @@ func (server *Server) loginUser(ctx *gin.Context) {
 		return
 	}
 
+	if !server.checkLoginAllowed(ctx, req.Email) {
+		return
+	}
+
 	user, err := server.store.GetUserByEmail(ctx, req.Email)
-	if err != nil {
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
 		return
 	}
+	userFound := err == nil
 
-	err = util.CheckPassword(req.Password, user.Password)
-	if err != nil {
-		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+	// unknown emails and wrong passwords look exactly the same to the caller
+	passwordMatches := false
+	if userFound {
+		passwordMatches = util.CheckPassword(req.Password, user.Password) == nil
+	} else {
+		compareDummyPassword(req.Password)
+	}
+
+	if !passwordMatches {
+		var knownUser *db.User
+		if userFound {
+			knownUser = &user
+		}
+
+		if err := server.recordLoginFailure(ctx, req.Email, knownUser); err != nil {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+
+		ctx.JSON(http.StatusUnauthorized, errorResponse(util.ErrInvalidCredentials))
+		return
+	}
+
+	if server.config.RequireVerifiedEmailLogin && !user.EmailVerifiedAt.Valid {
+		ctx.JSON(http.StatusForbidden, errorResponse(util.ErrEmailNotVerified))
+		return
+	}
+
+	_, err = server.getEnabledMfa(ctx, user.ID)
+	switch {
+	case err == nil:
+		// the failed attempts are only cleared once the second factor is checked
+		server.startMfaLogin(ctx, user)
+	case errors.Is(err, util.ErrMfaNotEnabled):
+		server.completeLogin(ctx, user, req.CartToken)
+	default:
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+	}
+}
+
+// completeLogin starts a session for a user that passed every login step and
+// merges their guest cart
+func (server *Server) completeLogin(ctx *gin.Context, user db.User, cartToken string) {
+	if err := server.clearAccountLoginFailures(ctx, user.Email); err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
 		return
 	}
 
-	token, err := server.tokenMaker.CreateToken(user.ID, server.config.AccessTokenDuration)
+	tokens, err := server.startSession(ctx, user)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
 		return
 	}
 
+	if cartToken == "" {
+		cartToken = ctx.GetHeader(cartTokenHeaderKey)
+	}
+	if cartToken != "" {
+		_, err = server.store.MergeCartTx(ctx, db.MergeCartTxParams{
+			UserID:     user.ID,
+			GuestToken: cartToken,
+		})
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+	}
+
 	rsp := loginUserResponse{
-		Token: token,
-		User:  userResponse(user),
+		Token:                 tokens.AccessToken,
+		AccessTokenExpiresAt:  tokens.AccessTokenExpiresAt,
+		RefreshToken:          tokens.RefreshToken,
+		RefreshTokenExpiresAt: tokens.RefreshTokenExpiresAt,
+		SessionID:             tokens.SessionID,
+		User:                  userResponse(user),
+	}
+
+	if server.roleRequiresMfa(user.Role) {
+		_, err := server.getEnabledMfa(ctx, user.ID)
+		rsp.MfaEnrollmentRequired = err != nil
 	}
 
 	ctx.JSON(http.StatusOK, rsp)
@@ -167,8 +242,16 @@ func (server *Server) getUserByID(ctx *gin.Context) {
 		return
 	}
 
+	if !authorizeOwner(ctx, req.ID, userManagers) {
+		return
+	}
+
 	user, err := server.store.GetUserById(ctx, req.ID)
 	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
 		return
 	}
@@ -209,9 +292,6 @@ func (server *Server) getUsers(ctx *gin.Context) {
 type updateUserRequest struct {
 	Name  string `json:"name"`
 	Email string `json:"email"`
-
-	Role      string          `json:"role"`
-	Addresses json.RawMessage `json:"addresses"`
 }
 
 func (server *Server) updateUser(ctx *gin.Context) {
@@ -221,18 +301,21 @@ func (server *Server) updateUser(ctx *gin.Context) {
 		return
 	}
 
+	if !authorizeOwner(ctx, int32(userId), userManagers) {
+		return
+	}
+
 	user, err := server.store.GetUserById(ctx, int32(userId))
 
 	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
 		return
 	}
 
-	if user.ID != int32(userId) {
-		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
-		return
-	}
-
 	var req updateUserRequest
 
 	if err := ctx.ShouldBindJSON(&req); err != nil {
@@ -241,42 +324,94 @@ func (server *Server) updateUser(ctx *gin.Context) {
 	}
 
 	arg := db.UpdateUserParams{
-		Name:      req.Name,
-		Email:     req.Email,
-		Role:      req.Role,
-		Addresses: req.Addresses,
-		ID:        user.ID,
+		Name:  req.Name,
+		Email: req.Email,
+		ID:    user.ID,
 	}
 
+	previousEmail := user.Email
+
 	user, err = server.store.UpdateUser(ctx, arg)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
 		return
 	}
 
+	// a new address has to be verified again
+	if user.Email != previousEmail {
+		if err := server.sendEmailVerification(ctx, user); err != nil {
+			log.Printf("cannot send email verification to user %d: %v", user.ID, err)
+		}
+	}
+
 	ctx.JSON(http.StatusOK, userResponse(user))
 }
 
-func (server *Server) deleteUser(ctx *gin.Context) {
-	userId, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
-	if err != nil {
+type updateUserRoleRequest struct {
+	Role string `json:"role" binding:"required,oneof=user staff admin"`
+}
+
+// updateUserRole promotes or demotes a user; only admins can reach it. The
+// user is logged out everywhere so the new role applies to the next token.
+func (server *Server) updateUserRole(ctx *gin.Context) {
+	var uri getUserRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
 		ctx.JSON(http.StatusBadRequest, errorResponse(err))
 		return
 	}
 
-	user, err := server.store.GetUserById(ctx, int32(userId))
+	var req updateUserRoleRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if authPayload.UserID == uri.ID && req.Role != authPayload.Role {
+		err := fmt.Errorf("you cannot change your own role")
+		ctx.JSON(http.StatusForbidden, errorResponse(err))
+		return
+	}
 
+	user, err := server.store.UpdateUserRoleTx(ctx, db.UpdateUserRoleParams{
+		Role: req.Role,
+		ID:   uri.ID,
+	})
 	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
 		return
 	}
 
-	if user.ID != int32(userId) {
-		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+	ctx.JSON(http.StatusOK, userResponse(user))
+}
+
+func (server *Server) deleteUser(ctx *gin.Context) {
+	userId, err := strconv.ParseInt(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if !authorizeOwner(ctx, int32(userId), userManagers) {
 		return
 	}
 
-	err = server.store.DeleteUser(ctx, int32(userId))
+	user, err := server.store.GetUserById(ctx, int32(userId))
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	err = server.store.DeleteUser(ctx, user.ID)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
 		return
@@ -287,15 +422,24 @@ func (server *Server) deleteUser(ctx *gin.Context) {
 
 // reset password işlemleri
 
+// passwordResetRequestedMessage is returned whether or not the email belongs
+// to an account so the endpoint cannot be used to discover users
+const passwordResetRequestedMessage = "if an account exists for this email, a password reset link has been sent"
+
+// RequestPasswordReset godoc
+// @Summary Request a password reset link
+// @Description Email a single use password reset link. The response is the same whether or not the email has an account.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param request body requestPasswordResetRequest true "Email"
+// @Success 200 {object} map[string]string
+// @Router /users/request-password-reset [post]
+
 type requestPasswordResetRequest struct {
 	Email string `json:"email" binding:"required,email"`
 }
 
-type resetPasswordRequest struct {
-	Token       string `json:"token" binding:"required"`
-	NewPassword string `json:"new_password" binding:"required,min=6"`
-}
-
 func (server *Server) requestPasswordReset(ctx *gin.Context) {
 	var req requestPasswordResetRequest
 
@@ -306,44 +450,67 @@ func (server *Server) requestPasswordReset(ctx *gin.Context) {
 
 	user, err := server.store.GetUserByEmail(ctx, req.Email)
 	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusOK, gin.H{"status": passwordResetRequestedMessage})
+			return
+		}
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
 		return
 	}
 
-	token, err := token.GenerateResetToken()
+	resetToken, err := token.GenerateResetToken()
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
 		return
 	}
 
-	err = server.storeResetToken(ctx, user.ID, token)
+	_, err = server.store.CreatePasswordResetTx(ctx, db.CreatePasswordResetTxParams{
+		UserID:     user.ID,
+		ResetToken: token.HashToken(resetToken),
+		ExpiresAt:  time.Now().Add(server.config.ResetPasswordDuration),
+	})
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
 		return
 	}
 
-	err = util.SendResetEmail(req.Email, token)
-	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
-		return
-	}
+	// delivery errors are only logged: answering with one would tell the caller the account exists
+	server.sendEmail(ctx, user.Email, mail.TemplatePasswordReset, mail.PasswordResetData{
+		Name:      user.Name,
+		ResetLink: linkWithToken(server.config.ResetPasswordRedirectURL, resetToken),
+		ExpiresIn: server.config.ResetPasswordDuration,
+	})
 
-	ctx.JSON(http.StatusOK, gin.H{"status": "password reset email sent"})
+	ctx.JSON(http.StatusOK, gin.H{"status": passwordResetRequestedMessage})
 }
 
-func (server *Server) storeResetToken(ctx *gin.Context, userID int32, token string) error {
-	arg := db.CreatePasswordResetParams{
-		UserID:     userID,
-		ResetToken: token,
-		ExpiresAt:  time.Now().Add(time.Hour * 1),
-	}
-
-	_, err := server.store.CreatePasswordReset(ctx, arg)
+// linkWithToken adds token to the query of a frontend URL the user is sent to
+func linkWithToken(baseURL, token string) string {
+	link, err := url.Parse(baseURL)
 	if err != nil {
-		return err
+		return baseURL + "?token=" + url.QueryEscape(token)
 	}
 
-	return nil
+	query := link.Query()
+	query.Set("token", token)
+	link.RawQuery = query.Encode()
+
+	return link.String()
+}
+
+// ResetPassword godoc
+// @Summary Set a new password with a reset token
+// @Description Consume a password reset token, set the new password and log the user out of every session
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param request body resetPasswordRequest true "Reset token and new password"
+// @Success 200 {object} map[string]string
+// @Router /users/reset-password [post]
+
+type resetPasswordRequest struct {
+	Token       string `json:"token" binding:"required"`
+	NewPassword string `json:"new_password" binding:"required,min=6"`
 }
 
 func (server *Server) resetPassword(ctx *gin.Context) {
@@ -354,50 +521,27 @@ func (server *Server) resetPassword(ctx *gin.Context) {
 		return
 	}
 
-	// bu alınan tokeni int32'ye çevirip userId'e atamamız gerekiyor
-	userIdByToken, _ := ctx.Get("userId")
-	userId, _ := userIdByToken.(int32)
-
-	fmt.Println(userId)
-	fmt.Println(req.Token)
-
-	err := server.verifyToken(ctx, userId, req.Token)
-	if err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
-		return
-	}
-
 	hashedPassword, err := util.HashPassword(req.NewPassword)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
 		return
 	}
 
-	_, err = server.store.UpdateUserPassword(ctx, db.UpdateUserPasswordParams{
-		ID:       userId,
-		Password: hashedPassword,
+	_, err = server.store.ResetPasswordTx(ctx, db.ResetPasswordTxParams{
+		ResetToken:     token.HashToken(req.Token),
+		HashedPassword: hashedPassword,
 	})
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			ctx.JSON(http.StatusBadRequest, errorResponse(util.ErrInvalidToken))
+		case errors.Is(err, util.ErrExpiredToken):
+			ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		default:
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		}
 		return
 	}
 
 	ctx.JSON(http.StatusOK, gin.H{"status": "password reset successfully"})
 }
-
-func (server *Server) verifyToken(ctx *gin.Context, userID int32, token string) error {
-	row, err := server.store.GetPasswordResetByToken(ctx, token)
-	if err != nil {
-		return err
-	}
-
-	if row.UserID != userID {
-		return util.ErrInvalidToken
-	}
-
-	if time.Now().After(row.ExpiresAt) {
-		return util.ErrExpiredToken
-	}
-
-	return nil
-}
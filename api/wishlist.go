@@ -1,10 +1,13 @@
 package api
 
 import (
+	"database/sql"
+	"errors"
 	"strconv"
 	"time"
 
 	db "github.com/cihanalici/api/db/sqlc"
+	"github.com/cihanalici/api/token"
 	"github.com/gin-gonic/gin"
 )
 
@@ -99,16 +102,24 @@ func (server *Server) getWishlist(ctx *gin.Context) {
 
 	wishlist, err := server.store.GetWishlistItemById(ctx, req.ID)
 	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(404, errorResponse(err))
+			return
+		}
 		ctx.JSON(500, errorResponse(err))
 		return
 	}
 
+	if !authorizeOwner(ctx, wishlist.UserID, userManagers) {
+		return
+	}
+
 	ctx.JSON(200, WishlistNotation(wishlist))
 }
 
 // listWishlist godoc
 // @Summary List wishlist items
-// @Description List wishlist items
+// @Description List all wishlist items for admins, or the caller's own items
 // @ID list-wishlist-items
 // @Accept  json
 // @Produce  json
@@ -126,12 +137,22 @@ func (server *Server) listWishlist(ctx *gin.Context) {
 		return
 	}
 
-	arg := db.ListWishlistItemsParams{
-		Limit:  req.PageSize,
-		Offset: (req.PageID - 1) * req.PageSize,
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	var wishlists []db.Wishlist
+	var err error
+	if hasAccess(authPayload, userManagers, false) {
+		wishlists, err = server.store.ListWishlistItems(ctx, db.ListWishlistItemsParams{
+			Limit:  req.PageSize,
+			Offset: (req.PageID - 1) * req.PageSize,
+		})
+	} else {
+		wishlists, err = server.store.GetWishlistItemsByUserId(ctx, db.GetWishlistItemsByUserIdParams{
+			UserID: authPayload.UserID,
+			Limit:  req.PageSize,
+			Offset: (req.PageID - 1) * req.PageSize,
+		})
 	}
-
-	wishlists, err := server.store.ListWishlistItems(ctx, arg)
 	if err != nil {
 		ctx.JSON(500, errorResponse(err))
 		return
@@ -195,7 +216,21 @@ func (server *Server) deleteWishlist(ctx *gin.Context) {
 		return
 	}
 
-	err = server.store.DeleteWishlistItem(ctx, int32(wishlistId))
+	wishlist, err := server.store.GetWishlistItemById(ctx, int32(wishlistId))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(404, errorResponse(err))
+			return
+		}
+		ctx.JSON(500, errorResponse(err))
+		return
+	}
+
+	if !authorizeOwner(ctx, wishlist.UserID, userManagers) {
+		return
+	}
+
+	err = server.store.DeleteWishlistItem(ctx, wishlist.ID)
 	if err != nil {
 		ctx.JSON(500, errorResponse(err))
 		return
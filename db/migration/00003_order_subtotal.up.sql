@@ -0,0 +1,3 @@
+ALTER TABLE "orders" ADD COLUMN "subtotal_amount" DECIMAL(10,2) NOT NULL DEFAULT 0;
+
+UPDATE "orders" SET "subtotal_amount" = "total_amount";
@@ -0,0 +1,25 @@
+CREATE TABLE "carts" (
+  "id" SERIAL PRIMARY KEY,
+  "user_id" INT UNIQUE,
+  "token" VARCHAR(64) UNIQUE,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  "updated_at" timestamptz NOT NULL DEFAULT (now()),
+  CHECK ("user_id" IS NOT NULL OR "token" IS NOT NULL)
+);
+
+CREATE TABLE "cart_items" (
+  "id" SERIAL PRIMARY KEY,
+  "cart_id" INT NOT NULL,
+  "product_variant_id" INT NOT NULL,
+  "quantity" INT NOT NULL CHECK ("quantity" > 0),
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  "updated_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE UNIQUE INDEX ON "cart_items" ("cart_id", "product_variant_id");
+
+ALTER TABLE "carts" ADD FOREIGN KEY ("user_id") REFERENCES "users" ("id") ON DELETE CASCADE;
+
+ALTER TABLE "cart_items" ADD FOREIGN KEY ("cart_id") REFERENCES "carts" ("id") ON DELETE CASCADE;
+
+ALTER TABLE "cart_items" ADD FOREIGN KEY ("product_variant_id") REFERENCES "product_variants" ("id") ON DELETE CASCADE;
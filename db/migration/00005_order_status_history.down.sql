@@ -0,0 +1,2 @@
+ALTER TABLE "orders" DROP CONSTRAINT IF EXISTS "orders_status_check";
+DROP TABLE IF EXISTS order_status_history;
@@ -0,0 +1,21 @@
+CREATE TABLE "order_status_history" (
+  "id" SERIAL PRIMARY KEY,
+  "order_id" INT NOT NULL,
+  "from_status" VARCHAR(50),
+  "to_status" VARCHAR(50) NOT NULL,
+  "changed_by" INT,
+  "note" TEXT NOT NULL DEFAULT '',
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "order_status_history" ("order_id");
+
+ALTER TABLE "order_status_history" ADD FOREIGN KEY ("order_id") REFERENCES "orders" ("id") ON DELETE CASCADE;
+
+ALTER TABLE "order_status_history" ADD FOREIGN KEY ("changed_by") REFERENCES "users" ("id") ON DELETE SET NULL;
+
+ALTER TABLE "orders" ADD CONSTRAINT "orders_status_check"
+  CHECK ("status" IN ('pending', 'paid', 'fulfilled', 'shipped', 'delivered', 'cancelled', 'refunded')) NOT VALID;
+
+INSERT INTO "order_status_history" ("order_id", "to_status", "created_at")
+SELECT "id", "status", "created_at" FROM "orders";
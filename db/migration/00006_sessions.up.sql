@@ -0,0 +1,25 @@
+CREATE TABLE "sessions" (
+  "id" uuid PRIMARY KEY,
+  "user_id" INT NOT NULL,
+  "refresh_token" VARCHAR NOT NULL,
+  "user_agent" VARCHAR NOT NULL,
+  "client_ip" VARCHAR NOT NULL,
+  "is_blocked" boolean NOT NULL DEFAULT false,
+  "expires_at" timestamptz NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "sessions" ("user_id");
+
+ALTER TABLE "sessions" ADD FOREIGN KEY ("user_id") REFERENCES "users" ("id") ON DELETE CASCADE;
+
+CREATE TABLE "revoked_tokens" (
+  "id" uuid PRIMARY KEY,
+  "user_id" INT NOT NULL,
+  "expires_at" timestamptz NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "revoked_tokens" ("expires_at");
+
+ALTER TABLE "revoked_tokens" ADD FOREIGN KEY ("user_id") REFERENCES "users" ("id") ON DELETE CASCADE;
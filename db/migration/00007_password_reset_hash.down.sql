@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS password_resets_reset_token_idx;
+DROP INDEX IF EXISTS password_resets_user_id_idx;
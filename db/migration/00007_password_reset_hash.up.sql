@@ -0,0 +1,7 @@
+-- reset tokens are stored as SHA-256 hashes from now on, so tokens issued in
+-- plain text can never match again
+DELETE FROM "password_resets";
+
+CREATE UNIQUE INDEX ON "password_resets" ("reset_token");
+
+CREATE INDEX ON "password_resets" ("user_id");
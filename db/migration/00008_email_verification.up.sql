@@ -0,0 +1,18 @@
+ALTER TABLE "users" ADD COLUMN "email_verified_at" timestamptz;
+
+-- accounts created before verification existed are trusted as they are
+UPDATE "users" SET "email_verified_at" = "created_at";
+
+CREATE TABLE "email_verifications" (
+  "id" SERIAL PRIMARY KEY,
+  "user_id" INT NOT NULL,
+  "verification_token" VARCHAR(255) NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  "expires_at" timestamptz NOT NULL
+);
+
+CREATE UNIQUE INDEX ON "email_verifications" ("verification_token");
+
+CREATE INDEX ON "email_verifications" ("user_id");
+
+ALTER TABLE "email_verifications" ADD FOREIGN KEY ("user_id") REFERENCES "users" ("id") ON DELETE CASCADE;
@@ -0,0 +1,12 @@
+CREATE TABLE "login_failures" (
+  "id" SERIAL PRIMARY KEY,
+  "scope" VARCHAR(16) NOT NULL,
+  "subject" VARCHAR(255) NOT NULL,
+  "failed_attempts" INT NOT NULL DEFAULT 0,
+  "last_failed_at" timestamptz NOT NULL DEFAULT (now()),
+  "locked_until" timestamptz,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  CONSTRAINT "login_failures_scope_check" CHECK ("scope" IN ('account', 'ip'))
+);
+
+CREATE UNIQUE INDEX ON "login_failures" ("scope", "subject");
@@ -0,0 +1,22 @@
+CREATE TABLE "user_mfa" (
+  "user_id" INT PRIMARY KEY,
+  "secret" VARCHAR(64) NOT NULL,
+  "enabled_at" timestamptz,
+  "last_used_step" BIGINT NOT NULL DEFAULT 0,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "mfa_recovery_codes" (
+  "id" SERIAL PRIMARY KEY,
+  "user_id" INT NOT NULL,
+  "code_hash" VARCHAR(64) NOT NULL,
+  "used_at" timestamptz,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE UNIQUE INDEX ON "mfa_recovery_codes" ("user_id", "code_hash");
+
+ALTER TABLE "user_mfa" ADD FOREIGN KEY ("user_id") REFERENCES "users" ("id") ON DELETE CASCADE;
+
+-- recovery codes go away together with the enrollment they belong to
+ALTER TABLE "mfa_recovery_codes" ADD FOREIGN KEY ("user_id") REFERENCES "user_mfa" ("user_id") ON DELETE CASCADE;
@@ -0,0 +1,17 @@
+CREATE TABLE "api_keys" (
+  "id" SERIAL PRIMARY KEY,
+  "name" VARCHAR(255) NOT NULL,
+  "prefix" VARCHAR(32) NOT NULL,
+  "key_hash" VARCHAR(64) NOT NULL,
+  "scopes" TEXT[] NOT NULL DEFAULT '{}',
+  "created_by" INT NOT NULL,
+  "expires_at" timestamptz,
+  "last_used_at" timestamptz,
+  "revoked_at" timestamptz,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE UNIQUE INDEX ON "api_keys" ("prefix");
+
+-- keys act on behalf of the admin that created them and go away with them
+ALTER TABLE "api_keys" ADD FOREIGN KEY ("created_by") REFERENCES "users" ("id") ON DELETE CASCADE;
@@ -0,0 +1,19 @@
+ALTER TABLE "users" ADD COLUMN "addresses" JSONB NOT NULL DEFAULT '{}'::jsonb;
+
+UPDATE "users" u
+SET "addresses" = a."addresses"
+FROM (
+  SELECT "user_id", jsonb_agg(jsonb_build_object(
+    'full_name', "full_name",
+    'street', trim("line1" || ' ' || "line2"),
+    'city', "city",
+    'state', "state",
+    'zip', "postal_code",
+    'country', "country"
+  ) ORDER BY "is_default_shipping" DESC, "id") AS "addresses"
+  FROM "user_addresses"
+  GROUP BY "user_id"
+) a
+WHERE u."id" = a."user_id";
+
+DROP TABLE IF EXISTS user_addresses;
@@ -0,0 +1,67 @@
+CREATE TABLE "user_addresses" (
+  "id" SERIAL PRIMARY KEY,
+  "user_id" INT NOT NULL,
+  "label" VARCHAR(50) NOT NULL DEFAULT '',
+  "full_name" VARCHAR(255) NOT NULL,
+  "phone" VARCHAR(50) NOT NULL DEFAULT '',
+  "line1" VARCHAR(255) NOT NULL,
+  "line2" VARCHAR(255) NOT NULL DEFAULT '',
+  "city" VARCHAR(100) NOT NULL,
+  "state" VARCHAR(100) NOT NULL DEFAULT '',
+  "postal_code" VARCHAR(20) NOT NULL DEFAULT '',
+  "country" VARCHAR(2) NOT NULL,
+  "is_default_shipping" BOOLEAN NOT NULL DEFAULT false,
+  "is_default_billing" BOOLEAN NOT NULL DEFAULT false,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  "updated_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "user_addresses" ("user_id");
+
+-- at most one default shipping and one default billing address per user
+CREATE UNIQUE INDEX ON "user_addresses" ("user_id") WHERE "is_default_shipping";
+CREATE UNIQUE INDEX ON "user_addresses" ("user_id") WHERE "is_default_billing";
+
+ALTER TABLE "user_addresses" ADD FOREIGN KEY ("user_id") REFERENCES "users" ("id") ON DELETE CASCADE;
+
+-- users.addresses held either a single address object, an array of them or
+-- an object of them keyed by label. Entries without a street or a city are
+-- dropped, countries that are not ISO codes are left empty for the owner to fix.
+WITH "entries" AS (
+  SELECT u."id" AS "user_id", u."name", '' AS "label", a."value" AS "address", a."ordinality" AS "position"
+  FROM "users" u, jsonb_array_elements(u."addresses") WITH ORDINALITY a("value", "ordinality")
+  WHERE jsonb_typeof(u."addresses") = 'array'
+  UNION ALL
+  SELECT u."id", u."name", '', u."addresses", 1
+  FROM "users" u
+  WHERE jsonb_typeof(u."addresses") = 'object'
+    AND (u."addresses" ? 'street' OR u."addresses" ? 'city')
+  UNION ALL
+  SELECT u."id", u."name", a."key", a."value", a."ordinality"
+  FROM "users" u, jsonb_each(u."addresses") WITH ORDINALITY a("key", "value", "ordinality")
+  WHERE jsonb_typeof(u."addresses") = 'object'
+    AND NOT (u."addresses" ? 'street' OR u."addresses" ? 'city')
+    AND jsonb_typeof(a."value") = 'object'
+),
+"addresses" AS (
+  SELECT
+    "user_id",
+    left("label", 50) AS "label",
+    left(COALESCE(NULLIF("address"->>'full_name', ''), "name"), 255) AS "full_name",
+    left(COALESCE("address"->>'phone', ''), 50) AS "phone",
+    left(COALESCE(NULLIF("address"->>'street', ''), "address"->>'line1', ''), 255) AS "line1",
+    left(COALESCE("address"->>'line2', ''), 255) AS "line2",
+    left(COALESCE("address"->>'city', ''), 100) AS "city",
+    left(COALESCE("address"->>'state', ''), 100) AS "state",
+    left(COALESCE(NULLIF("address"->>'zip', ''), "address"->>'postal_code', ''), 20) AS "postal_code",
+    CASE WHEN upper(trim("address"->>'country')) ~ '^[A-Z]{2}$'
+      THEN upper(trim("address"->>'country')) ELSE '' END AS "country",
+    row_number() OVER (PARTITION BY "user_id" ORDER BY "position") AS "rank"
+  FROM "entries"
+)
+INSERT INTO "user_addresses" ("user_id", "label", "full_name", "phone", "line1", "line2", "city", "state", "postal_code", "country", "is_default_shipping", "is_default_billing")
+SELECT "user_id", "label", "full_name", "phone", "line1", "line2", "city", "state", "postal_code", "country", "rank" = 1, "rank" = 1
+FROM "addresses"
+WHERE "line1" <> '' OR "city" <> '';
+
+ALTER TABLE "users" DROP COLUMN "addresses";
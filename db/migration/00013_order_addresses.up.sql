@@ -0,0 +1,5 @@
+-- copies of the addresses at checkout time, later edits of the address book
+-- do not change them. Orders placed before this have empty snapshots.
+ALTER TABLE "orders"
+  ADD COLUMN "shipping_address" JSONB NOT NULL DEFAULT '{}'::jsonb,
+  ADD COLUMN "billing_address" JSONB NOT NULL DEFAULT '{}'::jsonb;
@@ -0,0 +1,13 @@
+ALTER TABLE "order_items" DROP CONSTRAINT "order_items_product_variant_id_fkey";
+
+ALTER TABLE "order_items" ADD FOREIGN KEY ("product_variant_id") REFERENCES "product_variants" ("id");
+
+ALTER TABLE "order_items"
+  DROP COLUMN IF EXISTS "sku",
+  DROP COLUMN IF EXISTS "size",
+  DROP COLUMN IF EXISTS "color",
+  DROP COLUMN IF EXISTS "product_name";
+
+ALTER TABLE "product_variants"
+  DROP COLUMN IF EXISTS "archived_at",
+  DROP COLUMN IF EXISTS "sku";
@@ -0,0 +1,35 @@
+ALTER TABLE "product_variants"
+  ADD COLUMN "sku" VARCHAR(64),
+  ADD COLUMN "archived_at" timestamptz;
+
+UPDATE "product_variants"
+SET "sku" = "product_id" || '-' || "color" || '-' || "size";
+
+ALTER TABLE "product_variants" ALTER COLUMN "sku" SET NOT NULL;
+
+CREATE UNIQUE INDEX ON "product_variants" ("sku");
+
+-- what was bought, as it was sold: later catalog edits must not rewrite
+-- order history. Existing items get the current catalog data, the best we have.
+ALTER TABLE "order_items"
+  ADD COLUMN "product_name" VARCHAR(255) NOT NULL DEFAULT '',
+  ADD COLUMN "color" VARCHAR(50) NOT NULL DEFAULT '',
+  ADD COLUMN "size" VARCHAR(10) NOT NULL DEFAULT '',
+  ADD COLUMN "sku" VARCHAR(64) NOT NULL DEFAULT '';
+
+UPDATE "order_items" oi
+SET "product_name" = p."name", "color" = pv."color", "size" = pv."size", "sku" = pv."sku"
+FROM "product_variants" pv
+JOIN "products" p ON p."id" = pv."product_id"
+WHERE pv."id" = oi."product_variant_id";
+
+ALTER TABLE "order_items"
+  ALTER COLUMN "product_name" DROP DEFAULT,
+  ALTER COLUMN "color" DROP DEFAULT,
+  ALTER COLUMN "size" DROP DEFAULT,
+  ALTER COLUMN "sku" DROP DEFAULT;
+
+-- variants that were ordered are archived instead of deleted
+ALTER TABLE "order_items" DROP CONSTRAINT "order_items_product_variant_id_fkey";
+
+ALTER TABLE "order_items" ADD FOREIGN KEY ("product_variant_id") REFERENCES "product_variants" ("id") ON DELETE RESTRICT;
@@ -0,0 +1,24 @@
+CREATE TABLE "payments" (
+  "id" SERIAL PRIMARY KEY,
+  "order_id" INT NOT NULL,
+  "provider" VARCHAR(50) NOT NULL,
+  "provider_payment_id" VARCHAR(255) NOT NULL,
+  "amount" DECIMAL(10,2) NOT NULL,
+  "captured_amount" DECIMAL(10,2) NOT NULL DEFAULT 0,
+  "currency" VARCHAR(3) NOT NULL,
+  "status" VARCHAR(20) NOT NULL,
+  "failure_reason" TEXT NOT NULL DEFAULT '',
+  "next_action_url" TEXT NOT NULL DEFAULT '',
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  "updated_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "payments" ("order_id");
+
+CREATE UNIQUE INDEX ON "payments" ("provider", "provider_payment_id") WHERE "provider_payment_id" <> '';
+
+-- at most one payment holds money for an order, declined and voided
+-- attempts are kept next to it
+CREATE UNIQUE INDEX "payments_order_id_live_key" ON "payments" ("order_id") WHERE "status" IN ('authorized', 'captured', 'refunded');
+
+ALTER TABLE "payments" ADD FOREIGN KEY ("order_id") REFERENCES "orders" ("id") ON DELETE CASCADE;
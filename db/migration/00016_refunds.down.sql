@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS "refund_items";
+DROP TABLE IF EXISTS "refunds";
+
+ALTER TABLE "payments" DROP COLUMN IF EXISTS "refunded_amount";
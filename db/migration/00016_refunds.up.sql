@@ -0,0 +1,40 @@
+ALTER TABLE "payments" ADD COLUMN "refunded_amount" DECIMAL(10,2) NOT NULL DEFAULT 0;
+
+CREATE TABLE "refunds" (
+  "id" SERIAL PRIMARY KEY,
+  "order_id" INT NOT NULL,
+  "payment_id" INT NOT NULL,
+  "amount" DECIMAL(10,2) NOT NULL,
+  "reason" TEXT NOT NULL DEFAULT '',
+  "restock" BOOLEAN NOT NULL DEFAULT false,
+  "status" VARCHAR(20) NOT NULL,
+  "failure_reason" TEXT NOT NULL DEFAULT '',
+  "created_by" INT,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  "updated_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "refund_items" (
+  "id" SERIAL PRIMARY KEY,
+  "refund_id" INT NOT NULL,
+  "order_item_id" INT NOT NULL,
+  "quantity" INT NOT NULL CHECK ("quantity" > 0),
+  "amount" DECIMAL(10,2) NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "refunds" ("order_id");
+
+CREATE INDEX ON "refund_items" ("refund_id");
+
+CREATE INDEX ON "refund_items" ("order_item_id");
+
+ALTER TABLE "refunds" ADD FOREIGN KEY ("order_id") REFERENCES "orders" ("id") ON DELETE CASCADE;
+
+ALTER TABLE "refunds" ADD FOREIGN KEY ("payment_id") REFERENCES "payments" ("id") ON DELETE CASCADE;
+
+ALTER TABLE "refunds" ADD FOREIGN KEY ("created_by") REFERENCES "users" ("id") ON DELETE SET NULL;
+
+ALTER TABLE "refund_items" ADD FOREIGN KEY ("refund_id") REFERENCES "refunds" ("id") ON DELETE CASCADE;
+
+ALTER TABLE "refund_items" ADD FOREIGN KEY ("order_item_id") REFERENCES "order_items" ("id") ON DELETE CASCADE;
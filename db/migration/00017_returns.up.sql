@@ -0,0 +1,39 @@
+CREATE TABLE "returns" (
+  "id" SERIAL PRIMARY KEY,
+  "order_id" INT NOT NULL,
+  "user_id" INT NOT NULL,
+  "status" VARCHAR(20) NOT NULL,
+  "reason" TEXT NOT NULL,
+  "resolution_note" TEXT NOT NULL DEFAULT '',
+  "refund_id" INT,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  "updated_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "return_items" (
+  "id" SERIAL PRIMARY KEY,
+  "return_id" INT NOT NULL,
+  "order_item_id" INT NOT NULL,
+  "quantity" INT NOT NULL CHECK ("quantity" > 0),
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "returns" ("order_id");
+
+CREATE INDEX ON "returns" ("user_id");
+
+CREATE INDEX ON "returns" ("status");
+
+CREATE INDEX ON "return_items" ("return_id");
+
+CREATE INDEX ON "return_items" ("order_item_id");
+
+ALTER TABLE "returns" ADD FOREIGN KEY ("order_id") REFERENCES "orders" ("id") ON DELETE CASCADE;
+
+ALTER TABLE "returns" ADD FOREIGN KEY ("user_id") REFERENCES "users" ("id") ON DELETE CASCADE;
+
+ALTER TABLE "returns" ADD FOREIGN KEY ("refund_id") REFERENCES "refunds" ("id") ON DELETE SET NULL;
+
+ALTER TABLE "return_items" ADD FOREIGN KEY ("return_id") REFERENCES "returns" ("id") ON DELETE CASCADE;
+
+ALTER TABLE "return_items" ADD FOREIGN KEY ("order_item_id") REFERENCES "order_items" ("id") ON DELETE CASCADE;
@@ -0,0 +1,62 @@
+CREATE TABLE "coupons" (
+  "id" SERIAL PRIMARY KEY,
+  "code" VARCHAR(50) UNIQUE NOT NULL,
+  "description" TEXT NOT NULL DEFAULT '',
+  "type" VARCHAR(20) NOT NULL CHECK ("type" IN ('percentage', 'fixed', 'free_shipping')),
+  "value" DECIMAL(10,2) NOT NULL DEFAULT 0,
+  "min_order_amount" DECIMAL(10,2) NOT NULL DEFAULT 0,
+  "starts_at" timestamptz,
+  "ends_at" timestamptz,
+  "usage_limit" INT,
+  "per_user_limit" INT,
+  "is_active" BOOLEAN NOT NULL DEFAULT true,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  "updated_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+-- a coupon without products and categories applies to every product
+CREATE TABLE "coupon_products" (
+  "coupon_id" INT NOT NULL,
+  "product_id" INT NOT NULL,
+  PRIMARY KEY ("coupon_id", "product_id")
+);
+
+CREATE TABLE "coupon_categories" (
+  "coupon_id" INT NOT NULL,
+  "category_id" INT NOT NULL,
+  PRIMARY KEY ("coupon_id", "category_id")
+);
+
+CREATE TABLE "coupon_redemptions" (
+  "id" SERIAL PRIMARY KEY,
+  "coupon_id" INT NOT NULL,
+  "user_id" INT NOT NULL,
+  "order_id" INT UNIQUE NOT NULL,
+  "discount_amount" DECIMAL(10,2) NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "coupon_redemptions" ("coupon_id", "user_id");
+
+ALTER TABLE "coupon_products" ADD FOREIGN KEY ("coupon_id") REFERENCES "coupons" ("id") ON DELETE CASCADE;
+
+ALTER TABLE "coupon_products" ADD FOREIGN KEY ("product_id") REFERENCES "products" ("id") ON DELETE CASCADE;
+
+ALTER TABLE "coupon_categories" ADD FOREIGN KEY ("coupon_id") REFERENCES "coupons" ("id") ON DELETE CASCADE;
+
+ALTER TABLE "coupon_categories" ADD FOREIGN KEY ("category_id") REFERENCES "categories" ("id") ON DELETE CASCADE;
+
+-- redeemed coupons are deactivated, not deleted
+ALTER TABLE "coupon_redemptions" ADD FOREIGN KEY ("coupon_id") REFERENCES "coupons" ("id") ON DELETE RESTRICT;
+
+ALTER TABLE "coupon_redemptions" ADD FOREIGN KEY ("user_id") REFERENCES "users" ("id") ON DELETE CASCADE;
+
+ALTER TABLE "coupon_redemptions" ADD FOREIGN KEY ("order_id") REFERENCES "orders" ("id") ON DELETE CASCADE;
+
+ALTER TABLE "orders"
+  ADD COLUMN "discount_amount" DECIMAL(10,2) NOT NULL DEFAULT 0,
+  ADD COLUMN "shipping_amount" DECIMAL(10,2) NOT NULL DEFAULT 0,
+  ADD COLUMN "coupon_code" VARCHAR(50) NOT NULL DEFAULT '';
+
+-- discount_amount is the part of the order discount taken off the whole line
+ALTER TABLE "order_items" ADD COLUMN "discount_amount" DECIMAL(10,2) NOT NULL DEFAULT 0;
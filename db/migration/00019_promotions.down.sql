@@ -0,0 +1,3 @@
+ALTER TABLE "order_items" DROP COLUMN IF EXISTS "discounts";
+
+DROP TABLE IF EXISTS "promotions";
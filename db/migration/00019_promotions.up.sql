@@ -0,0 +1,20 @@
+-- conditions holds the parameters of the type, see promotions.Conditions
+CREATE TABLE "promotions" (
+  "id" SERIAL PRIMARY KEY,
+  "name" VARCHAR(100) NOT NULL,
+  "description" TEXT NOT NULL DEFAULT '',
+  "type" VARCHAR(20) NOT NULL CHECK ("type" IN ('buy_x_get_y', 'tiered', 'bundle')),
+  "priority" INT NOT NULL DEFAULT 0,
+  "exclusive" BOOLEAN NOT NULL DEFAULT false,
+  "conditions" JSONB NOT NULL DEFAULT '{}'::jsonb,
+  "starts_at" timestamptz,
+  "ends_at" timestamptz,
+  "is_active" BOOLEAN NOT NULL DEFAULT true,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  "updated_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "promotions" ("is_active", "priority");
+
+-- discounts explains discount_amount, one entry per promotion or coupon
+ALTER TABLE "order_items" ADD COLUMN "discounts" JSONB NOT NULL DEFAULT '[]'::jsonb;
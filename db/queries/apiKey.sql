@@ -0,0 +1,27 @@
+-- name: CreateApiKey :one
+INSERT INTO api_keys (name, prefix, key_hash, scopes, created_by, expires_at)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING id, name, prefix, key_hash, scopes, created_by, expires_at, last_used_at, revoked_at, created_at;
+
+-- name: GetApiKeyByPrefix :one
+SELECT id, name, prefix, key_hash, scopes, created_by, expires_at, last_used_at, revoked_at, created_at
+FROM api_keys
+WHERE prefix = $1;
+
+-- name: ListApiKeys :many
+SELECT id, name, prefix, key_hash, scopes, created_by, expires_at, last_used_at, revoked_at, created_at
+FROM api_keys
+ORDER BY id DESC
+LIMIT $1
+OFFSET $2;
+
+-- name: RevokeApiKey :one
+UPDATE api_keys
+SET revoked_at = now()
+WHERE id = $1 AND revoked_at IS NULL
+RETURNING id, name, prefix, key_hash, scopes, created_by, expires_at, last_used_at, revoked_at, created_at;
+
+-- name: TouchApiKey :exec
+UPDATE api_keys
+SET last_used_at = now()
+WHERE id = $1 AND (last_used_at IS NULL OR last_used_at < now() - interval '1 minute');
@@ -0,0 +1,30 @@
+-- name: CreateCart :one
+INSERT INTO carts (user_id, token)
+VALUES ($1, $2)
+RETURNING id, user_id, token, created_at, updated_at;
+
+-- name: GetCartByUserId :one
+SELECT id, user_id, token, created_at, updated_at
+FROM carts
+WHERE user_id = $1;
+
+-- name: GetCartByToken :one
+SELECT id, user_id, token, created_at, updated_at
+FROM carts
+WHERE token = $1;
+
+-- name: GetCartForUpdate :one
+SELECT id, user_id, token, created_at, updated_at
+FROM carts
+WHERE id = $1
+FOR UPDATE;
+
+-- name: AssignCartToUser :one
+UPDATE carts
+SET user_id = $2, token = NULL, updated_at = CURRENT_TIMESTAMP
+WHERE id = $1
+RETURNING id, user_id, token, created_at, updated_at;
+
+-- name: DeleteCart :exec
+DELETE FROM carts
+WHERE id = $1;
@@ -0,0 +1,40 @@
+-- name: AddCartItem :one
+INSERT INTO cart_items (cart_id, product_variant_id, quantity)
+VALUES ($1, $2, $3)
+ON CONFLICT (cart_id, product_variant_id)
+DO UPDATE SET quantity = LEAST(cart_items.quantity + EXCLUDED.quantity, sqlc.arg(max_quantity)::int), updated_at = CURRENT_TIMESTAMP
+RETURNING id, cart_id, product_variant_id, quantity, created_at, updated_at;
+
+-- name: GetCartItemById :one
+SELECT id, cart_id, product_variant_id, quantity, created_at, updated_at
+FROM cart_items
+WHERE id = $1;
+
+-- name: UpdateCartItemQuantity :one
+UPDATE cart_items
+SET quantity = $2, updated_at = CURRENT_TIMESTAMP
+WHERE id = $1
+RETURNING id, cart_id, product_variant_id, quantity, created_at, updated_at;
+
+-- name: DeleteCartItem :exec
+DELETE FROM cart_items
+WHERE id = $1;
+
+-- name: ListCartItemsByCartId :many
+SELECT id, cart_id, product_variant_id, quantity, created_at, updated_at
+FROM cart_items
+WHERE cart_id = $1
+ORDER BY id;
+
+-- name: ListCartItemDetailsByCartId :many
+SELECT ci.id, ci.cart_id, ci.product_variant_id, ci.quantity, ci.created_at, ci.updated_at,
+       pv.product_id, pv.color, pv.size, pv.stock, pv.price, p.name AS product_name, p.category_id
+FROM cart_items ci
+JOIN product_variants pv ON pv.id = ci.product_variant_id
+JOIN products p ON p.id = pv.product_id
+WHERE ci.cart_id = $1
+ORDER BY ci.id;
+
+-- name: DeleteCartItemsByCartId :exec
+DELETE FROM cart_items
+WHERE cart_id = $1;
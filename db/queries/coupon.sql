@@ -0,0 +1,80 @@
+-- name: CreateCoupon :one
+INSERT INTO coupons (code, description, type, value, min_order_amount, starts_at, ends_at, usage_limit, per_user_limit, is_active)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+RETURNING id, code, description, type, value, min_order_amount, starts_at, ends_at, usage_limit, per_user_limit, is_active, created_at, updated_at;
+
+-- name: GetCouponById :one
+SELECT id, code, description, type, value, min_order_amount, starts_at, ends_at, usage_limit, per_user_limit, is_active, created_at, updated_at
+FROM coupons
+WHERE id = $1;
+
+-- name: GetCouponByCodeForUpdate :one
+SELECT id, code, description, type, value, min_order_amount, starts_at, ends_at, usage_limit, per_user_limit, is_active, created_at, updated_at
+FROM coupons
+WHERE code = $1
+FOR UPDATE;
+
+-- name: ListCoupons :many
+SELECT id, code, description, type, value, min_order_amount, starts_at, ends_at, usage_limit, per_user_limit, is_active, created_at, updated_at
+FROM coupons
+ORDER BY id
+LIMIT $1
+OFFSET $2;
+
+-- name: UpdateCoupon :one
+UPDATE coupons
+SET code = $2, description = $3, type = $4, value = $5, min_order_amount = $6, starts_at = $7, ends_at = $8,
+  usage_limit = $9, per_user_limit = $10, is_active = $11, updated_at = CURRENT_TIMESTAMP
+WHERE id = $1
+RETURNING id, code, description, type, value, min_order_amount, starts_at, ends_at, usage_limit, per_user_limit, is_active, created_at, updated_at;
+
+-- name: DeleteCoupon :exec
+DELETE FROM coupons
+WHERE id = $1;
+
+-- name: ListCouponProductIds :many
+SELECT product_id
+FROM coupon_products
+WHERE coupon_id = $1
+ORDER BY product_id;
+
+-- name: ListCouponCategoryIds :many
+SELECT category_id
+FROM coupon_categories
+WHERE coupon_id = $1
+ORDER BY category_id;
+
+-- name: AddCouponProduct :exec
+INSERT INTO coupon_products (coupon_id, product_id)
+VALUES ($1, $2)
+ON CONFLICT DO NOTHING;
+
+-- name: AddCouponCategory :exec
+INSERT INTO coupon_categories (coupon_id, category_id)
+VALUES ($1, $2)
+ON CONFLICT DO NOTHING;
+
+-- name: DeleteCouponProducts :exec
+DELETE FROM coupon_products
+WHERE coupon_id = $1;
+
+-- name: DeleteCouponCategories :exec
+DELETE FROM coupon_categories
+WHERE coupon_id = $1;
+
+-- name: CreateCouponRedemption :one
+INSERT INTO coupon_redemptions (coupon_id, user_id, order_id, discount_amount)
+VALUES ($1, $2, $3, $4)
+RETURNING id, coupon_id, user_id, order_id, discount_amount, created_at;
+
+-- name: CountCouponRedemptions :one
+SELECT COUNT(*)
+FROM coupon_redemptions cr
+JOIN orders o ON o.id = cr.order_id
+WHERE cr.coupon_id = $1 AND o.status <> 'cancelled';
+
+-- name: CountCouponRedemptionsByUser :one
+SELECT COUNT(*)
+FROM coupon_redemptions cr
+JOIN orders o ON o.id = cr.order_id
+WHERE cr.coupon_id = $1 AND cr.user_id = $2 AND o.status <> 'cancelled';
@@ -0,0 +1,18 @@
+-- name: CreateEmailVerification :one
+INSERT INTO email_verifications (user_id, verification_token, expires_at)
+VALUES ($1, $2, $3)
+RETURNING id, user_id, verification_token, created_at, expires_at;
+
+-- name: GetEmailVerificationByTokenForUpdate :one
+SELECT id, user_id, verification_token, created_at, expires_at
+FROM email_verifications
+WHERE verification_token = $1
+FOR UPDATE;
+
+-- name: DeleteEmailVerificationsByUserId :exec
+DELETE FROM email_verifications
+WHERE user_id = $1;
+
+-- name: DeleteExpiredEmailVerifications :exec
+DELETE FROM email_verifications
+WHERE expires_at < CURRENT_TIMESTAMP;
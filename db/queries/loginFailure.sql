@@ -0,0 +1,36 @@
+-- name: GetLoginFailure :one
+SELECT id, scope, subject, failed_attempts, last_failed_at, locked_until, created_at
+FROM login_failures
+WHERE scope = $1 AND subject = $2;
+
+-- name: RecordLoginFailure :one
+INSERT INTO login_failures (scope, subject, failed_attempts, last_failed_at)
+VALUES (sqlc.arg(scope), sqlc.arg(subject), 1, now())
+ON CONFLICT (scope, subject) DO UPDATE
+SET failed_attempts = CASE
+    WHEN login_failures.last_failed_at < sqlc.arg(window_start) THEN 1
+    ELSE login_failures.failed_attempts + 1
+  END,
+  last_failed_at = now()
+RETURNING id, scope, subject, failed_attempts, last_failed_at, locked_until, created_at;
+
+-- name: LockLoginFailure :one
+UPDATE login_failures
+SET locked_until = $2, failed_attempts = 0
+WHERE id = $1
+RETURNING id, scope, subject, failed_attempts, last_failed_at, locked_until, created_at;
+
+-- name: DeleteLoginFailure :exec
+DELETE FROM login_failures
+WHERE scope = $1 AND subject = $2;
+
+-- name: DeleteLoginFailureById :execrows
+DELETE FROM login_failures
+WHERE id = $1;
+
+-- name: ListLoginFailures :many
+SELECT id, scope, subject, failed_attempts, last_failed_at, locked_until, created_at
+FROM login_failures
+ORDER BY locked_until DESC NULLS LAST, last_failed_at DESC
+LIMIT $1
+OFFSET $2;
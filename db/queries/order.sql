@@ -1,32 +1,44 @@
 -- name: CreateOrder :one
-INSERT INTO orders (user_id, total_amount, status)
-VALUES ($1, $2, $3)
-RETURNING id, user_id, total_amount, status, created_at, updated_at;
+INSERT INTO orders (user_id, subtotal_amount, total_amount, status, shipping_address, billing_address, discount_amount, shipping_amount, coupon_code)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+RETURNING id, user_id, total_amount, status, created_at, updated_at, subtotal_amount, shipping_address, billing_address, discount_amount, shipping_amount, coupon_code;
 
 -- name: GetOrderById :one
-SELECT id, user_id, total_amount, status, created_at, updated_at
+SELECT id, user_id, total_amount, status, created_at, updated_at, subtotal_amount, shipping_address, billing_address, discount_amount, shipping_amount, coupon_code
 FROM orders
 WHERE id = $1;
 
 -- name: ListOrders :many
-SELECT id, user_id, total_amount, status, created_at, updated_at
+SELECT id, user_id, total_amount, status, created_at, updated_at, subtotal_amount, shipping_address, billing_address, discount_amount, shipping_amount, coupon_code
 FROM orders
 ORDER BY id
 LIMIT $1
 OFFSET $2;
 
--- name: UpdateOrder :one
+-- name: GetOrderForUpdate :one
+SELECT id, user_id, total_amount, status, created_at, updated_at, subtotal_amount, shipping_address, billing_address, discount_amount, shipping_amount, coupon_code
+FROM orders
+WHERE id = $1
+FOR UPDATE;
+
+-- name: UpdateOrderStatus :one
+UPDATE orders
+SET status = $2, updated_at = CURRENT_TIMESTAMP
+WHERE id = $1
+RETURNING id, user_id, total_amount, status, created_at, updated_at, subtotal_amount, shipping_address, billing_address, discount_amount, shipping_amount, coupon_code;
+
+-- name: UpdateOrderAddresses :one
 UPDATE orders
-SET user_id = $2, total_amount = $3, status = $4, updated_at = CURRENT_TIMESTAMP
+SET shipping_address = $2, billing_address = $3, updated_at = CURRENT_TIMESTAMP
 WHERE id = $1
-RETURNING id, user_id, total_amount, status, created_at, updated_at;
+RETURNING id, user_id, total_amount, status, created_at, updated_at, subtotal_amount, shipping_address, billing_address, discount_amount, shipping_amount, coupon_code;
 
 -- name: DeleteOrder :exec
 DELETE FROM orders
 WHERE id = $1;
 
 -- name: GetOrdersByUserId :many
-SELECT id, user_id, total_amount, status, created_at, updated_at
+SELECT id, user_id, total_amount, status, created_at, updated_at, subtotal_amount, shipping_address, billing_address, discount_amount, shipping_amount, coupon_code
 FROM orders
 WHERE user_id = $1
 ORDER BY id
@@ -37,5 +49,6 @@ OFFSET $3;
 SELECT EXTRACT(MONTH FROM created_at) AS month, SUM(total_amount) AS total_sales
 FROM orders
 WHERE EXTRACT(YEAR FROM created_at) = $1
+  AND status NOT IN ('cancelled', 'refunded')
 GROUP BY month
 ORDER BY month;
\ No newline at end of file
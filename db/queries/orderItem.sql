@@ -1,15 +1,15 @@
 -- name: CreateOrderItem :one
-INSERT INTO order_items (order_id, product_variant_id, quantity, price)
-VALUES ($1, $2, $3, $4)
-RETURNING id, order_id, product_variant_id, quantity, price, created_at, updated_at;
+INSERT INTO order_items (order_id, product_variant_id, quantity, price, product_name, color, size, sku, discount_amount, discounts)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+RETURNING id, order_id, product_variant_id, quantity, price, created_at, updated_at, product_name, color, size, sku, discount_amount, discounts;
 
 -- name: GetOrderItemById :one
-SELECT id, order_id, product_variant_id, quantity, price, created_at, updated_at
+SELECT id, order_id, product_variant_id, quantity, price, created_at, updated_at, product_name, color, size, sku, discount_amount, discounts
 FROM order_items
 WHERE id = $1;
 
 -- name: ListOrderItems :many
-SELECT id, order_id, product_variant_id, quantity, price, created_at, updated_at
+SELECT id, order_id, product_variant_id, quantity, price, created_at, updated_at, product_name, color, size, sku, discount_amount, discounts
 FROM order_items
 ORDER BY id
 LIMIT $1
@@ -19,16 +19,37 @@ OFFSET $2;
 UPDATE order_items
 SET order_id = $2, product_variant_id = $3, quantity = $4, price = $5, updated_at = CURRENT_TIMESTAMP
 WHERE id = $1
-RETURNING id, order_id, product_variant_id, quantity, price, created_at, updated_at;
+RETURNING id, order_id, product_variant_id, quantity, price, created_at, updated_at, product_name, color, size, sku, discount_amount, discounts;
 
 -- name: DeleteOrderItem :exec
 DELETE FROM order_items
 WHERE id = $1;
 
 -- name: GetOrderItemsByOrderId :many
-SELECT id, order_id, product_variant_id, quantity, price, created_at, updated_at
+SELECT id, order_id, product_variant_id, quantity, price, created_at, updated_at, product_name, color, size, sku, discount_amount, discounts
 FROM order_items
 WHERE order_id = $1
 ORDER BY id
 LIMIT $2
 OFFSET $3;
+
+-- name: GetAllOrderItemsByOrderId :many
+SELECT id, order_id, product_variant_id, quantity, price, created_at, updated_at, product_name, color, size, sku, discount_amount, discounts
+FROM order_items
+WHERE order_id = $1
+ORDER BY id;
+
+-- name: ListOrderItemDetailsByOrderId :many
+SELECT oi.id, oi.order_id, oi.product_variant_id, oi.quantity, oi.price, oi.created_at, oi.updated_at,
+       oi.product_name, oi.color, oi.size, oi.sku, oi.discount_amount, oi.discounts,
+       pv.product_id, pv.color AS variant_color, pv.size AS variant_size, pv.stock AS variant_stock,
+       pv.price AS variant_price, pv.sku AS variant_sku, pv.archived_at AS variant_archived_at,
+       pv.created_at AS variant_created_at, pv.updated_at AS variant_updated_at,
+       p.name AS current_product_name, p.description AS product_description, p.price AS product_price,
+       p.stock AS product_stock, p.category_id AS product_category_id,
+       p.created_at AS product_created_at, p.updated_at AS product_updated_at
+FROM order_items oi
+JOIN product_variants pv ON pv.id = oi.product_variant_id
+JOIN products p ON p.id = pv.product_id
+WHERE oi.order_id = $1
+ORDER BY oi.id;
@@ -0,0 +1,10 @@
+-- name: CreateOrderStatusHistory :one
+INSERT INTO order_status_history (order_id, from_status, to_status, changed_by, note)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING id, order_id, from_status, to_status, changed_by, note, created_at;
+
+-- name: ListOrderStatusHistory :many
+SELECT id, order_id, from_status, to_status, changed_by, note, created_at
+FROM order_status_history
+WHERE order_id = $1
+ORDER BY created_at, id;
@@ -30,3 +30,13 @@ WHERE user_id = $1 AND reset_token = $2;
 SELECT id, user_id, reset_token, created_at, expires_at
 FROM password_resets
 WHERE id = $1;
+
+-- name: GetPasswordResetByTokenForUpdate :one
+SELECT id, user_id, reset_token, created_at, expires_at
+FROM password_resets
+WHERE reset_token = $1
+FOR UPDATE;
+
+-- name: DeletePasswordResetsByUserId :exec
+DELETE FROM password_resets
+WHERE user_id = $1;
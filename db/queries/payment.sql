@@ -0,0 +1,41 @@
+-- name: CreatePayment :one
+INSERT INTO payments (order_id, provider, provider_payment_id, amount, currency, status, failure_reason, next_action_url)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+RETURNING id, order_id, provider, provider_payment_id, amount, captured_amount, currency, status, failure_reason, next_action_url, created_at, updated_at, refunded_amount;
+
+-- name: GetPayment :one
+SELECT id, order_id, provider, provider_payment_id, amount, captured_amount, currency, status, failure_reason, next_action_url, created_at, updated_at, refunded_amount
+FROM payments
+WHERE id = $1;
+
+-- name: ListPaymentsByOrderId :many
+SELECT id, order_id, provider, provider_payment_id, amount, captured_amount, currency, status, failure_reason, next_action_url, created_at, updated_at, refunded_amount
+FROM payments
+WHERE order_id = $1
+ORDER BY id;
+
+-- name: UpdatePaymentStatus :one
+UPDATE payments
+SET status = $2, failure_reason = $3, updated_at = CURRENT_TIMESTAMP
+WHERE id = $1
+RETURNING id, order_id, provider, provider_payment_id, amount, captured_amount, currency, status, failure_reason, next_action_url, created_at, updated_at, refunded_amount;
+
+-- name: MarkPaymentCaptured :one
+UPDATE payments
+SET status = 'captured', captured_amount = $2, updated_at = CURRENT_TIMESTAMP
+WHERE id = $1 AND status = 'authorized'
+RETURNING id, order_id, provider, provider_payment_id, amount, captured_amount, currency, status, failure_reason, next_action_url, created_at, updated_at, refunded_amount;
+
+-- name: GetRefundablePaymentForUpdate :one
+SELECT id, order_id, provider, provider_payment_id, amount, captured_amount, currency, status, failure_reason, next_action_url, created_at, updated_at, refunded_amount
+FROM payments
+WHERE order_id = $1 AND status IN ('captured', 'refunded')
+FOR UPDATE;
+
+-- name: AddPaymentRefundedAmount :one
+UPDATE payments
+SET refunded_amount = refunded_amount + sqlc.arg(amount),
+  status = CASE WHEN refunded_amount + sqlc.arg(amount) >= captured_amount THEN 'refunded' ELSE 'captured' END,
+  updated_at = CURRENT_TIMESTAMP
+WHERE id = sqlc.arg(id)
+RETURNING id, order_id, provider, provider_payment_id, amount, captured_amount, currency, status, failure_reason, next_action_url, created_at, updated_at, refunded_amount;
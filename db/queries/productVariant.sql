@@ -1,26 +1,50 @@
 -- name: CreateProductVariant :one
-INSERT INTO product_variants (product_id, color, size, stock, price)
-VALUES ($1, $2, $3, $4, $5)
-RETURNING id, product_id, color, size, stock, price, created_at, updated_at;
+INSERT INTO product_variants (product_id, color, size, stock, price, sku)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING id, product_id, color, size, stock, price, created_at, updated_at, sku, archived_at;
 
 -- name: GetProductVariantById :one
-SELECT id, product_id, color, size, stock, price, created_at, updated_at
+SELECT id, product_id, color, size, stock, price, created_at, updated_at, sku, archived_at
 FROM product_variants
 WHERE id = $1;
 
 -- name: ListProductVariants :many
-SELECT id, product_id, color, size, stock, price, created_at, updated_at
+SELECT id, product_id, color, size, stock, price, created_at, updated_at, sku, archived_at
 FROM product_variants
+WHERE archived_at IS NULL
 ORDER BY id
 LIMIT $1
 OFFSET $2;
 
 -- name: UpdateProductVariant :one
 UPDATE product_variants
-SET product_id = $2, color = $3, size = $4, stock = $5, price = $6, updated_at = CURRENT_TIMESTAMP
+SET product_id = $2, color = $3, size = $4, stock = $5, price = $6, sku = $7, updated_at = CURRENT_TIMESTAMP
 WHERE id = $1
-RETURNING id, product_id, color, size, stock, price, created_at, updated_at;
+RETURNING id, product_id, color, size, stock, price, created_at, updated_at, sku, archived_at;
 
 -- name: DeleteProductVariant :exec
 DELETE FROM product_variants
-WHERE id = $1;
\ No newline at end of file
+WHERE id = $1;
+
+-- name: GetProductVariantForUpdate :one
+SELECT id, product_id, color, size, stock, price, created_at, updated_at, sku, archived_at
+FROM product_variants
+WHERE id = $1
+FOR UPDATE;
+
+-- name: AddProductVariantStock :one
+UPDATE product_variants
+SET stock = stock + sqlc.arg(amount), updated_at = CURRENT_TIMESTAMP
+WHERE id = sqlc.arg(id)
+RETURNING id, product_id, color, size, stock, price, created_at, updated_at, sku, archived_at;
+
+-- name: ProductVariantHasOrders :one
+SELECT EXISTS (
+  SELECT 1 FROM order_items WHERE product_variant_id = $1
+);
+
+-- name: ArchiveProductVariant :one
+UPDATE product_variants
+SET archived_at = COALESCE(archived_at, CURRENT_TIMESTAMP), updated_at = CURRENT_TIMESTAMP
+WHERE id = $1
+RETURNING id, product_id, color, size, stock, price, created_at, updated_at, sku, archived_at;
@@ -0,0 +1,35 @@
+-- name: CreatePromotion :one
+INSERT INTO promotions (name, description, type, priority, exclusive, conditions, starts_at, ends_at, is_active)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+RETURNING id, name, description, type, priority, exclusive, conditions, starts_at, ends_at, is_active, created_at, updated_at;
+
+-- name: GetPromotionById :one
+SELECT id, name, description, type, priority, exclusive, conditions, starts_at, ends_at, is_active, created_at, updated_at
+FROM promotions
+WHERE id = $1;
+
+-- name: ListPromotions :many
+SELECT id, name, description, type, priority, exclusive, conditions, starts_at, ends_at, is_active, created_at, updated_at
+FROM promotions
+ORDER BY id
+LIMIT $1
+OFFSET $2;
+
+-- name: ListActivePromotions :many
+SELECT id, name, description, type, priority, exclusive, conditions, starts_at, ends_at, is_active, created_at, updated_at
+FROM promotions
+WHERE is_active
+  AND (starts_at IS NULL OR starts_at <= now())
+  AND (ends_at IS NULL OR ends_at > now())
+ORDER BY priority DESC, id;
+
+-- name: UpdatePromotion :one
+UPDATE promotions
+SET name = $2, description = $3, type = $4, priority = $5, exclusive = $6, conditions = $7, starts_at = $8, ends_at = $9,
+  is_active = $10, updated_at = CURRENT_TIMESTAMP
+WHERE id = $1
+RETURNING id, name, description, type, priority, exclusive, conditions, starts_at, ends_at, is_active, created_at, updated_at;
+
+-- name: DeletePromotion :exec
+DELETE FROM promotions
+WHERE id = $1;
@@ -0,0 +1,46 @@
+-- name: CreateRefund :one
+INSERT INTO refunds (order_id, payment_id, amount, reason, restock, status, created_by)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING id, order_id, payment_id, amount, reason, restock, status, failure_reason, created_by, created_at, updated_at;
+
+-- name: GetRefund :one
+SELECT id, order_id, payment_id, amount, reason, restock, status, failure_reason, created_by, created_at, updated_at
+FROM refunds
+WHERE id = $1;
+
+-- name: GetRefundForUpdate :one
+SELECT id, order_id, payment_id, amount, reason, restock, status, failure_reason, created_by, created_at, updated_at
+FROM refunds
+WHERE id = $1
+FOR UPDATE;
+
+-- name: ListRefundsByOrderId :many
+SELECT id, order_id, payment_id, amount, reason, restock, status, failure_reason, created_by, created_at, updated_at
+FROM refunds
+WHERE order_id = $1
+ORDER BY id;
+
+-- name: UpdateRefundStatus :one
+UPDATE refunds
+SET status = $2, failure_reason = $3, updated_at = CURRENT_TIMESTAMP
+WHERE id = $1
+RETURNING id, order_id, payment_id, amount, reason, restock, status, failure_reason, created_by, created_at, updated_at;
+
+-- name: CreateRefundItem :one
+INSERT INTO refund_items (refund_id, order_item_id, quantity, amount)
+VALUES ($1, $2, $3, $4)
+RETURNING id, refund_id, order_item_id, quantity, amount, created_at;
+
+-- name: ListRefundItemsByOrderId :many
+SELECT ri.id, ri.refund_id, ri.order_item_id, ri.quantity, ri.amount, ri.created_at
+FROM refund_items ri
+JOIN refunds r ON r.id = ri.refund_id
+WHERE r.order_id = $1
+ORDER BY ri.id;
+
+-- name: ListRefundedQuantitiesByOrderId :many
+SELECT ri.order_item_id, SUM(ri.quantity)::int AS quantity
+FROM refund_items ri
+JOIN refunds r ON r.id = ri.refund_id
+WHERE r.order_id = $1 AND r.status <> 'failed'
+GROUP BY ri.order_item_id;
@@ -0,0 +1,80 @@
+-- name: CreateReturn :one
+INSERT INTO returns (order_id, user_id, status, reason)
+VALUES ($1, $2, $3, $4)
+RETURNING id, order_id, user_id, status, reason, resolution_note, refund_id, created_at, updated_at;
+
+-- name: GetReturn :one
+SELECT id, order_id, user_id, status, reason, resolution_note, refund_id, created_at, updated_at
+FROM returns
+WHERE id = $1;
+
+-- name: GetReturnForUpdate :one
+SELECT id, order_id, user_id, status, reason, resolution_note, refund_id, created_at, updated_at
+FROM returns
+WHERE id = $1
+FOR UPDATE;
+
+-- name: ListReturns :many
+SELECT id, order_id, user_id, status, reason, resolution_note, refund_id, created_at, updated_at
+FROM returns
+ORDER BY id
+LIMIT $1
+OFFSET $2;
+
+-- name: ListReturnsByStatus :many
+SELECT id, order_id, user_id, status, reason, resolution_note, refund_id, created_at, updated_at
+FROM returns
+WHERE status = $1
+ORDER BY id
+LIMIT $2
+OFFSET $3;
+
+-- name: ListReturnsByUserId :many
+SELECT id, order_id, user_id, status, reason, resolution_note, refund_id, created_at, updated_at
+FROM returns
+WHERE user_id = $1
+ORDER BY id DESC
+LIMIT $2
+OFFSET $3;
+
+-- name: ListReturnsByOrderId :many
+SELECT id, order_id, user_id, status, reason, resolution_note, refund_id, created_at, updated_at
+FROM returns
+WHERE order_id = $1
+ORDER BY id;
+
+-- name: UpdateReturnStatus :one
+UPDATE returns
+SET status = $2, resolution_note = $3, updated_at = CURRENT_TIMESTAMP
+WHERE id = $1
+RETURNING id, order_id, user_id, status, reason, resolution_note, refund_id, created_at, updated_at;
+
+-- name: MarkReturnRefunded :one
+UPDATE returns
+SET status = 'refunded', refund_id = $2, updated_at = CURRENT_TIMESTAMP
+WHERE id = $1 AND status = 'received'
+RETURNING id, order_id, user_id, status, reason, resolution_note, refund_id, created_at, updated_at;
+
+-- name: LinkReturnRefund :one
+UPDATE returns
+SET refund_id = $2, updated_at = CURRENT_TIMESTAMP
+WHERE id = $1 AND status = 'received'
+RETURNING id, order_id, user_id, status, reason, resolution_note, refund_id, created_at, updated_at;
+
+-- name: CreateReturnItem :one
+INSERT INTO return_items (return_id, order_item_id, quantity)
+VALUES ($1, $2, $3)
+RETURNING id, return_id, order_item_id, quantity, created_at;
+
+-- name: ListReturnItemsByReturnId :many
+SELECT id, return_id, order_item_id, quantity, created_at
+FROM return_items
+WHERE return_id = $1
+ORDER BY id;
+
+-- name: ListOpenReturnedQuantitiesByOrderId :many
+SELECT ri.order_item_id, SUM(ri.quantity)::int AS quantity
+FROM return_items ri
+JOIN returns r ON r.id = ri.return_id
+WHERE r.order_id = $1 AND r.status IN ('requested', 'approved', 'received')
+GROUP BY ri.order_item_id;
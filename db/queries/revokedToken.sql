@@ -0,0 +1,15 @@
+-- name: CreateRevokedToken :exec
+INSERT INTO revoked_tokens (id, user_id, expires_at)
+VALUES ($1, $2, $3)
+ON CONFLICT (id) DO NOTHING;
+
+-- name: IsTokenRevoked :one
+SELECT EXISTS (
+  SELECT 1 FROM revoked_tokens WHERE id = sqlc.arg(token_id)
+  UNION ALL
+  SELECT 1 FROM sessions WHERE id = sqlc.arg(session_id) AND is_blocked
+) AS revoked;
+
+-- name: DeleteExpiredRevokedTokens :exec
+DELETE FROM revoked_tokens
+WHERE expires_at < now();
@@ -1,20 +1,20 @@
 -- name: CreateUser :one
-INSERT INTO users (name, email, password, role, addresses)
-VALUES ($1, $2, $3, $4, $5)
-RETURNING id, name, email, password, role, addresses, created_at, updated_at;
+INSERT INTO users (name, email, password, role)
+VALUES ($1, $2, $3, $4)
+RETURNING id, name, email, password, role, created_at, updated_at, email_verified_at;
 
 -- name: GetUserById :one
-SELECT id, name, email, password, role, addresses, created_at, updated_at
+SELECT id, name, email, password, role, created_at, updated_at, email_verified_at
 FROM users
 WHERE id = $1;
 
 -- name: GetUserByEmail :one
-SELECT id, name, email, password, role, addresses, created_at, updated_at
+SELECT id, name, email, password, role, created_at, updated_at, email_verified_at
 FROM users
 WHERE email = $1;
 
 -- name: ListUsers :many
-SELECT id, name, email, role, password, addresses, created_at, updated_at
+SELECT id, name, email, role, password, created_at, updated_at, email_verified_at
 FROM users
 ORDER BY id
 LIMIT $1
@@ -22,9 +22,10 @@ OFFSET $2;
 
 -- name: UpdateUser :one
 UPDATE users
-SET name = $1, email = $2, role = $3, addresses = $4, updated_at = CURRENT_TIMESTAMP
-WHERE id = $5
-RETURNING id, name, email, role, password, addresses, created_at, updated_at;
+SET name = $1, email = $2, updated_at = CURRENT_TIMESTAMP,
+  email_verified_at = CASE WHEN email = $2 THEN email_verified_at END
+WHERE id = $3
+RETURNING id, name, email, role, password, created_at, updated_at, email_verified_at;
 
 -- name: DeleteUser :exec
 DELETE FROM users
@@ -34,4 +35,16 @@ WHERE id = $1;
 UPDATE users
 SET password = $1, updated_at = CURRENT_TIMESTAMP
 WHERE id = $2
-RETURNING id, name, email, role, password, addresses, created_at, updated_at;
\ No newline at end of file
+RETURNING id, name, email, role, password, created_at, updated_at, email_verified_at;
+
+-- name: UpdateUserRole :one
+UPDATE users
+SET role = $1, updated_at = CURRENT_TIMESTAMP
+WHERE id = $2
+RETURNING id, name, email, role, password, created_at, updated_at, email_verified_at;
+
+-- name: MarkUserEmailVerified :one
+UPDATE users
+SET email_verified_at = COALESCE(email_verified_at, CURRENT_TIMESTAMP), updated_at = CURRENT_TIMESTAMP
+WHERE id = $1
+RETURNING id, name, email, password, role, created_at, updated_at, email_verified_at;
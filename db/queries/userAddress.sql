@@ -0,0 +1,44 @@
+-- name: CreateUserAddress :one
+INSERT INTO user_addresses (
+  user_id, label, full_name, phone, line1, line2, city, state, postal_code, country, is_default_shipping, is_default_billing
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12
+)
+RETURNING id, user_id, label, full_name, phone, line1, line2, city, state, postal_code, country, is_default_shipping, is_default_billing, created_at, updated_at;
+
+-- name: GetUserAddress :one
+SELECT id, user_id, label, full_name, phone, line1, line2, city, state, postal_code, country, is_default_shipping, is_default_billing, created_at, updated_at
+FROM user_addresses
+WHERE id = $1 AND user_id = $2;
+
+-- name: ListUserAddresses :many
+SELECT id, user_id, label, full_name, phone, line1, line2, city, state, postal_code, country, is_default_shipping, is_default_billing, created_at, updated_at
+FROM user_addresses
+WHERE user_id = $1
+ORDER BY is_default_shipping DESC, is_default_billing DESC, id;
+
+-- name: CountUserAddresses :one
+SELECT COUNT(*) FROM user_addresses
+WHERE user_id = $1;
+
+-- name: UpdateUserAddress :one
+UPDATE user_addresses
+SET label = $3, full_name = $4, phone = $5, line1 = $6, line2 = $7, city = $8, state = $9,
+  postal_code = $10, country = $11, is_default_shipping = $12, is_default_billing = $13,
+  updated_at = CURRENT_TIMESTAMP
+WHERE id = $1 AND user_id = $2
+RETURNING id, user_id, label, full_name, phone, line1, line2, city, state, postal_code, country, is_default_shipping, is_default_billing, created_at, updated_at;
+
+-- name: DeleteUserAddress :execrows
+DELETE FROM user_addresses
+WHERE id = $1 AND user_id = $2;
+
+-- name: ClearDefaultShippingAddress :exec
+UPDATE user_addresses
+SET is_default_shipping = false, updated_at = CURRENT_TIMESTAMP
+WHERE user_id = $1 AND is_default_shipping;
+
+-- name: ClearDefaultBillingAddress :exec
+UPDATE user_addresses
+SET is_default_billing = false, updated_at = CURRENT_TIMESTAMP
+WHERE user_id = $1 AND is_default_billing;
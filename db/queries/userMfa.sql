@@ -0,0 +1,44 @@
+-- name: UpsertUserMfa :one
+INSERT INTO user_mfa (user_id, secret)
+VALUES ($1, $2)
+ON CONFLICT (user_id) DO UPDATE
+SET secret = EXCLUDED.secret, enabled_at = NULL, last_used_step = 0, created_at = now()
+WHERE user_mfa.enabled_at IS NULL
+RETURNING user_id, secret, enabled_at, last_used_step, created_at;
+
+-- name: GetUserMfa :one
+SELECT user_id, secret, enabled_at, last_used_step, created_at
+FROM user_mfa
+WHERE user_id = $1;
+
+-- name: EnableUserMfa :one
+UPDATE user_mfa
+SET enabled_at = now(), last_used_step = $2
+WHERE user_id = $1
+RETURNING user_id, secret, enabled_at, last_used_step, created_at;
+
+-- name: UseUserMfaStep :execrows
+UPDATE user_mfa
+SET last_used_step = $2
+WHERE user_id = $1 AND last_used_step < $2;
+
+-- name: DeleteUserMfa :execrows
+DELETE FROM user_mfa
+WHERE user_id = $1;
+
+-- name: CreateMfaRecoveryCode :exec
+INSERT INTO mfa_recovery_codes (user_id, code_hash)
+VALUES ($1, $2);
+
+-- name: UseMfaRecoveryCode :execrows
+UPDATE mfa_recovery_codes
+SET used_at = now()
+WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL;
+
+-- name: CountUnusedMfaRecoveryCodes :one
+SELECT COUNT(*) FROM mfa_recovery_codes
+WHERE user_id = $1 AND used_at IS NULL;
+
+-- name: DeleteMfaRecoveryCodes :exec
+DELETE FROM mfa_recovery_codes
+WHERE user_id = $1;
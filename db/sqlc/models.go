@@ -8,8 +8,40 @@ import (
 	"database/sql"
 	"encoding/json"
 	"time"
+
+	"github.com/google/uuid"
 )
 
+type ApiKey struct {
+	ID         int32        `json:"id"`
+	Name       string       `json:"name"`
+	Prefix     string       `json:"prefix"`
+	KeyHash    string       `json:"key_hash"`
+	Scopes     []string     `json:"scopes"`
+	CreatedBy  int32        `json:"created_by"`
+	ExpiresAt  sql.NullTime `json:"expires_at"`
+	LastUsedAt sql.NullTime `json:"last_used_at"`
+	RevokedAt  sql.NullTime `json:"revoked_at"`
+	CreatedAt  time.Time    `json:"created_at"`
+}
+
+type Cart struct {
+	ID        int32          `json:"id"`
+	UserID    sql.NullInt32  `json:"user_id"`
+	Token     sql.NullString `json:"token"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+}
+
+type CartItem struct {
+	ID               int32     `json:"id"`
+	CartID           int32     `json:"cart_id"`
+	ProductVariantID int32     `json:"product_variant_id"`
+	Quantity         int32     `json:"quantity"`
+	CreatedAt        time.Time `json:"created_at"`
+	UpdatedAt        time.Time `json:"updated_at"`
+}
+
 type Category struct {
 	ID          int32     `json:"id"`
 	Name        string    `json:"name"`
@@ -18,23 +50,106 @@ type Category struct {
 	UpdatedAt   time.Time `json:"updated_at"`
 }
 
+type Coupon struct {
+	ID             int32         `json:"id"`
+	Code           string        `json:"code"`
+	Description    string        `json:"description"`
+	Type           string        `json:"type"`
+	Value          string        `json:"value"`
+	MinOrderAmount string        `json:"min_order_amount"`
+	StartsAt       sql.NullTime  `json:"starts_at"`
+	EndsAt         sql.NullTime  `json:"ends_at"`
+	UsageLimit     sql.NullInt32 `json:"usage_limit"`
+	PerUserLimit   sql.NullInt32 `json:"per_user_limit"`
+	IsActive       bool          `json:"is_active"`
+	CreatedAt      time.Time     `json:"created_at"`
+	UpdatedAt      time.Time     `json:"updated_at"`
+}
+
+type CouponCategory struct {
+	CouponID   int32 `json:"coupon_id"`
+	CategoryID int32 `json:"category_id"`
+}
+
+type CouponProduct struct {
+	CouponID  int32 `json:"coupon_id"`
+	ProductID int32 `json:"product_id"`
+}
+
+type CouponRedemption struct {
+	ID             int32     `json:"id"`
+	CouponID       int32     `json:"coupon_id"`
+	UserID         int32     `json:"user_id"`
+	OrderID        int32     `json:"order_id"`
+	DiscountAmount string    `json:"discount_amount"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+type EmailVerification struct {
+	ID                int32     `json:"id"`
+	UserID            int32     `json:"user_id"`
+	VerificationToken string    `json:"verification_token"`
+	CreatedAt         time.Time `json:"created_at"`
+	ExpiresAt         time.Time `json:"expires_at"`
+}
+
+type LoginFailure struct {
+	ID             int32        `json:"id"`
+	Scope          string       `json:"scope"`
+	Subject        string       `json:"subject"`
+	FailedAttempts int32        `json:"failed_attempts"`
+	LastFailedAt   time.Time    `json:"last_failed_at"`
+	LockedUntil    sql.NullTime `json:"locked_until"`
+	CreatedAt      time.Time    `json:"created_at"`
+}
+
+type MfaRecoveryCode struct {
+	ID        int32        `json:"id"`
+	UserID    int32        `json:"user_id"`
+	CodeHash  string       `json:"code_hash"`
+	UsedAt    sql.NullTime `json:"used_at"`
+	CreatedAt time.Time    `json:"created_at"`
+}
+
 type Order struct {
-	ID          int32     `json:"id"`
-	UserID      int32     `json:"user_id"`
-	TotalAmount string    `json:"total_amount"`
-	Status      string    `json:"status"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	ID              int32           `json:"id"`
+	UserID          int32           `json:"user_id"`
+	TotalAmount     string          `json:"total_amount"`
+	Status          string          `json:"status"`
+	CreatedAt       time.Time       `json:"created_at"`
+	UpdatedAt       time.Time       `json:"updated_at"`
+	SubtotalAmount  string          `json:"subtotal_amount"`
+	ShippingAddress json.RawMessage `json:"shipping_address"`
+	BillingAddress  json.RawMessage `json:"billing_address"`
+	DiscountAmount  string          `json:"discount_amount"`
+	ShippingAmount  string          `json:"shipping_amount"`
+	CouponCode      string          `json:"coupon_code"`
 }
 
 type OrderItem struct {
-	ID               int32     `json:"id"`
-	OrderID          int32     `json:"order_id"`
-	ProductVariantID int32     `json:"product_variant_id"`
-	Quantity         int32     `json:"quantity"`
-	Price            string    `json:"price"`
-	CreatedAt        time.Time `json:"created_at"`
-	UpdatedAt        time.Time `json:"updated_at"`
+	ID               int32           `json:"id"`
+	OrderID          int32           `json:"order_id"`
+	ProductVariantID int32           `json:"product_variant_id"`
+	Quantity         int32           `json:"quantity"`
+	Price            string          `json:"price"`
+	CreatedAt        time.Time       `json:"created_at"`
+	UpdatedAt        time.Time       `json:"updated_at"`
+	ProductName      string          `json:"product_name"`
+	Color            string          `json:"color"`
+	Size             string          `json:"size"`
+	Sku              string          `json:"sku"`
+	DiscountAmount   string          `json:"discount_amount"`
+	Discounts        json.RawMessage `json:"discounts"`
+}
+
+type OrderStatusHistory struct {
+	ID         int32          `json:"id"`
+	OrderID    int32          `json:"order_id"`
+	FromStatus sql.NullString `json:"from_status"`
+	ToStatus   string         `json:"to_status"`
+	ChangedBy  sql.NullInt32  `json:"changed_by"`
+	Note       string         `json:"note"`
+	CreatedAt  time.Time      `json:"created_at"`
 }
 
 type PasswordReset struct {
@@ -45,6 +160,22 @@ type PasswordReset struct {
 	ExpiresAt  time.Time `json:"expires_at"`
 }
 
+type Payment struct {
+	ID                int32     `json:"id"`
+	OrderID           int32     `json:"order_id"`
+	Provider          string    `json:"provider"`
+	ProviderPaymentID string    `json:"provider_payment_id"`
+	Amount            string    `json:"amount"`
+	CapturedAmount    string    `json:"captured_amount"`
+	Currency          string    `json:"currency"`
+	Status            string    `json:"status"`
+	FailureReason     string    `json:"failure_reason"`
+	NextActionUrl     string    `json:"next_action_url"`
+	CreatedAt         time.Time `json:"created_at"`
+	UpdatedAt         time.Time `json:"updated_at"`
+	RefundedAmount    string    `json:"refunded_amount"`
+}
+
 type Product struct {
 	ID          int32     `json:"id"`
 	Name        string    `json:"name"`
@@ -57,14 +188,74 @@ type Product struct {
 }
 
 type ProductVariant struct {
-	ID        int32     `json:"id"`
-	ProductID int32     `json:"product_id"`
-	Color     string    `json:"color"`
-	Size      string    `json:"size"`
-	Stock     int32     `json:"stock"`
-	Price     string    `json:"price"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID         int32        `json:"id"`
+	ProductID  int32        `json:"product_id"`
+	Color      string       `json:"color"`
+	Size       string       `json:"size"`
+	Stock      int32        `json:"stock"`
+	Price      string       `json:"price"`
+	CreatedAt  time.Time    `json:"created_at"`
+	UpdatedAt  time.Time    `json:"updated_at"`
+	Sku        string       `json:"sku"`
+	ArchivedAt sql.NullTime `json:"archived_at"`
+}
+
+type Promotion struct {
+	ID          int32           `json:"id"`
+	Name        string          `json:"name"`
+	Description string          `json:"description"`
+	Type        string          `json:"type"`
+	Priority    int32           `json:"priority"`
+	Exclusive   bool            `json:"exclusive"`
+	Conditions  json.RawMessage `json:"conditions"`
+	StartsAt    sql.NullTime    `json:"starts_at"`
+	EndsAt      sql.NullTime    `json:"ends_at"`
+	IsActive    bool            `json:"is_active"`
+	CreatedAt   time.Time       `json:"created_at"`
+	UpdatedAt   time.Time       `json:"updated_at"`
+}
+
+type Refund struct {
+	ID            int32         `json:"id"`
+	OrderID       int32         `json:"order_id"`
+	PaymentID     int32         `json:"payment_id"`
+	Amount        string        `json:"amount"`
+	Reason        string        `json:"reason"`
+	Restock       bool          `json:"restock"`
+	Status        string        `json:"status"`
+	FailureReason string        `json:"failure_reason"`
+	CreatedBy     sql.NullInt32 `json:"created_by"`
+	CreatedAt     time.Time     `json:"created_at"`
+	UpdatedAt     time.Time     `json:"updated_at"`
+}
+
+type RefundItem struct {
+	ID          int32     `json:"id"`
+	RefundID    int32     `json:"refund_id"`
+	OrderItemID int32     `json:"order_item_id"`
+	Quantity    int32     `json:"quantity"`
+	Amount      string    `json:"amount"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type Return struct {
+	ID             int32         `json:"id"`
+	OrderID        int32         `json:"order_id"`
+	UserID         int32         `json:"user_id"`
+	Status         string        `json:"status"`
+	Reason         string        `json:"reason"`
+	ResolutionNote string        `json:"resolution_note"`
+	RefundID       sql.NullInt32 `json:"refund_id"`
+	CreatedAt      time.Time     `json:"created_at"`
+	UpdatedAt      time.Time     `json:"updated_at"`
+}
+
+type ReturnItem struct {
+	ID          int32     `json:"id"`
+	ReturnID    int32     `json:"return_id"`
+	OrderItemID int32     `json:"order_item_id"`
+	Quantity    int32     `json:"quantity"`
+	CreatedAt   time.Time `json:"created_at"`
 }
 
 type Review struct {
@@ -77,6 +268,13 @@ type Review struct {
 	UpdatedAt time.Time `json:"updated_at"`
 }
 
+type RevokedToken struct {
+	ID        uuid.UUID `json:"id"`
+	UserID    int32     `json:"user_id"`
+	ExpiresAt time.Time `json:"expires_at"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
 type Sale struct {
 	ID         int32          `json:"id"`
 	Month      int32          `json:"month"`
@@ -86,15 +284,52 @@ type Sale struct {
 	UpdatedAt  time.Time      `json:"updated_at"`
 }
 
+type Session struct {
+	ID           uuid.UUID `json:"id"`
+	UserID       int32     `json:"user_id"`
+	RefreshToken string    `json:"refresh_token"`
+	UserAgent    string    `json:"user_agent"`
+	ClientIp     string    `json:"client_ip"`
+	IsBlocked    bool      `json:"is_blocked"`
+	ExpiresAt    time.Time `json:"expires_at"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
 type User struct {
-	ID        int32           `json:"id"`
-	Name      string          `json:"name"`
-	Email     string          `json:"email"`
-	Password  string          `json:"password"`
-	Role      string          `json:"role"`
-	Addresses json.RawMessage `json:"addresses"`
-	CreatedAt time.Time       `json:"created_at"`
-	UpdatedAt time.Time       `json:"updated_at"`
+	ID              int32        `json:"id"`
+	Name            string       `json:"name"`
+	Email           string       `json:"email"`
+	Password        string       `json:"password"`
+	Role            string       `json:"role"`
+	CreatedAt       time.Time    `json:"created_at"`
+	UpdatedAt       time.Time    `json:"updated_at"`
+	EmailVerifiedAt sql.NullTime `json:"email_verified_at"`
+}
+
+type UserAddress struct {
+	ID                int32     `json:"id"`
+	UserID            int32     `json:"user_id"`
+	Label             string    `json:"label"`
+	FullName          string    `json:"full_name"`
+	Phone             string    `json:"phone"`
+	Line1             string    `json:"line1"`
+	Line2             string    `json:"line2"`
+	City              string    `json:"city"`
+	State             string    `json:"state"`
+	PostalCode        string    `json:"postal_code"`
+	Country           string    `json:"country"`
+	IsDefaultShipping bool      `json:"is_default_shipping"`
+	IsDefaultBilling  bool      `json:"is_default_billing"`
+	CreatedAt         time.Time `json:"created_at"`
+	UpdatedAt         time.Time `json:"updated_at"`
+}
+
+type UserMfa struct {
+	UserID       int32        `json:"user_id"`
+	Secret       string       `json:"secret"`
+	EnabledAt    sql.NullTime `json:"enabled_at"`
+	LastUsedStep int64        `json:"last_used_step"`
+	CreatedAt    time.Time    `json:"created_at"`
 }
 
 type Wishlist struct {
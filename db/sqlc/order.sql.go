@@ -7,23 +7,40 @@ package sqlc
 
 import (
 	"context"
+	"encoding/json"
 	"time"
 )
 
 const createOrder = `-- name: CreateOrder :one
-INSERT INTO orders (user_id, total_amount, status)
-VALUES ($1, $2, $3)
-RETURNING id, user_id, total_amount, status, created_at, updated_at
+INSERT INTO orders (user_id, subtotal_amount, total_amount, status, shipping_address, billing_address, discount_amount, shipping_amount, coupon_code)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+RETURNING id, user_id, total_amount, status, created_at, updated_at, subtotal_amount, shipping_address, billing_address, discount_amount, shipping_amount, coupon_code
 `
 
 type CreateOrderParams struct {
-	UserID      int32  `json:"user_id"`
-	TotalAmount string `json:"total_amount"`
-	Status      string `json:"status"`
+	UserID          int32           `json:"user_id"`
+	SubtotalAmount  string          `json:"subtotal_amount"`
+	TotalAmount     string          `json:"total_amount"`
+	Status          string          `json:"status"`
+	ShippingAddress json.RawMessage `json:"shipping_address"`
+	BillingAddress  json.RawMessage `json:"billing_address"`
+	DiscountAmount  string          `json:"discount_amount"`
+	ShippingAmount  string          `json:"shipping_amount"`
+	CouponCode      string          `json:"coupon_code"`
 }
 
 func (q *Queries) CreateOrder(ctx context.Context, arg CreateOrderParams) (Order, error) {
-	row := q.db.QueryRowContext(ctx, createOrder, arg.UserID, arg.TotalAmount, arg.Status)
+	row := q.db.QueryRowContext(ctx, createOrder,
+		arg.UserID,
+		arg.SubtotalAmount,
+		arg.TotalAmount,
+		arg.Status,
+		arg.ShippingAddress,
+		arg.BillingAddress,
+		arg.DiscountAmount,
+		arg.ShippingAmount,
+		arg.CouponCode,
+	)
 	var i Order
 	err := row.Scan(
 		&i.ID,
@@ -32,6 +49,12 @@ func (q *Queries) CreateOrder(ctx context.Context, arg CreateOrderParams) (Order
 		&i.Status,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.SubtotalAmount,
+		&i.ShippingAddress,
+		&i.BillingAddress,
+		&i.DiscountAmount,
+		&i.ShippingAmount,
+		&i.CouponCode,
 	)
 	return i, err
 }
@@ -50,6 +73,7 @@ const getMonthlySales = `-- name: GetMonthlySales :many
 SELECT EXTRACT(MONTH FROM created_at) AS month, SUM(total_amount) AS total_sales
 FROM orders
 WHERE EXTRACT(YEAR FROM created_at) = $1
+  AND status NOT IN ('cancelled', 'refunded')
 GROUP BY month
 ORDER BY month
 `
@@ -83,7 +107,7 @@ func (q *Queries) GetMonthlySales(ctx context.Context, createdAt time.Time) ([]G
 }
 
 const getOrderById = `-- name: GetOrderById :one
-SELECT id, user_id, total_amount, status, created_at, updated_at
+SELECT id, user_id, total_amount, status, created_at, updated_at, subtotal_amount, shipping_address, billing_address, discount_amount, shipping_amount, coupon_code
 FROM orders
 WHERE id = $1
 `
@@ -98,12 +122,45 @@ func (q *Queries) GetOrderById(ctx context.Context, id int32) (Order, error) {
 		&i.Status,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.SubtotalAmount,
+		&i.ShippingAddress,
+		&i.BillingAddress,
+		&i.DiscountAmount,
+		&i.ShippingAmount,
+		&i.CouponCode,
+	)
+	return i, err
+}
+
+const getOrderForUpdate = `-- name: GetOrderForUpdate :one
+SELECT id, user_id, total_amount, status, created_at, updated_at, subtotal_amount, shipping_address, billing_address, discount_amount, shipping_amount, coupon_code
+FROM orders
+WHERE id = $1
+FOR UPDATE
+`
+
+func (q *Queries) GetOrderForUpdate(ctx context.Context, id int32) (Order, error) {
+	row := q.db.QueryRowContext(ctx, getOrderForUpdate, id)
+	var i Order
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.TotalAmount,
+		&i.Status,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.SubtotalAmount,
+		&i.ShippingAddress,
+		&i.BillingAddress,
+		&i.DiscountAmount,
+		&i.ShippingAmount,
+		&i.CouponCode,
 	)
 	return i, err
 }
 
 const getOrdersByUserId = `-- name: GetOrdersByUserId :many
-SELECT id, user_id, total_amount, status, created_at, updated_at
+SELECT id, user_id, total_amount, status, created_at, updated_at, subtotal_amount, shipping_address, billing_address, discount_amount, shipping_amount, coupon_code
 FROM orders
 WHERE user_id = $1
 ORDER BY id
@@ -133,6 +190,12 @@ func (q *Queries) GetOrdersByUserId(ctx context.Context, arg GetOrdersByUserIdPa
 			&i.Status,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.SubtotalAmount,
+			&i.ShippingAddress,
+			&i.BillingAddress,
+			&i.DiscountAmount,
+			&i.ShippingAmount,
+			&i.CouponCode,
 		); err != nil {
 			return nil, err
 		}
@@ -148,7 +211,7 @@ func (q *Queries) GetOrdersByUserId(ctx context.Context, arg GetOrdersByUserIdPa
 }
 
 const listOrders = `-- name: ListOrders :many
-SELECT id, user_id, total_amount, status, created_at, updated_at
+SELECT id, user_id, total_amount, status, created_at, updated_at, subtotal_amount, shipping_address, billing_address, discount_amount, shipping_amount, coupon_code
 FROM orders
 ORDER BY id
 LIMIT $1
@@ -176,6 +239,12 @@ func (q *Queries) ListOrders(ctx context.Context, arg ListOrdersParams) ([]Order
 			&i.Status,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.SubtotalAmount,
+			&i.ShippingAddress,
+			&i.BillingAddress,
+			&i.DiscountAmount,
+			&i.ShippingAmount,
+			&i.CouponCode,
 		); err != nil {
 			return nil, err
 		}
@@ -190,27 +259,53 @@ func (q *Queries) ListOrders(ctx context.Context, arg ListOrdersParams) ([]Order
 	return items, nil
 }
 
-const updateOrder = `-- name: UpdateOrder :one
+const updateOrderAddresses = `-- name: UpdateOrderAddresses :one
 UPDATE orders
-SET user_id = $2, total_amount = $3, status = $4, updated_at = CURRENT_TIMESTAMP
+SET shipping_address = $2, billing_address = $3, updated_at = CURRENT_TIMESTAMP
 WHERE id = $1
-RETURNING id, user_id, total_amount, status, created_at, updated_at
+RETURNING id, user_id, total_amount, status, created_at, updated_at, subtotal_amount, shipping_address, billing_address, discount_amount, shipping_amount, coupon_code
 `
 
-type UpdateOrderParams struct {
-	ID          int32  `json:"id"`
-	UserID      int32  `json:"user_id"`
-	TotalAmount string `json:"total_amount"`
-	Status      string `json:"status"`
+type UpdateOrderAddressesParams struct {
+	ID              int32           `json:"id"`
+	ShippingAddress json.RawMessage `json:"shipping_address"`
+	BillingAddress  json.RawMessage `json:"billing_address"`
 }
 
-func (q *Queries) UpdateOrder(ctx context.Context, arg UpdateOrderParams) (Order, error) {
-	row := q.db.QueryRowContext(ctx, updateOrder,
-		arg.ID,
-		arg.UserID,
-		arg.TotalAmount,
-		arg.Status,
+func (q *Queries) UpdateOrderAddresses(ctx context.Context, arg UpdateOrderAddressesParams) (Order, error) {
+	row := q.db.QueryRowContext(ctx, updateOrderAddresses, arg.ID, arg.ShippingAddress, arg.BillingAddress)
+	var i Order
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.TotalAmount,
+		&i.Status,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.SubtotalAmount,
+		&i.ShippingAddress,
+		&i.BillingAddress,
+		&i.DiscountAmount,
+		&i.ShippingAmount,
+		&i.CouponCode,
 	)
+	return i, err
+}
+
+const updateOrderStatus = `-- name: UpdateOrderStatus :one
+UPDATE orders
+SET status = $2, updated_at = CURRENT_TIMESTAMP
+WHERE id = $1
+RETURNING id, user_id, total_amount, status, created_at, updated_at, subtotal_amount, shipping_address, billing_address, discount_amount, shipping_amount, coupon_code
+`
+
+type UpdateOrderStatusParams struct {
+	ID     int32  `json:"id"`
+	Status string `json:"status"`
+}
+
+func (q *Queries) UpdateOrderStatus(ctx context.Context, arg UpdateOrderStatusParams) (Order, error) {
+	row := q.db.QueryRowContext(ctx, updateOrderStatus, arg.ID, arg.Status)
 	var i Order
 	err := row.Scan(
 		&i.ID,
@@ -219,6 +314,12 @@ func (q *Queries) UpdateOrder(ctx context.Context, arg UpdateOrderParams) (Order
 		&i.Status,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.SubtotalAmount,
+		&i.ShippingAddress,
+		&i.BillingAddress,
+		&i.DiscountAmount,
+		&i.ShippingAmount,
+		&i.CouponCode,
 	)
 	return i, err
 }
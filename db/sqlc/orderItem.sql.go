@@ -7,19 +7,28 @@ package sqlc
 
 import (
 	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
 )
 
 const createOrderItem = `-- name: CreateOrderItem :one
-INSERT INTO order_items (order_id, product_variant_id, quantity, price)
-VALUES ($1, $2, $3, $4)
-RETURNING id, order_id, product_variant_id, quantity, price, created_at, updated_at
+INSERT INTO order_items (order_id, product_variant_id, quantity, price, product_name, color, size, sku, discount_amount, discounts)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+RETURNING id, order_id, product_variant_id, quantity, price, created_at, updated_at, product_name, color, size, sku, discount_amount, discounts
 `
 
 type CreateOrderItemParams struct {
-	OrderID          int32  `json:"order_id"`
-	ProductVariantID int32  `json:"product_variant_id"`
-	Quantity         int32  `json:"quantity"`
-	Price            string `json:"price"`
+	OrderID          int32           `json:"order_id"`
+	ProductVariantID int32           `json:"product_variant_id"`
+	Quantity         int32           `json:"quantity"`
+	Price            string          `json:"price"`
+	ProductName      string          `json:"product_name"`
+	Color            string          `json:"color"`
+	Size             string          `json:"size"`
+	Sku              string          `json:"sku"`
+	DiscountAmount   string          `json:"discount_amount"`
+	Discounts        json.RawMessage `json:"discounts"`
 }
 
 func (q *Queries) CreateOrderItem(ctx context.Context, arg CreateOrderItemParams) (OrderItem, error) {
@@ -28,6 +37,12 @@ func (q *Queries) CreateOrderItem(ctx context.Context, arg CreateOrderItemParams
 		arg.ProductVariantID,
 		arg.Quantity,
 		arg.Price,
+		arg.ProductName,
+		arg.Color,
+		arg.Size,
+		arg.Sku,
+		arg.DiscountAmount,
+		arg.Discounts,
 	)
 	var i OrderItem
 	err := row.Scan(
@@ -38,6 +53,12 @@ func (q *Queries) CreateOrderItem(ctx context.Context, arg CreateOrderItemParams
 		&i.Price,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.ProductName,
+		&i.Color,
+		&i.Size,
+		&i.Sku,
+		&i.DiscountAmount,
+		&i.Discounts,
 	)
 	return i, err
 }
@@ -52,8 +73,52 @@ func (q *Queries) DeleteOrderItem(ctx context.Context, id int32) error {
 	return err
 }
 
+const getAllOrderItemsByOrderId = `-- name: GetAllOrderItemsByOrderId :many
+SELECT id, order_id, product_variant_id, quantity, price, created_at, updated_at, product_name, color, size, sku, discount_amount, discounts
+FROM order_items
+WHERE order_id = $1
+ORDER BY id
+`
+
+func (q *Queries) GetAllOrderItemsByOrderId(ctx context.Context, orderID int32) ([]OrderItem, error) {
+	rows, err := q.db.QueryContext(ctx, getAllOrderItemsByOrderId, orderID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []OrderItem{}
+	for rows.Next() {
+		var i OrderItem
+		if err := rows.Scan(
+			&i.ID,
+			&i.OrderID,
+			&i.ProductVariantID,
+			&i.Quantity,
+			&i.Price,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.ProductName,
+			&i.Color,
+			&i.Size,
+			&i.Sku,
+			&i.DiscountAmount,
+			&i.Discounts,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getOrderItemById = `-- name: GetOrderItemById :one
-SELECT id, order_id, product_variant_id, quantity, price, created_at, updated_at
+SELECT id, order_id, product_variant_id, quantity, price, created_at, updated_at, product_name, color, size, sku, discount_amount, discounts
 FROM order_items
 WHERE id = $1
 `
@@ -69,12 +134,18 @@ func (q *Queries) GetOrderItemById(ctx context.Context, id int32) (OrderItem, er
 		&i.Price,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.ProductName,
+		&i.Color,
+		&i.Size,
+		&i.Sku,
+		&i.DiscountAmount,
+		&i.Discounts,
 	)
 	return i, err
 }
 
 const getOrderItemsByOrderId = `-- name: GetOrderItemsByOrderId :many
-SELECT id, order_id, product_variant_id, quantity, price, created_at, updated_at
+SELECT id, order_id, product_variant_id, quantity, price, created_at, updated_at, product_name, color, size, sku, discount_amount, discounts
 FROM order_items
 WHERE order_id = $1
 ORDER BY id
@@ -105,6 +176,113 @@ func (q *Queries) GetOrderItemsByOrderId(ctx context.Context, arg GetOrderItemsB
 			&i.Price,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.ProductName,
+			&i.Color,
+			&i.Size,
+			&i.Sku,
+			&i.DiscountAmount,
+			&i.Discounts,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listOrderItemDetailsByOrderId = `-- name: ListOrderItemDetailsByOrderId :many
+SELECT oi.id, oi.order_id, oi.product_variant_id, oi.quantity, oi.price, oi.created_at, oi.updated_at,
+       oi.product_name, oi.color, oi.size, oi.sku, oi.discount_amount, oi.discounts,
+       pv.product_id, pv.color AS variant_color, pv.size AS variant_size, pv.stock AS variant_stock,
+       pv.price AS variant_price, pv.sku AS variant_sku, pv.archived_at AS variant_archived_at,
+       pv.created_at AS variant_created_at, pv.updated_at AS variant_updated_at,
+       p.name AS current_product_name, p.description AS product_description, p.price AS product_price,
+       p.stock AS product_stock, p.category_id AS product_category_id,
+       p.created_at AS product_created_at, p.updated_at AS product_updated_at
+FROM order_items oi
+JOIN product_variants pv ON pv.id = oi.product_variant_id
+JOIN products p ON p.id = pv.product_id
+WHERE oi.order_id = $1
+ORDER BY oi.id
+`
+
+type ListOrderItemDetailsByOrderIdRow struct {
+	ID                 int32           `json:"id"`
+	OrderID            int32           `json:"order_id"`
+	ProductVariantID   int32           `json:"product_variant_id"`
+	Quantity           int32           `json:"quantity"`
+	Price              string          `json:"price"`
+	CreatedAt          time.Time       `json:"created_at"`
+	UpdatedAt          time.Time       `json:"updated_at"`
+	ProductName        string          `json:"product_name"`
+	Color              string          `json:"color"`
+	Size               string          `json:"size"`
+	Sku                string          `json:"sku"`
+	DiscountAmount     string          `json:"discount_amount"`
+	Discounts          json.RawMessage `json:"discounts"`
+	ProductID          int32           `json:"product_id"`
+	VariantColor       string          `json:"variant_color"`
+	VariantSize        string          `json:"variant_size"`
+	VariantStock       int32           `json:"variant_stock"`
+	VariantPrice       string          `json:"variant_price"`
+	VariantSku         string          `json:"variant_sku"`
+	VariantArchivedAt  sql.NullTime    `json:"variant_archived_at"`
+	VariantCreatedAt   time.Time       `json:"variant_created_at"`
+	VariantUpdatedAt   time.Time       `json:"variant_updated_at"`
+	CurrentProductName string          `json:"current_product_name"`
+	ProductDescription string          `json:"product_description"`
+	ProductPrice       string          `json:"product_price"`
+	ProductStock       int32           `json:"product_stock"`
+	ProductCategoryID  int32           `json:"product_category_id"`
+	ProductCreatedAt   time.Time       `json:"product_created_at"`
+	ProductUpdatedAt   time.Time       `json:"product_updated_at"`
+}
+
+func (q *Queries) ListOrderItemDetailsByOrderId(ctx context.Context, orderID int32) ([]ListOrderItemDetailsByOrderIdRow, error) {
+	rows, err := q.db.QueryContext(ctx, listOrderItemDetailsByOrderId, orderID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListOrderItemDetailsByOrderIdRow{}
+	for rows.Next() {
+		var i ListOrderItemDetailsByOrderIdRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.OrderID,
+			&i.ProductVariantID,
+			&i.Quantity,
+			&i.Price,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.ProductName,
+			&i.Color,
+			&i.Size,
+			&i.Sku,
+			&i.DiscountAmount,
+			&i.Discounts,
+			&i.ProductID,
+			&i.VariantColor,
+			&i.VariantSize,
+			&i.VariantStock,
+			&i.VariantPrice,
+			&i.VariantSku,
+			&i.VariantArchivedAt,
+			&i.VariantCreatedAt,
+			&i.VariantUpdatedAt,
+			&i.CurrentProductName,
+			&i.ProductDescription,
+			&i.ProductPrice,
+			&i.ProductStock,
+			&i.ProductCategoryID,
+			&i.ProductCreatedAt,
+			&i.ProductUpdatedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -120,7 +298,7 @@ func (q *Queries) GetOrderItemsByOrderId(ctx context.Context, arg GetOrderItemsB
 }
 
 const listOrderItems = `-- name: ListOrderItems :many
-SELECT id, order_id, product_variant_id, quantity, price, created_at, updated_at
+SELECT id, order_id, product_variant_id, quantity, price, created_at, updated_at, product_name, color, size, sku, discount_amount, discounts
 FROM order_items
 ORDER BY id
 LIMIT $1
@@ -149,6 +327,12 @@ func (q *Queries) ListOrderItems(ctx context.Context, arg ListOrderItemsParams)
 			&i.Price,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.ProductName,
+			&i.Color,
+			&i.Size,
+			&i.Sku,
+			&i.DiscountAmount,
+			&i.Discounts,
 		); err != nil {
 			return nil, err
 		}
@@ -167,7 +351,7 @@ const updateOrderItem = `-- name: UpdateOrderItem :one
 UPDATE order_items
 SET order_id = $2, product_variant_id = $3, quantity = $4, price = $5, updated_at = CURRENT_TIMESTAMP
 WHERE id = $1
-RETURNING id, order_id, product_variant_id, quantity, price, created_at, updated_at
+RETURNING id, order_id, product_variant_id, quantity, price, created_at, updated_at, product_name, color, size, sku, discount_amount, discounts
 `
 
 type UpdateOrderItemParams struct {
@@ -195,6 +379,12 @@ func (q *Queries) UpdateOrderItem(ctx context.Context, arg UpdateOrderItemParams
 		&i.Price,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.ProductName,
+		&i.Color,
+		&i.Size,
+		&i.Sku,
+		&i.DiscountAmount,
+		&i.Discounts,
 	)
 	return i, err
 }
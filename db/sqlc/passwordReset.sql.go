@@ -63,6 +63,16 @@ func (q *Queries) DeletePasswordReset(ctx context.Context, resetToken string) er
 	return err
 }
 
+const deletePasswordResetsByUserId = `-- name: DeletePasswordResetsByUserId :exec
+DELETE FROM password_resets
+WHERE user_id = $1
+`
+
+func (q *Queries) DeletePasswordResetsByUserId(ctx context.Context, userID int32) error {
+	_, err := q.db.ExecContext(ctx, deletePasswordResetsByUserId, userID)
+	return err
+}
+
 const getPasswordResetByID = `-- name: GetPasswordResetByID :one
 SELECT id, user_id, reset_token, created_at, expires_at
 FROM password_resets
@@ -109,6 +119,26 @@ func (q *Queries) GetPasswordResetByToken(ctx context.Context, resetToken string
 	return i, err
 }
 
+const getPasswordResetByTokenForUpdate = `-- name: GetPasswordResetByTokenForUpdate :one
+SELECT id, user_id, reset_token, created_at, expires_at
+FROM password_resets
+WHERE reset_token = $1
+FOR UPDATE
+`
+
+func (q *Queries) GetPasswordResetByTokenForUpdate(ctx context.Context, resetToken string) (PasswordReset, error) {
+	row := q.db.QueryRowContext(ctx, getPasswordResetByTokenForUpdate, resetToken)
+	var i PasswordReset
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.ResetToken,
+		&i.CreatedAt,
+		&i.ExpiresAt,
+	)
+	return i, err
+}
+
 const getPasswordResetByUserId = `-- name: GetPasswordResetByUserId :one
 SELECT id, user_id, reset_token, expires_at, created_at
 FROM password_resets
@@ -9,10 +9,65 @@ import (
 	"context"
 )
 
+const addProductVariantStock = `-- name: AddProductVariantStock :one
+UPDATE product_variants
+SET stock = stock + $1, updated_at = CURRENT_TIMESTAMP
+WHERE id = $2
+RETURNING id, product_id, color, size, stock, price, created_at, updated_at, sku, archived_at
+`
+
+type AddProductVariantStockParams struct {
+	Amount int32 `json:"amount"`
+	ID     int32 `json:"id"`
+}
+
+func (q *Queries) AddProductVariantStock(ctx context.Context, arg AddProductVariantStockParams) (ProductVariant, error) {
+	row := q.db.QueryRowContext(ctx, addProductVariantStock, arg.Amount, arg.ID)
+	var i ProductVariant
+	err := row.Scan(
+		&i.ID,
+		&i.ProductID,
+		&i.Color,
+		&i.Size,
+		&i.Stock,
+		&i.Price,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Sku,
+		&i.ArchivedAt,
+	)
+	return i, err
+}
+
+const archiveProductVariant = `-- name: ArchiveProductVariant :one
+UPDATE product_variants
+SET archived_at = COALESCE(archived_at, CURRENT_TIMESTAMP), updated_at = CURRENT_TIMESTAMP
+WHERE id = $1
+RETURNING id, product_id, color, size, stock, price, created_at, updated_at, sku, archived_at
+`
+
+func (q *Queries) ArchiveProductVariant(ctx context.Context, id int32) (ProductVariant, error) {
+	row := q.db.QueryRowContext(ctx, archiveProductVariant, id)
+	var i ProductVariant
+	err := row.Scan(
+		&i.ID,
+		&i.ProductID,
+		&i.Color,
+		&i.Size,
+		&i.Stock,
+		&i.Price,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Sku,
+		&i.ArchivedAt,
+	)
+	return i, err
+}
+
 const createProductVariant = `-- name: CreateProductVariant :one
-INSERT INTO product_variants (product_id, color, size, stock, price)
-VALUES ($1, $2, $3, $4, $5)
-RETURNING id, product_id, color, size, stock, price, created_at, updated_at
+INSERT INTO product_variants (product_id, color, size, stock, price, sku)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING id, product_id, color, size, stock, price, created_at, updated_at, sku, archived_at
 `
 
 type CreateProductVariantParams struct {
@@ -21,6 +76,7 @@ type CreateProductVariantParams struct {
 	Size      string `json:"size"`
 	Stock     int32  `json:"stock"`
 	Price     string `json:"price"`
+	Sku       string `json:"sku"`
 }
 
 func (q *Queries) CreateProductVariant(ctx context.Context, arg CreateProductVariantParams) (ProductVariant, error) {
@@ -30,6 +86,7 @@ func (q *Queries) CreateProductVariant(ctx context.Context, arg CreateProductVar
 		arg.Size,
 		arg.Stock,
 		arg.Price,
+		arg.Sku,
 	)
 	var i ProductVariant
 	err := row.Scan(
@@ -41,6 +98,8 @@ func (q *Queries) CreateProductVariant(ctx context.Context, arg CreateProductVar
 		&i.Price,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Sku,
+		&i.ArchivedAt,
 	)
 	return i, err
 }
@@ -56,7 +115,7 @@ func (q *Queries) DeleteProductVariant(ctx context.Context, id int32) error {
 }
 
 const getProductVariantById = `-- name: GetProductVariantById :one
-SELECT id, product_id, color, size, stock, price, created_at, updated_at
+SELECT id, product_id, color, size, stock, price, created_at, updated_at, sku, archived_at
 FROM product_variants
 WHERE id = $1
 `
@@ -73,13 +132,41 @@ func (q *Queries) GetProductVariantById(ctx context.Context, id int32) (ProductV
 		&i.Price,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Sku,
+		&i.ArchivedAt,
+	)
+	return i, err
+}
+
+const getProductVariantForUpdate = `-- name: GetProductVariantForUpdate :one
+SELECT id, product_id, color, size, stock, price, created_at, updated_at, sku, archived_at
+FROM product_variants
+WHERE id = $1
+FOR UPDATE
+`
+
+func (q *Queries) GetProductVariantForUpdate(ctx context.Context, id int32) (ProductVariant, error) {
+	row := q.db.QueryRowContext(ctx, getProductVariantForUpdate, id)
+	var i ProductVariant
+	err := row.Scan(
+		&i.ID,
+		&i.ProductID,
+		&i.Color,
+		&i.Size,
+		&i.Stock,
+		&i.Price,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Sku,
+		&i.ArchivedAt,
 	)
 	return i, err
 }
 
 const listProductVariants = `-- name: ListProductVariants :many
-SELECT id, product_id, color, size, stock, price, created_at, updated_at
+SELECT id, product_id, color, size, stock, price, created_at, updated_at, sku, archived_at
 FROM product_variants
+WHERE archived_at IS NULL
 ORDER BY id
 LIMIT $1
 OFFSET $2
@@ -108,6 +195,8 @@ func (q *Queries) ListProductVariants(ctx context.Context, arg ListProductVarian
 			&i.Price,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.Sku,
+			&i.ArchivedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -122,11 +211,24 @@ func (q *Queries) ListProductVariants(ctx context.Context, arg ListProductVarian
 	return items, nil
 }
 
+const productVariantHasOrders = `-- name: ProductVariantHasOrders :one
+SELECT EXISTS (
+  SELECT 1 FROM order_items WHERE product_variant_id = $1
+)
+`
+
+func (q *Queries) ProductVariantHasOrders(ctx context.Context, productVariantID int32) (bool, error) {
+	row := q.db.QueryRowContext(ctx, productVariantHasOrders, productVariantID)
+	var exists bool
+	err := row.Scan(&exists)
+	return exists, err
+}
+
 const updateProductVariant = `-- name: UpdateProductVariant :one
 UPDATE product_variants
-SET product_id = $2, color = $3, size = $4, stock = $5, price = $6, updated_at = CURRENT_TIMESTAMP
+SET product_id = $2, color = $3, size = $4, stock = $5, price = $6, sku = $7, updated_at = CURRENT_TIMESTAMP
 WHERE id = $1
-RETURNING id, product_id, color, size, stock, price, created_at, updated_at
+RETURNING id, product_id, color, size, stock, price, created_at, updated_at, sku, archived_at
 `
 
 type UpdateProductVariantParams struct {
@@ -136,6 +238,7 @@ type UpdateProductVariantParams struct {
 	Size      string `json:"size"`
 	Stock     int32  `json:"stock"`
 	Price     string `json:"price"`
+	Sku       string `json:"sku"`
 }
 
 func (q *Queries) UpdateProductVariant(ctx context.Context, arg UpdateProductVariantParams) (ProductVariant, error) {
@@ -146,6 +249,7 @@ func (q *Queries) UpdateProductVariant(ctx context.Context, arg UpdateProductVar
 		arg.Size,
 		arg.Stock,
 		arg.Price,
+		arg.Sku,
 	)
 	var i ProductVariant
 	err := row.Scan(
@@ -157,6 +261,8 @@ func (q *Queries) UpdateProductVariant(ctx context.Context, arg UpdateProductVar
 		&i.Price,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Sku,
+		&i.ArchivedAt,
 	)
 	return i, err
 }
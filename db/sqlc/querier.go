@@ -6,69 +6,188 @@ package sqlc
 
 import (
 	"context"
+	"database/sql"
 	"time"
+
+	"github.com/google/uuid"
 )
 
 type Querier interface {
+	AddCartItem(ctx context.Context, arg AddCartItemParams) (CartItem, error)
+	AddCouponCategory(ctx context.Context, arg AddCouponCategoryParams) error
+	AddCouponProduct(ctx context.Context, arg AddCouponProductParams) error
+	AddPaymentRefundedAmount(ctx context.Context, arg AddPaymentRefundedAmountParams) (Payment, error)
+	AddProductVariantStock(ctx context.Context, arg AddProductVariantStockParams) (ProductVariant, error)
+	ArchiveProductVariant(ctx context.Context, id int32) (ProductVariant, error)
+	AssignCartToUser(ctx context.Context, arg AssignCartToUserParams) (Cart, error)
+	BlockSession(ctx context.Context, id uuid.UUID) error
+	BlockUserSessions(ctx context.Context, userID int32) error
+	ClearDefaultBillingAddress(ctx context.Context, userID int32) error
+	ClearDefaultShippingAddress(ctx context.Context, userID int32) error
+	CountCouponRedemptions(ctx context.Context, couponID int32) (int64, error)
+	CountCouponRedemptionsByUser(ctx context.Context, arg CountCouponRedemptionsByUserParams) (int64, error)
+	CountUnusedMfaRecoveryCodes(ctx context.Context, userID int32) (int64, error)
+	CountUserAddresses(ctx context.Context, userID int32) (int64, error)
+	CreateApiKey(ctx context.Context, arg CreateApiKeyParams) (ApiKey, error)
+	CreateCart(ctx context.Context, arg CreateCartParams) (Cart, error)
 	CreateCategory(ctx context.Context, arg CreateCategoryParams) (Category, error)
+	CreateCoupon(ctx context.Context, arg CreateCouponParams) (Coupon, error)
+	CreateCouponRedemption(ctx context.Context, arg CreateCouponRedemptionParams) (CouponRedemption, error)
+	CreateEmailVerification(ctx context.Context, arg CreateEmailVerificationParams) (EmailVerification, error)
+	CreateMfaRecoveryCode(ctx context.Context, arg CreateMfaRecoveryCodeParams) error
 	CreateOrder(ctx context.Context, arg CreateOrderParams) (Order, error)
 	CreateOrderItem(ctx context.Context, arg CreateOrderItemParams) (OrderItem, error)
+	CreateOrderStatusHistory(ctx context.Context, arg CreateOrderStatusHistoryParams) (OrderStatusHistory, error)
 	CreatePasswordReset(ctx context.Context, arg CreatePasswordResetParams) (CreatePasswordResetRow, error)
+	CreatePayment(ctx context.Context, arg CreatePaymentParams) (Payment, error)
 	CreateProduct(ctx context.Context, arg CreateProductParams) (Product, error)
 	CreateProductVariant(ctx context.Context, arg CreateProductVariantParams) (ProductVariant, error)
+	CreatePromotion(ctx context.Context, arg CreatePromotionParams) (Promotion, error)
+	CreateRefund(ctx context.Context, arg CreateRefundParams) (Refund, error)
+	CreateRefundItem(ctx context.Context, arg CreateRefundItemParams) (RefundItem, error)
+	CreateReturn(ctx context.Context, arg CreateReturnParams) (Return, error)
+	CreateReturnItem(ctx context.Context, arg CreateReturnItemParams) (ReturnItem, error)
 	CreateReview(ctx context.Context, arg CreateReviewParams) (Review, error)
+	CreateRevokedToken(ctx context.Context, arg CreateRevokedTokenParams) error
 	CreateSale(ctx context.Context, arg CreateSaleParams) (Sale, error)
+	CreateSession(ctx context.Context, arg CreateSessionParams) (Session, error)
 	CreateUser(ctx context.Context, arg CreateUserParams) (User, error)
+	CreateUserAddress(ctx context.Context, arg CreateUserAddressParams) (UserAddress, error)
 	CreateWishlistItem(ctx context.Context, arg CreateWishlistItemParams) (Wishlist, error)
+	DeleteCart(ctx context.Context, id int32) error
+	DeleteCartItem(ctx context.Context, id int32) error
+	DeleteCartItemsByCartId(ctx context.Context, cartID int32) error
 	DeleteCategory(ctx context.Context, id int32) error
+	DeleteCoupon(ctx context.Context, id int32) error
+	DeleteCouponCategories(ctx context.Context, couponID int32) error
+	DeleteCouponProducts(ctx context.Context, couponID int32) error
+	DeleteEmailVerificationsByUserId(ctx context.Context, userID int32) error
+	DeleteExpiredEmailVerifications(ctx context.Context) error
 	DeleteExpiredPasswordResets(ctx context.Context) error
+	DeleteExpiredRevokedTokens(ctx context.Context) error
+	DeleteLoginFailure(ctx context.Context, arg DeleteLoginFailureParams) error
+	DeleteLoginFailureById(ctx context.Context, id int32) (int64, error)
+	DeleteMfaRecoveryCodes(ctx context.Context, userID int32) error
 	DeleteOrder(ctx context.Context, id int32) error
 	DeleteOrderItem(ctx context.Context, id int32) error
 	DeletePasswordReset(ctx context.Context, resetToken string) error
+	DeletePasswordResetsByUserId(ctx context.Context, userID int32) error
 	DeleteProduct(ctx context.Context, id int32) error
 	DeleteProductVariant(ctx context.Context, id int32) error
+	DeletePromotion(ctx context.Context, id int32) error
 	DeleteReview(ctx context.Context, id int32) error
 	DeleteSale(ctx context.Context, id int32) error
 	DeleteUser(ctx context.Context, id int32) error
+	DeleteUserAddress(ctx context.Context, arg DeleteUserAddressParams) (int64, error)
+	DeleteUserMfa(ctx context.Context, userID int32) (int64, error)
 	DeleteWishlistItem(ctx context.Context, id int32) error
+	EnableUserMfa(ctx context.Context, arg EnableUserMfaParams) (UserMfa, error)
+	GetAllOrderItemsByOrderId(ctx context.Context, orderID int32) ([]OrderItem, error)
+	GetApiKeyByPrefix(ctx context.Context, prefix string) (ApiKey, error)
+	GetCartByToken(ctx context.Context, token sql.NullString) (Cart, error)
+	GetCartByUserId(ctx context.Context, userID sql.NullInt32) (Cart, error)
+	GetCartForUpdate(ctx context.Context, id int32) (Cart, error)
+	GetCartItemById(ctx context.Context, id int32) (CartItem, error)
 	GetCategoryById(ctx context.Context, id int32) (Category, error)
+	GetCouponByCodeForUpdate(ctx context.Context, code string) (Coupon, error)
+	GetCouponById(ctx context.Context, id int32) (Coupon, error)
+	GetEmailVerificationByTokenForUpdate(ctx context.Context, verificationToken string) (EmailVerification, error)
+	GetLoginFailure(ctx context.Context, arg GetLoginFailureParams) (LoginFailure, error)
 	GetMonthlySales(ctx context.Context, createdAt time.Time) ([]GetMonthlySalesRow, error)
 	GetOrderById(ctx context.Context, id int32) (Order, error)
+	GetOrderForUpdate(ctx context.Context, id int32) (Order, error)
 	GetOrderItemById(ctx context.Context, id int32) (OrderItem, error)
 	GetOrderItemsByOrderId(ctx context.Context, arg GetOrderItemsByOrderIdParams) ([]OrderItem, error)
 	GetOrdersByUserId(ctx context.Context, arg GetOrdersByUserIdParams) ([]Order, error)
 	GetPasswordResetByID(ctx context.Context, id int32) (PasswordReset, error)
 	GetPasswordResetByToken(ctx context.Context, resetToken string) (GetPasswordResetByTokenRow, error)
+	GetPasswordResetByTokenForUpdate(ctx context.Context, resetToken string) (PasswordReset, error)
 	GetPasswordResetByUserId(ctx context.Context, userID int32) (GetPasswordResetByUserIdRow, error)
 	GetPasswordResetByUserIdAndToken(ctx context.Context, arg GetPasswordResetByUserIdAndTokenParams) (GetPasswordResetByUserIdAndTokenRow, error)
+	GetPayment(ctx context.Context, id int32) (Payment, error)
 	GetProductById(ctx context.Context, id int32) (Product, error)
 	GetProductVariantById(ctx context.Context, id int32) (ProductVariant, error)
+	GetProductVariantForUpdate(ctx context.Context, id int32) (ProductVariant, error)
+	GetPromotionById(ctx context.Context, id int32) (Promotion, error)
+	GetRefund(ctx context.Context, id int32) (Refund, error)
+	GetRefundForUpdate(ctx context.Context, id int32) (Refund, error)
+	GetRefundablePaymentForUpdate(ctx context.Context, orderID int32) (Payment, error)
+	GetReturn(ctx context.Context, id int32) (Return, error)
+	GetReturnForUpdate(ctx context.Context, id int32) (Return, error)
 	GetReviewById(ctx context.Context, id int32) (Review, error)
 	GetReviewsByProductId(ctx context.Context, arg GetReviewsByProductIdParams) ([]Review, error)
 	GetSaleById(ctx context.Context, id int32) (Sale, error)
+	GetSession(ctx context.Context, id uuid.UUID) (Session, error)
+	GetSessionForUpdate(ctx context.Context, id uuid.UUID) (Session, error)
+	GetUserAddress(ctx context.Context, arg GetUserAddressParams) (UserAddress, error)
 	GetUserByEmail(ctx context.Context, email string) (User, error)
 	GetUserById(ctx context.Context, id int32) (User, error)
+	GetUserMfa(ctx context.Context, userID int32) (UserMfa, error)
 	GetWishlistItemById(ctx context.Context, id int32) (Wishlist, error)
 	GetWishlistItemsByUserId(ctx context.Context, arg GetWishlistItemsByUserIdParams) ([]Wishlist, error)
+	IsTokenRevoked(ctx context.Context, arg IsTokenRevokedParams) (bool, error)
+	LinkReturnRefund(ctx context.Context, arg LinkReturnRefundParams) (Return, error)
+	ListActivePromotions(ctx context.Context) ([]Promotion, error)
+	ListApiKeys(ctx context.Context, arg ListApiKeysParams) ([]ApiKey, error)
+	ListCartItemDetailsByCartId(ctx context.Context, cartID int32) ([]ListCartItemDetailsByCartIdRow, error)
+	ListCartItemsByCartId(ctx context.Context, cartID int32) ([]CartItem, error)
 	ListCategories(ctx context.Context, arg ListCategoriesParams) ([]Category, error)
+	ListCouponCategoryIds(ctx context.Context, couponID int32) ([]int32, error)
+	ListCouponProductIds(ctx context.Context, couponID int32) ([]int32, error)
+	ListCoupons(ctx context.Context, arg ListCouponsParams) ([]Coupon, error)
+	ListLoginFailures(ctx context.Context, arg ListLoginFailuresParams) ([]LoginFailure, error)
+	ListOpenReturnedQuantitiesByOrderId(ctx context.Context, orderID int32) ([]ListOpenReturnedQuantitiesByOrderIdRow, error)
+	ListOrderItemDetailsByOrderId(ctx context.Context, orderID int32) ([]ListOrderItemDetailsByOrderIdRow, error)
 	ListOrderItems(ctx context.Context, arg ListOrderItemsParams) ([]OrderItem, error)
+	ListOrderStatusHistory(ctx context.Context, orderID int32) ([]OrderStatusHistory, error)
 	ListOrders(ctx context.Context, arg ListOrdersParams) ([]Order, error)
+	ListPaymentsByOrderId(ctx context.Context, orderID int32) ([]Payment, error)
 	ListProductVariants(ctx context.Context, arg ListProductVariantsParams) ([]ProductVariant, error)
 	ListProducts(ctx context.Context, arg ListProductsParams) ([]Product, error)
+	ListPromotions(ctx context.Context, arg ListPromotionsParams) ([]Promotion, error)
+	ListRefundItemsByOrderId(ctx context.Context, orderID int32) ([]RefundItem, error)
+	ListRefundedQuantitiesByOrderId(ctx context.Context, orderID int32) ([]ListRefundedQuantitiesByOrderIdRow, error)
+	ListRefundsByOrderId(ctx context.Context, orderID int32) ([]Refund, error)
+	ListReturnItemsByReturnId(ctx context.Context, returnID int32) ([]ReturnItem, error)
+	ListReturns(ctx context.Context, arg ListReturnsParams) ([]Return, error)
+	ListReturnsByOrderId(ctx context.Context, orderID int32) ([]Return, error)
+	ListReturnsByStatus(ctx context.Context, arg ListReturnsByStatusParams) ([]Return, error)
+	ListReturnsByUserId(ctx context.Context, arg ListReturnsByUserIdParams) ([]Return, error)
 	ListReviews(ctx context.Context, arg ListReviewsParams) ([]Review, error)
 	ListSales(ctx context.Context, arg ListSalesParams) ([]Sale, error)
+	ListUserAddresses(ctx context.Context, userID int32) ([]UserAddress, error)
 	ListUsers(ctx context.Context, arg ListUsersParams) ([]User, error)
 	ListWishlistItems(ctx context.Context, arg ListWishlistItemsParams) ([]Wishlist, error)
+	LockLoginFailure(ctx context.Context, arg LockLoginFailureParams) (LoginFailure, error)
+	MarkPaymentCaptured(ctx context.Context, arg MarkPaymentCapturedParams) (Payment, error)
+	MarkReturnRefunded(ctx context.Context, arg MarkReturnRefundedParams) (Return, error)
+	MarkUserEmailVerified(ctx context.Context, id int32) (User, error)
+	ProductVariantHasOrders(ctx context.Context, productVariantID int32) (bool, error)
+	RecordLoginFailure(ctx context.Context, arg RecordLoginFailureParams) (LoginFailure, error)
+	RevokeApiKey(ctx context.Context, id int32) (ApiKey, error)
+	TouchApiKey(ctx context.Context, id int32) error
+	UpdateCartItemQuantity(ctx context.Context, arg UpdateCartItemQuantityParams) (CartItem, error)
 	UpdateCategory(ctx context.Context, arg UpdateCategoryParams) (Category, error)
-	UpdateOrder(ctx context.Context, arg UpdateOrderParams) (Order, error)
+	UpdateCoupon(ctx context.Context, arg UpdateCouponParams) (Coupon, error)
+	UpdateOrderAddresses(ctx context.Context, arg UpdateOrderAddressesParams) (Order, error)
 	UpdateOrderItem(ctx context.Context, arg UpdateOrderItemParams) (OrderItem, error)
+	UpdateOrderStatus(ctx context.Context, arg UpdateOrderStatusParams) (Order, error)
+	UpdatePaymentStatus(ctx context.Context, arg UpdatePaymentStatusParams) (Payment, error)
 	UpdateProduct(ctx context.Context, arg UpdateProductParams) (Product, error)
 	UpdateProductVariant(ctx context.Context, arg UpdateProductVariantParams) (ProductVariant, error)
+	UpdatePromotion(ctx context.Context, arg UpdatePromotionParams) (Promotion, error)
+	UpdateRefundStatus(ctx context.Context, arg UpdateRefundStatusParams) (Refund, error)
+	UpdateReturnStatus(ctx context.Context, arg UpdateReturnStatusParams) (Return, error)
 	UpdateReview(ctx context.Context, arg UpdateReviewParams) (Review, error)
 	UpdateSale(ctx context.Context, arg UpdateSaleParams) (Sale, error)
 	UpdateUser(ctx context.Context, arg UpdateUserParams) (User, error)
+	UpdateUserAddress(ctx context.Context, arg UpdateUserAddressParams) (UserAddress, error)
 	UpdateUserPassword(ctx context.Context, arg UpdateUserPasswordParams) (User, error)
+	UpdateUserRole(ctx context.Context, arg UpdateUserRoleParams) (User, error)
 	UpdateWishlistItem(ctx context.Context, arg UpdateWishlistItemParams) (Wishlist, error)
+	UpsertUserMfa(ctx context.Context, arg UpsertUserMfaParams) (UserMfa, error)
+	UseMfaRecoveryCode(ctx context.Context, arg UseMfaRecoveryCodeParams) (int64, error)
+	UseUserMfaStep(ctx context.Context, arg UseUserMfaStepParams) (int64, error)
 }
 
 var _ Querier = (*Queries)(nil)
@@ -1,11 +1,39 @@
 package sqlc
 
 import (
+	"context"
 	"database/sql"
+	"fmt"
 )
 
 type Store interface {
 	Querier
+	CheckoutTx(ctx context.Context, arg CheckoutTxParams) (CheckoutTxResult, error)
+	MergeCartTx(ctx context.Context, arg MergeCartTxParams) (Cart, error)
+	CartCheckoutTx(ctx context.Context, arg CartCheckoutTxParams) (CheckoutTxResult, error)
+	UpdateOrderStatusTx(ctx context.Context, arg UpdateOrderStatusTxParams) (Order, error)
+	CancelOrderTx(ctx context.Context, arg CancelOrderTxParams) (Order, error)
+	UpdateOrderAddressesTx(ctx context.Context, arg UpdateOrderAddressesParams) (Order, error)
+	DeleteProductVariantTx(ctx context.Context, id int32) (DeleteProductVariantTxResult, error)
+	CapturePaymentTx(ctx context.Context, arg CapturePaymentTxParams) (CapturePaymentTxResult, error)
+	CreateRefundTx(ctx context.Context, arg CreateRefundTxParams) (CreateRefundTxResult, error)
+	CompleteRefundTx(ctx context.Context, arg CompleteRefundTxParams) (CompleteRefundTxResult, error)
+	FailRefundTx(ctx context.Context, refundID int32, reason string) (Refund, error)
+	CreateReturnTx(ctx context.Context, arg CreateReturnTxParams) (ReturnTxResult, error)
+	UpdateReturnStatusTx(ctx context.Context, arg UpdateReturnStatusTxParams) (Return, error)
+	ReceiveReturnTx(ctx context.Context, arg ReceiveReturnTxParams) (ReturnTxResult, error)
+	CreateCouponTx(ctx context.Context, arg CreateCouponTxParams) (CouponTxResult, error)
+	UpdateCouponTx(ctx context.Context, arg UpdateCouponTxParams) (CouponTxResult, error)
+	RotateSessionTx(ctx context.Context, arg RotateSessionTxParams) (Session, error)
+	CreatePasswordResetTx(ctx context.Context, arg CreatePasswordResetTxParams) (CreatePasswordResetRow, error)
+	ResetPasswordTx(ctx context.Context, arg ResetPasswordTxParams) (User, error)
+	UpdateUserRoleTx(ctx context.Context, arg UpdateUserRoleParams) (User, error)
+	CreateEmailVerificationTx(ctx context.Context, arg CreateEmailVerificationParams) (EmailVerification, error)
+	VerifyEmailTx(ctx context.Context, verificationToken string) (User, error)
+	EnableMfaTx(ctx context.Context, arg EnableMfaTxParams) (UserMfa, error)
+	ReplaceMfaRecoveryCodesTx(ctx context.Context, userID int32, codeHashes []string) error
+	CreateUserAddressTx(ctx context.Context, arg CreateUserAddressParams) (UserAddress, error)
+	UpdateUserAddressTx(ctx context.Context, arg UpdateUserAddressParams) (UserAddress, error)
 }
 
 type SQLStore struct {
@@ -19,3 +47,22 @@ func NewStore(db *sql.DB) Store {
 		db:      db,
 	}
 }
+
+// ExecTx runs fn inside a database transaction and rolls it back if fn returns an error
+func (store *SQLStore) ExecTx(ctx context.Context, fn func(*Queries) error) error {
+	tx, err := store.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	q := New(tx)
+	err = fn(q)
+	if err != nil {
+		if rbErr := tx.Rollback(); rbErr != nil {
+			return fmt.Errorf("tx err: %v, rb err: %v", err, rbErr)
+		}
+		return err
+	}
+
+	return tx.Commit()
+}
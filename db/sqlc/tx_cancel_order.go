@@ -0,0 +1,74 @@
+package sqlc
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/cihanalici/api/util"
+)
+
+type CancelOrderTxParams struct {
+	OrderID     int32  `json:"order_id"`
+	CancelledBy int32  `json:"cancelled_by"`
+	Reason      string `json:"reason"`
+	// AllowedStatuses lists the statuses the caller may cancel the order from
+	AllowedStatuses []string `json:"allowed_statuses"`
+}
+
+// CancelOrderTx marks an order cancelled, records the reason in the status
+// history and returns the ordered quantities to product_variants.stock.
+// Quantities that were already refunded are left out, their stock was dealt
+// with by the refund. The payment is not touched, see the cancel handler.
+func (store *SQLStore) CancelOrderTx(ctx context.Context, arg CancelOrderTxParams) (Order, error) {
+	var result Order
+
+	err := store.ExecTx(ctx, func(q *Queries) error {
+		order, err := q.GetOrderForUpdate(ctx, arg.OrderID)
+		if err != nil {
+			return err
+		}
+
+		allowed := false
+		for _, status := range arg.AllowedStatuses {
+			if order.Status == status {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return fmt.Errorf("%w: cannot cancel a %s order", util.ErrInvalidTransition, order.Status)
+		}
+
+		result, err = transitionOrder(ctx, q, UpdateOrderStatusTxParams{
+			OrderID:   order.ID,
+			Status:    util.OrderStatusCancelled,
+			ChangedBy: arg.CancelledBy,
+			Note:      arg.Reason,
+		})
+		if err != nil {
+			return err
+		}
+
+		items, err := q.GetAllOrderItemsByOrderId(ctx, order.ID)
+		if err != nil {
+			return err
+		}
+
+		refunded, err := q.ListRefundedQuantitiesByOrderId(ctx, order.ID)
+		if err != nil {
+			return err
+		}
+
+		quantities := make(map[int32]int32, len(items))
+		for _, item := range items {
+			quantities[item.ID] = item.Quantity
+		}
+		for _, row := range refunded {
+			quantities[row.OrderItemID] -= row.Quantity
+		}
+
+		return restockOrderItems(ctx, q, order.ID, quantities)
+	})
+
+	return result, err
+}
@@ -0,0 +1,127 @@
+package sqlc
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+)
+
+// MaxCartItemQuantity caps the quantity of a cart line, adding to a line
+// never takes it above
+const MaxCartItemQuantity = 100
+
+type MergeCartTxParams struct {
+	UserID     int32  `json:"user_id"`
+	GuestToken string `json:"guest_token"`
+}
+
+// MergeCartTx moves the items of the guest cart identified by GuestToken into
+// the user's cart. If the user has no cart yet the guest cart is simply
+// assigned to them. A missing guest cart is not an error and leaves the
+// result empty.
+func (store *SQLStore) MergeCartTx(ctx context.Context, arg MergeCartTxParams) (Cart, error) {
+	var result Cart
+
+	err := store.ExecTx(ctx, func(q *Queries) error {
+		userID := sql.NullInt32{Int32: arg.UserID, Valid: true}
+
+		guestCart, err := q.GetCartByToken(ctx, sql.NullString{String: arg.GuestToken, Valid: true})
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		guestCart, err = q.GetCartForUpdate(ctx, guestCart.ID)
+		if err != nil {
+			return err
+		}
+
+		userCart, err := q.GetCartByUserId(ctx, userID)
+		if errors.Is(err, sql.ErrNoRows) {
+			result, err = q.AssignCartToUser(ctx, AssignCartToUserParams{
+				ID:     guestCart.ID,
+				UserID: userID,
+			})
+			return err
+		}
+		if err != nil {
+			return err
+		}
+
+		items, err := q.ListCartItemsByCartId(ctx, guestCart.ID)
+		if err != nil {
+			return err
+		}
+
+		for _, item := range items {
+			_, err = q.AddCartItem(ctx, AddCartItemParams{
+				CartID:           userCart.ID,
+				ProductVariantID: item.ProductVariantID,
+				Quantity:         item.Quantity,
+				MaxQuantity:      MaxCartItemQuantity,
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		result = userCart
+		return q.DeleteCart(ctx, guestCart.ID)
+	})
+
+	return result, err
+}
+
+type CartCheckoutTxParams struct {
+	CartID          int32           `json:"cart_id"`
+	UserID          int32           `json:"user_id"`
+	ShippingAddress json.RawMessage `json:"shipping_address"`
+	BillingAddress  json.RawMessage `json:"billing_address"`
+	ShippingAmount  string          `json:"shipping_amount"`
+	CouponCode      string          `json:"coupon_code"`
+}
+
+// CartCheckoutTx turns the content of a cart into an order and empties the
+// cart in the same transaction
+func (store *SQLStore) CartCheckoutTx(ctx context.Context, arg CartCheckoutTxParams) (CheckoutTxResult, error) {
+	var result CheckoutTxResult
+
+	err := store.ExecTx(ctx, func(q *Queries) error {
+		cart, err := q.GetCartForUpdate(ctx, arg.CartID)
+		if err != nil {
+			return err
+		}
+
+		cartItems, err := q.ListCartItemsByCartId(ctx, cart.ID)
+		if err != nil {
+			return err
+		}
+
+		items := make([]CheckoutItem, len(cartItems))
+		for i, cartItem := range cartItems {
+			items[i] = CheckoutItem{
+				ProductVariantID: cartItem.ProductVariantID,
+				Quantity:         cartItem.Quantity,
+			}
+		}
+
+		result, err = checkout(ctx, q, CheckoutTxParams{
+			UserID:          arg.UserID,
+			Items:           items,
+			ShippingAddress: arg.ShippingAddress,
+			BillingAddress:  arg.BillingAddress,
+			ShippingAmount:  arg.ShippingAmount,
+			CouponCode:      arg.CouponCode,
+		})
+		if err != nil {
+			return err
+		}
+
+		return q.DeleteCartItemsByCartId(ctx, cart.ID)
+	})
+
+	return result, err
+}
@@ -0,0 +1,267 @@
+package sqlc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/cihanalici/api/util"
+)
+
+type CheckoutItem struct {
+	ProductVariantID int32 `json:"product_variant_id"`
+	Quantity         int32 `json:"quantity"`
+}
+
+type CheckoutTxParams struct {
+	UserID int32          `json:"user_id"`
+	Items  []CheckoutItem `json:"items"`
+	// ShippingAddress and BillingAddress are stored on the order as they are
+	ShippingAddress json.RawMessage `json:"shipping_address"`
+	BillingAddress  json.RawMessage `json:"billing_address"`
+	// ShippingAmount is charged on top of the items unless a free shipping
+	// coupon waives it
+	ShippingAmount string `json:"shipping_amount"`
+	// CouponCode is optional, its discount is spread over the eligible lines
+	// after the automatic promotions
+	CouponCode string `json:"coupon_code"`
+}
+
+type CheckoutTxResult struct {
+	Order      Order       `json:"order"`
+	OrderItems []OrderItem `json:"order_items"`
+}
+
+// checkoutLine is an ordered variant priced from the locked product_variants row
+type checkoutLine struct {
+	variant     ProductVariant
+	productName string
+	categoryID  int32
+	quantity    int32
+	unitPrice   int64
+	// discount is what promotions and the coupon take off the whole line,
+	// discounts explains it
+	discount  int64
+	discounts []OrderItemDiscount
+}
+
+// amount is what the line costs before discounts
+func (line checkoutLine) amount() int64 {
+	return line.unitPrice * int64(line.quantity)
+}
+
+// paid is what is left to pay on the line after its discount
+func (line checkoutLine) paid() int64 {
+	return line.amount() - line.discount
+}
+
+// CheckoutTx creates a pending order with its items and decrements the stock
+// of every ordered variant in a single transaction. Line prices and order
+// totals are computed from product_variants.price; nothing is written if any
+// variant does not have enough stock.
+func (store *SQLStore) CheckoutTx(ctx context.Context, arg CheckoutTxParams) (CheckoutTxResult, error) {
+	var result CheckoutTxResult
+
+	err := store.ExecTx(ctx, func(q *Queries) error {
+		var err error
+		result, err = checkout(ctx, q, arg)
+		return err
+	})
+
+	return result, err
+}
+
+// checkout runs the checkout steps with the given transactional queries
+func checkout(ctx context.Context, q *Queries, arg CheckoutTxParams) (CheckoutTxResult, error) {
+	var result CheckoutTxResult
+
+	items, err := mergeCheckoutItems(arg.Items)
+	if err != nil {
+		return result, err
+	}
+
+	lines := make([]checkoutLine, 0, len(items))
+
+	for _, item := range items {
+		variant, err := q.GetProductVariantForUpdate(ctx, item.ProductVariantID)
+		if err != nil {
+			return result, err
+		}
+
+		if variant.ArchivedAt.Valid {
+			return result, fmt.Errorf("%w: product variant %d", util.ErrVariantUnavailable, variant.ID)
+		}
+
+		if variant.Stock < item.Quantity {
+			return result, fmt.Errorf("%w: product variant %d", util.ErrInsufficientStock, variant.ID)
+		}
+
+		unitPrice, err := util.ParseMoney(variant.Price)
+		if err != nil {
+			return result, err
+		}
+
+		product, err := q.GetProductById(ctx, variant.ProductID)
+		if err != nil {
+			return result, err
+		}
+
+		lines = append(lines, checkoutLine{
+			variant:     variant,
+			productName: product.Name,
+			categoryID:  product.CategoryID,
+			quantity:    item.Quantity,
+			unitPrice:   unitPrice,
+			discounts:   []OrderItemDiscount{},
+		})
+	}
+
+	var subtotal int64
+	for _, line := range lines {
+		subtotal += line.amount()
+	}
+
+	var shippingFee int64
+	if arg.ShippingAmount != "" {
+		shippingFee, err = util.ParseMoney(arg.ShippingAmount)
+		if err != nil {
+			return result, err
+		}
+	}
+	shipping := shippingFee
+
+	if err := applyPromotions(ctx, q, lines); err != nil {
+		return result, err
+	}
+
+	var promotionDiscount int64
+	for _, line := range lines {
+		promotionDiscount += line.discount
+	}
+
+	var coupon Coupon
+	if arg.CouponCode != "" {
+		var freeShipping bool
+		coupon, freeShipping, err = applyCoupon(ctx, q, arg.CouponCode, arg.UserID, subtotal, lines)
+		if err != nil {
+			return result, err
+		}
+		if freeShipping {
+			shipping = 0
+		}
+	}
+
+	var discount int64
+	for _, line := range lines {
+		discount += line.discount
+	}
+
+	result.Order, err = q.CreateOrder(ctx, CreateOrderParams{
+		UserID:          arg.UserID,
+		SubtotalAmount:  util.FormatMoney(subtotal),
+		TotalAmount:     util.FormatMoney(subtotal - discount + shipping),
+		Status:          util.OrderStatusPending,
+		ShippingAddress: arg.ShippingAddress,
+		BillingAddress:  arg.BillingAddress,
+		DiscountAmount:  util.FormatMoney(discount),
+		ShippingAmount:  util.FormatMoney(shipping),
+		CouponCode:      coupon.Code,
+	})
+	if err != nil {
+		return result, err
+	}
+
+	if coupon.ID != 0 {
+		// a waived shipping fee counts as what the coupon saved
+		saved := discount - promotionDiscount + shippingFee - shipping
+
+		_, err = q.CreateCouponRedemption(ctx, CreateCouponRedemptionParams{
+			CouponID:       coupon.ID,
+			UserID:         arg.UserID,
+			OrderID:        result.Order.ID,
+			DiscountAmount: util.FormatMoney(saved),
+		})
+		if err != nil {
+			return result, err
+		}
+	}
+
+	_, err = q.CreateOrderStatusHistory(ctx, CreateOrderStatusHistoryParams{
+		OrderID:   result.Order.ID,
+		ToStatus:  result.Order.Status,
+		ChangedBy: util.ToInt32ToNullInt32(arg.UserID),
+		Note:      "order placed",
+	})
+	if err != nil {
+		return result, err
+	}
+
+	for _, line := range lines {
+		discounts, err := json.Marshal(line.discounts)
+		if err != nil {
+			return result, err
+		}
+
+		_, err = q.AddProductVariantStock(ctx, AddProductVariantStockParams{
+			Amount: -line.quantity,
+			ID:     line.variant.ID,
+		})
+		if err != nil {
+			return result, err
+		}
+
+		orderItem, err := q.CreateOrderItem(ctx, CreateOrderItemParams{
+			OrderID:          result.Order.ID,
+			ProductVariantID: line.variant.ID,
+			Quantity:         line.quantity,
+			Price:            util.FormatMoney(line.unitPrice),
+			ProductName:      line.productName,
+			Color:            line.variant.Color,
+			Size:             line.variant.Size,
+			Sku:              line.variant.Sku,
+			DiscountAmount:   util.FormatMoney(line.discount),
+			Discounts:        discounts,
+		})
+		if err != nil {
+			return result, err
+		}
+
+		result.OrderItems = append(result.OrderItems, orderItem)
+	}
+
+	return result, nil
+}
+
+// mergeCheckoutItems validates the requested items, folds duplicate variants
+// into one line and sorts them by variant id so that concurrent checkouts lock
+// rows in the same order and cannot deadlock.
+func mergeCheckoutItems(items []CheckoutItem) ([]CheckoutItem, error) {
+	if len(items) == 0 {
+		return nil, util.ErrEmptyOrder
+	}
+
+	quantities := make(map[int32]int32, len(items))
+	merged := make([]CheckoutItem, 0, len(items))
+
+	for _, item := range items {
+		if item.Quantity <= 0 {
+			return nil, fmt.Errorf("%w: product variant %d", util.ErrInvalidQuantity, item.ProductVariantID)
+		}
+
+		if _, ok := quantities[item.ProductVariantID]; !ok {
+			merged = append(merged, CheckoutItem{ProductVariantID: item.ProductVariantID})
+		}
+		quantities[item.ProductVariantID] += item.Quantity
+	}
+
+	for i := range merged {
+		merged[i].Quantity = quantities[merged[i].ProductVariantID]
+	}
+
+	sort.Slice(merged, func(i, j int) bool {
+		return merged[i].ProductVariantID < merged[j].ProductVariantID
+	})
+
+	return merged, nil
+}
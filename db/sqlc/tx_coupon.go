@@ -0,0 +1,218 @@
+package sqlc
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/cihanalici/api/util"
+)
+
+// NormalizeCouponCode is how coupon codes are stored and looked up, codes
+// are not case sensitive
+func NormalizeCouponCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// applyCoupon locks the coupon so concurrent checkouts count its uses one
+// after the other, checks that userID may redeem it on the lines and adds
+// its discount to every eligible line. The discount is computed from what
+// is left to pay after promotions. It reports whether shipping is free.
+func applyCoupon(ctx context.Context, q *Queries, code string, userID int32, subtotal int64, lines []checkoutLine) (Coupon, bool, error) {
+	coupon, err := q.GetCouponByCodeForUpdate(ctx, NormalizeCouponCode(code))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return coupon, false, fmt.Errorf("%w: %q", util.ErrInvalidCoupon, code)
+		}
+		return coupon, false, err
+	}
+
+	if !coupon.IsActive {
+		return coupon, false, fmt.Errorf("%w: %q", util.ErrInvalidCoupon, code)
+	}
+
+	now := time.Now()
+	if coupon.StartsAt.Valid && now.Before(coupon.StartsAt.Time) {
+		return coupon, false, fmt.Errorf("%w: not valid before %s", util.ErrCouponNotApplicable, coupon.StartsAt.Time.Format(time.RFC3339))
+	}
+	if coupon.EndsAt.Valid && !now.Before(coupon.EndsAt.Time) {
+		return coupon, false, fmt.Errorf("%w: expired", util.ErrCouponNotApplicable)
+	}
+
+	minOrder, err := util.ParseMoney(coupon.MinOrderAmount)
+	if err != nil {
+		return coupon, false, err
+	}
+	if subtotal < minOrder {
+		return coupon, false, fmt.Errorf("%w: needs an order of at least %s", util.ErrCouponNotApplicable, coupon.MinOrderAmount)
+	}
+
+	// redemptions of cancelled orders give the use back
+	if coupon.UsageLimit.Valid {
+		used, err := q.CountCouponRedemptions(ctx, coupon.ID)
+		if err != nil {
+			return coupon, false, err
+		}
+		if used >= int64(coupon.UsageLimit.Int32) {
+			return coupon, false, fmt.Errorf("%w: usage limit reached", util.ErrCouponNotApplicable)
+		}
+	}
+
+	if coupon.PerUserLimit.Valid {
+		used, err := q.CountCouponRedemptionsByUser(ctx, CountCouponRedemptionsByUserParams{
+			CouponID: coupon.ID,
+			UserID:   userID,
+		})
+		if err != nil {
+			return coupon, false, err
+		}
+		if used >= int64(coupon.PerUserLimit.Int32) {
+			return coupon, false, fmt.Errorf("%w: already used", util.ErrCouponNotApplicable)
+		}
+	}
+
+	productIDs, err := q.ListCouponProductIds(ctx, coupon.ID)
+	if err != nil {
+		return coupon, false, err
+	}
+
+	categoryIDs, err := q.ListCouponCategoryIds(ctx, coupon.ID)
+	if err != nil {
+		return coupon, false, err
+	}
+
+	unrestricted := len(productIDs) == 0 && len(categoryIDs) == 0
+
+	var eligible []int
+	var amounts []int64
+	var eligibleTotal int64
+	for i, line := range lines {
+		if unrestricted || slices.Contains(productIDs, line.variant.ProductID) || slices.Contains(categoryIDs, line.categoryID) {
+			eligible = append(eligible, i)
+			amounts = append(amounts, line.paid())
+			eligibleTotal += line.paid()
+		}
+	}
+	if len(eligible) == 0 {
+		return coupon, false, fmt.Errorf("%w: none of the items qualify", util.ErrCouponNotApplicable)
+	}
+
+	if coupon.Type == util.CouponTypeFreeShipping {
+		return coupon, true, nil
+	}
+
+	value, err := util.ParseMoney(coupon.Value)
+	if err != nil {
+		return coupon, false, err
+	}
+
+	discount := util.CouponDiscount(coupon.Type, value, eligibleTotal)
+	for i, share := range util.AllocateDiscount(discount, amounts) {
+		if share <= 0 {
+			continue
+		}
+
+		line := &lines[eligible[i]]
+		line.discount += share
+		line.discounts = append(line.discounts, OrderItemDiscount{
+			CouponCode:  coupon.Code,
+			Description: coupon.Description,
+			Amount:      util.FormatMoney(share),
+		})
+	}
+
+	return coupon, false, nil
+}
+
+// CouponTxResult is a coupon with the products and categories it is
+// restricted to
+type CouponTxResult struct {
+	Coupon      Coupon  `json:"coupon"`
+	ProductIDs  []int32 `json:"product_ids"`
+	CategoryIDs []int32 `json:"category_ids"`
+}
+
+type CreateCouponTxParams struct {
+	CreateCouponParams
+	ProductIDs  []int32 `json:"product_ids"`
+	CategoryIDs []int32 `json:"category_ids"`
+}
+
+// CreateCouponTx creates a coupon with its product and category restrictions
+func (store *SQLStore) CreateCouponTx(ctx context.Context, arg CreateCouponTxParams) (CouponTxResult, error) {
+	var result CouponTxResult
+
+	err := store.ExecTx(ctx, func(q *Queries) error {
+		coupon, err := q.CreateCoupon(ctx, arg.CreateCouponParams)
+		if err != nil {
+			return err
+		}
+
+		result, err = setCouponRestrictions(ctx, q, coupon, arg.ProductIDs, arg.CategoryIDs)
+		return err
+	})
+
+	return result, err
+}
+
+type UpdateCouponTxParams struct {
+	UpdateCouponParams
+	ProductIDs  []int32 `json:"product_ids"`
+	CategoryIDs []int32 `json:"category_ids"`
+}
+
+// UpdateCouponTx replaces a coupon and its restrictions
+func (store *SQLStore) UpdateCouponTx(ctx context.Context, arg UpdateCouponTxParams) (CouponTxResult, error) {
+	var result CouponTxResult
+
+	err := store.ExecTx(ctx, func(q *Queries) error {
+		coupon, err := q.UpdateCoupon(ctx, arg.UpdateCouponParams)
+		if err != nil {
+			return err
+		}
+
+		if err := q.DeleteCouponProducts(ctx, coupon.ID); err != nil {
+			return err
+		}
+		if err := q.DeleteCouponCategories(ctx, coupon.ID); err != nil {
+			return err
+		}
+
+		result, err = setCouponRestrictions(ctx, q, coupon, arg.ProductIDs, arg.CategoryIDs)
+		return err
+	})
+
+	return result, err
+}
+
+// setCouponRestrictions restricts a coupon to the products and categories
+func setCouponRestrictions(ctx context.Context, q *Queries, coupon Coupon, productIDs, categoryIDs []int32) (CouponTxResult, error) {
+	result := CouponTxResult{Coupon: coupon}
+
+	for _, id := range productIDs {
+		err := q.AddCouponProduct(ctx, AddCouponProductParams{CouponID: coupon.ID, ProductID: id})
+		if err != nil {
+			return result, err
+		}
+	}
+
+	for _, id := range categoryIDs {
+		err := q.AddCouponCategory(ctx, AddCouponCategoryParams{CouponID: coupon.ID, CategoryID: id})
+		if err != nil {
+			return result, err
+		}
+	}
+
+	var err error
+	result.ProductIDs, err = q.ListCouponProductIds(ctx, coupon.ID)
+	if err != nil {
+		return result, err
+	}
+
+	result.CategoryIDs, err = q.ListCouponCategoryIds(ctx, coupon.ID)
+	return result, err
+}
@@ -0,0 +1,51 @@
+package sqlc
+
+import (
+	"context"
+	"time"
+
+	"github.com/cihanalici/api/util"
+)
+
+// CreateEmailVerificationTx stores a new verification token for the user and
+// invalidates every token sent before it
+func (store *SQLStore) CreateEmailVerificationTx(ctx context.Context, arg CreateEmailVerificationParams) (EmailVerification, error) {
+	var result EmailVerification
+
+	err := store.ExecTx(ctx, func(q *Queries) error {
+		if err := q.DeleteEmailVerificationsByUserId(ctx, arg.UserID); err != nil {
+			return err
+		}
+
+		var err error
+		result, err = q.CreateEmailVerification(ctx, arg)
+		return err
+	})
+
+	return result, err
+}
+
+// VerifyEmailTx consumes a verification token and marks the user's email as verified
+func (store *SQLStore) VerifyEmailTx(ctx context.Context, verificationToken string) (User, error) {
+	var result User
+
+	err := store.ExecTx(ctx, func(q *Queries) error {
+		verification, err := q.GetEmailVerificationByTokenForUpdate(ctx, verificationToken)
+		if err != nil {
+			return err
+		}
+
+		if time.Now().After(verification.ExpiresAt) {
+			return util.ErrExpiredToken
+		}
+
+		result, err = q.MarkUserEmailVerified(ctx, verification.UserID)
+		if err != nil {
+			return err
+		}
+
+		return q.DeleteEmailVerificationsByUserId(ctx, verification.UserID)
+	})
+
+	return result, err
+}
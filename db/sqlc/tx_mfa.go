@@ -0,0 +1,59 @@
+package sqlc
+
+import (
+	"context"
+)
+
+type EnableMfaTxParams struct {
+	UserID int32 `json:"user_id"`
+	// Step is the TOTP time step of the code that confirmed the enrollment
+	Step               int64    `json:"step"`
+	RecoveryCodeHashes []string `json:"recovery_code_hashes"`
+}
+
+// EnableMfaTx turns on two-factor authentication for a confirmed enrollment
+// and stores its recovery codes
+func (store *SQLStore) EnableMfaTx(ctx context.Context, arg EnableMfaTxParams) (UserMfa, error) {
+	var result UserMfa
+
+	err := store.ExecTx(ctx, func(q *Queries) error {
+		var err error
+		result, err = q.EnableUserMfa(ctx, EnableUserMfaParams{
+			UserID:       arg.UserID,
+			LastUsedStep: arg.Step,
+		})
+		if err != nil {
+			return err
+		}
+
+		return replaceMfaRecoveryCodes(ctx, q, arg.UserID, arg.RecoveryCodeHashes)
+	})
+
+	return result, err
+}
+
+// ReplaceMfaRecoveryCodesTx invalidates every recovery code of the user and
+// stores a new set
+func (store *SQLStore) ReplaceMfaRecoveryCodesTx(ctx context.Context, userID int32, codeHashes []string) error {
+	return store.ExecTx(ctx, func(q *Queries) error {
+		return replaceMfaRecoveryCodes(ctx, q, userID, codeHashes)
+	})
+}
+
+func replaceMfaRecoveryCodes(ctx context.Context, q *Queries, userID int32, codeHashes []string) error {
+	if err := q.DeleteMfaRecoveryCodes(ctx, userID); err != nil {
+		return err
+	}
+
+	for _, codeHash := range codeHashes {
+		err := q.CreateMfaRecoveryCode(ctx, CreateMfaRecoveryCodeParams{
+			UserID:   userID,
+			CodeHash: codeHash,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
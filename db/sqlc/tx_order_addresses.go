@@ -0,0 +1,41 @@
+package sqlc
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/cihanalici/api/util"
+)
+
+// orderAddressStatuses are the statuses in which the address snapshots of an
+// order may still be corrected, once it is fulfilled the parcel is on its way
+var orderAddressStatuses = []string{util.OrderStatusPending, util.OrderStatusPaid}
+
+// UpdateOrderAddressesTx replaces the shipping and billing snapshots of an
+// order that has not been fulfilled yet
+func (store *SQLStore) UpdateOrderAddressesTx(ctx context.Context, arg UpdateOrderAddressesParams) (Order, error) {
+	var result Order
+
+	err := store.ExecTx(ctx, func(q *Queries) error {
+		order, err := q.GetOrderForUpdate(ctx, arg.ID)
+		if err != nil {
+			return err
+		}
+
+		allowed := false
+		for _, status := range orderAddressStatuses {
+			if order.Status == status {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return fmt.Errorf("%w: order is %s", util.ErrOrderAddressLocked, order.Status)
+		}
+
+		result, err = q.UpdateOrderAddresses(ctx, arg)
+		return err
+	})
+
+	return result, err
+}
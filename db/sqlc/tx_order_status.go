@@ -0,0 +1,68 @@
+package sqlc
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/cihanalici/api/util"
+)
+
+type UpdateOrderStatusTxParams struct {
+	OrderID   int32  `json:"order_id"`
+	Status    string `json:"status"`
+	ChangedBy int32  `json:"changed_by"`
+	Note      string `json:"note"`
+}
+
+// UpdateOrderStatusTx moves an order to a new status if the lifecycle allows
+// it and records the change in order_status_history
+func (store *SQLStore) UpdateOrderStatusTx(ctx context.Context, arg UpdateOrderStatusTxParams) (Order, error) {
+	var result Order
+
+	err := store.ExecTx(ctx, func(q *Queries) error {
+		var err error
+		result, err = transitionOrder(ctx, q, arg)
+		return err
+	})
+
+	return result, err
+}
+
+// transitionOrder locks the order, validates the transition, updates the
+// status and records it in the history
+func transitionOrder(ctx context.Context, q *Queries, arg UpdateOrderStatusTxParams) (Order, error) {
+	if !util.IsSupportedOrderStatus(arg.Status) {
+		return Order{}, fmt.Errorf("%w: %q", util.ErrInvalidOrderStatus, arg.Status)
+	}
+
+	order, err := q.GetOrderForUpdate(ctx, arg.OrderID)
+	if err != nil {
+		return order, err
+	}
+
+	if !util.CanTransitionOrderStatus(order.Status, arg.Status) {
+		return order, fmt.Errorf("%w: %s -> %s", util.ErrInvalidTransition, order.Status, arg.Status)
+	}
+
+	updated, err := q.UpdateOrderStatus(ctx, UpdateOrderStatusParams{
+		ID:     order.ID,
+		Status: arg.Status,
+	})
+	if err != nil {
+		return order, err
+	}
+
+	_, err = q.CreateOrderStatusHistory(ctx, CreateOrderStatusHistoryParams{
+		OrderID:    order.ID,
+		FromStatus: sql.NullString{String: order.Status, Valid: true},
+		ToStatus:   arg.Status,
+		ChangedBy:  util.ToInt32ToNullInt32(arg.ChangedBy),
+		Note:       arg.Note,
+	})
+	if err != nil {
+		return order, err
+	}
+
+	return updated, nil
+}
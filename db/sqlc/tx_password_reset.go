@@ -0,0 +1,70 @@
+package sqlc
+
+import (
+	"context"
+	"time"
+
+	"github.com/cihanalici/api/util"
+)
+
+type CreatePasswordResetTxParams struct {
+	UserID     int32     `json:"user_id"`
+	ResetToken string    `json:"reset_token"`
+	ExpiresAt  time.Time `json:"expires_at"`
+}
+
+// CreatePasswordResetTx stores a new reset token for the user and invalidates
+// every token requested before it
+func (store *SQLStore) CreatePasswordResetTx(ctx context.Context, arg CreatePasswordResetTxParams) (CreatePasswordResetRow, error) {
+	var result CreatePasswordResetRow
+
+	err := store.ExecTx(ctx, func(q *Queries) error {
+		if err := q.DeletePasswordResetsByUserId(ctx, arg.UserID); err != nil {
+			return err
+		}
+
+		var err error
+		result, err = q.CreatePasswordReset(ctx, CreatePasswordResetParams(arg))
+		return err
+	})
+
+	return result, err
+}
+
+type ResetPasswordTxParams struct {
+	ResetToken     string `json:"reset_token"`
+	HashedPassword string `json:"hashed_password"`
+}
+
+// ResetPasswordTx consumes a reset token, sets the new password and blocks
+// every session of the user so stolen refresh tokens stop working
+func (store *SQLStore) ResetPasswordTx(ctx context.Context, arg ResetPasswordTxParams) (User, error) {
+	var result User
+
+	err := store.ExecTx(ctx, func(q *Queries) error {
+		reset, err := q.GetPasswordResetByTokenForUpdate(ctx, arg.ResetToken)
+		if err != nil {
+			return err
+		}
+
+		if time.Now().After(reset.ExpiresAt) {
+			return util.ErrExpiredToken
+		}
+
+		result, err = q.UpdateUserPassword(ctx, UpdateUserPasswordParams{
+			ID:       reset.UserID,
+			Password: arg.HashedPassword,
+		})
+		if err != nil {
+			return err
+		}
+
+		if err := q.DeletePasswordResetsByUserId(ctx, reset.UserID); err != nil {
+			return err
+		}
+
+		return q.BlockUserSessions(ctx, reset.UserID)
+	})
+
+	return result, err
+}
@@ -0,0 +1,60 @@
+package sqlc
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/cihanalici/api/util"
+)
+
+type CapturePaymentTxParams struct {
+	PaymentID      int32  `json:"payment_id"`
+	CapturedAmount string `json:"captured_amount"`
+	ChangedBy      int32  `json:"changed_by"`
+}
+
+type CapturePaymentTxResult struct {
+	Payment Payment `json:"payment"`
+	Order   Order   `json:"order"`
+}
+
+// CapturePaymentTx records a capture the gateway confirmed and marks the
+// order paid. It fails without writing anything if the order left pending
+// meanwhile, the caller then has to give the money back.
+func (store *SQLStore) CapturePaymentTx(ctx context.Context, arg CapturePaymentTxParams) (CapturePaymentTxResult, error) {
+	var result CapturePaymentTxResult
+
+	err := store.ExecTx(ctx, func(q *Queries) error {
+		payment, err := q.GetPayment(ctx, arg.PaymentID)
+		if err != nil {
+			return err
+		}
+
+		order, err := q.GetOrderForUpdate(ctx, payment.OrderID)
+		if err != nil {
+			return err
+		}
+
+		if order.Status != util.OrderStatusPending {
+			return fmt.Errorf("%w: cannot pay a %s order", util.ErrInvalidTransition, order.Status)
+		}
+
+		result.Payment, err = q.MarkPaymentCaptured(ctx, MarkPaymentCapturedParams{
+			ID:             payment.ID,
+			CapturedAmount: arg.CapturedAmount,
+		})
+		if err != nil {
+			return err
+		}
+
+		result.Order, err = transitionOrder(ctx, q, UpdateOrderStatusTxParams{
+			OrderID:   order.ID,
+			Status:    util.OrderStatusPaid,
+			ChangedBy: arg.ChangedBy,
+			Note:      fmt.Sprintf("payment %d captured", payment.ID),
+		})
+		return err
+	})
+
+	return result, err
+}
@@ -0,0 +1,42 @@
+package sqlc
+
+import (
+	"context"
+)
+
+type DeleteProductVariantTxResult struct {
+	// Archived is true if the variant was ordered before and was archived
+	// instead of deleted; Variant is only set in that case
+	Archived bool           `json:"archived"`
+	Variant  ProductVariant `json:"variant"`
+}
+
+// DeleteProductVariantTx deletes a variant that was never ordered and
+// archives one that was, so that order items keep pointing to it
+func (store *SQLStore) DeleteProductVariantTx(ctx context.Context, id int32) (DeleteProductVariantTxResult, error) {
+	var result DeleteProductVariantTxResult
+
+	err := store.ExecTx(ctx, func(q *Queries) error {
+		// the lock makes a concurrent checkout of the variant wait, it
+		// either sees the variant archived or has written its order item
+		variant, err := q.GetProductVariantForUpdate(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		ordered, err := q.ProductVariantHasOrders(ctx, variant.ID)
+		if err != nil {
+			return err
+		}
+
+		if !ordered {
+			return q.DeleteProductVariant(ctx, variant.ID)
+		}
+
+		result.Archived = true
+		result.Variant, err = q.ArchiveProductVariant(ctx, variant.ID)
+		return err
+	})
+
+	return result, err
+}
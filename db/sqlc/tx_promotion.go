@@ -0,0 +1,91 @@
+package sqlc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/cihanalici/api/promotions"
+	"github.com/cihanalici/api/util"
+)
+
+// OrderItemDiscount explains a part of an order item's discount_amount, it
+// was given either by a promotion or by the coupon of the order
+type OrderItemDiscount struct {
+	PromotionID int32  `json:"promotion_id,omitempty"`
+	CouponCode  string `json:"coupon_code,omitempty"`
+	Name        string `json:"name,omitempty"`
+	Description string `json:"description"`
+	Amount      string `json:"amount"`
+}
+
+// PromotionRule validates a stored promotion and converts it for the
+// evaluator
+func PromotionRule(promotion Promotion) (promotions.Rule, error) {
+	var conditions promotions.Conditions
+	if err := json.Unmarshal(promotion.Conditions, &conditions); err != nil {
+		return promotions.Rule{}, fmt.Errorf("%w: %v", promotions.ErrInvalidRule, err)
+	}
+
+	return promotions.NewRule(promotion.ID, promotion.Name, promotions.Type(promotion.Type), promotion.Priority, promotion.Exclusive, conditions)
+}
+
+// PromotionRules converts stored promotions for the evaluator
+func PromotionRules(list []Promotion) ([]promotions.Rule, error) {
+	rules := make([]promotions.Rule, len(list))
+	for i, promotion := range list {
+		var err error
+		rules[i], err = PromotionRule(promotion)
+		if err != nil {
+			return nil, fmt.Errorf("promotion %d: %w", promotion.ID, err)
+		}
+	}
+	return rules, nil
+}
+
+// PromotionDiscount explains what a promotion took off a line
+func PromotionDiscount(adjustment promotions.Adjustment) OrderItemDiscount {
+	return OrderItemDiscount{
+		PromotionID: adjustment.PromotionID,
+		Name:        adjustment.Name,
+		Description: adjustment.Description,
+		Amount:      util.FormatMoney(adjustment.Amount),
+	}
+}
+
+// applyPromotions evaluates the promotions that are active now against the
+// lines and adds what they take off to the discount of every line
+func applyPromotions(ctx context.Context, q *Queries, lines []checkoutLine) error {
+	active, err := q.ListActivePromotions(ctx)
+	if err != nil {
+		return err
+	}
+	if len(active) == 0 {
+		return nil
+	}
+
+	rules, err := PromotionRules(active)
+	if err != nil {
+		return err
+	}
+
+	evaluated := make([]promotions.Line, len(lines))
+	for i, line := range lines {
+		evaluated[i] = promotions.Line{
+			ProductID:  line.variant.ProductID,
+			CategoryID: line.categoryID,
+			Quantity:   line.quantity,
+			UnitPrice:  line.unitPrice,
+		}
+	}
+
+	result := promotions.Evaluate(rules, evaluated)
+	for i, adjustments := range result.Adjustments {
+		for _, adjustment := range adjustments {
+			lines[i].discount += adjustment.Amount
+			lines[i].discounts = append(lines[i].discounts, PromotionDiscount(adjustment))
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,395 @@
+package sqlc
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/cihanalici/api/payments"
+	"github.com/cihanalici/api/util"
+)
+
+// Statuses of a refund. A refund is pending while the gateway is asked for
+// the money, its amount is already reserved on the payment then.
+const (
+	RefundStatusPending   = "pending"
+	RefundStatusSucceeded = "succeeded"
+	RefundStatusFailed    = "failed"
+)
+
+type RefundItemParams struct {
+	OrderItemID int32 `json:"order_item_id"`
+	Quantity    int32 `json:"quantity"`
+}
+
+// CreateRefundTxParams describes what to refund: the given item quantities,
+// an arbitrary Amount, or everything left on the order when both are empty.
+// ReturnID links the refund to the received return it pays back.
+type CreateRefundTxParams struct {
+	OrderID   int32              `json:"order_id"`
+	Items     []RefundItemParams `json:"items"`
+	Amount    string             `json:"amount"`
+	Reason    string             `json:"reason"`
+	Restock   bool               `json:"restock"`
+	ReturnID  int32              `json:"return_id"`
+	CreatedBy int32              `json:"created_by"`
+}
+
+type CreateRefundTxResult struct {
+	Refund  Refund       `json:"refund"`
+	Items   []RefundItem `json:"items"`
+	Payment Payment      `json:"payment"`
+}
+
+// CreateRefundTx records a pending refund and reserves its amount on the
+// captured payment of the order, so concurrent refunds cannot exceed the
+// capture. The caller refunds at the gateway, then completes or fails it.
+func (store *SQLStore) CreateRefundTx(ctx context.Context, arg CreateRefundTxParams) (CreateRefundTxResult, error) {
+	var result CreateRefundTxResult
+
+	err := store.ExecTx(ctx, func(q *Queries) error {
+		order, err := q.GetOrderForUpdate(ctx, arg.OrderID)
+		if err != nil {
+			return err
+		}
+
+		payment, err := q.GetRefundablePaymentForUpdate(ctx, order.ID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return util.ErrOrderNotRefundable
+			}
+			return err
+		}
+
+		captured, err := util.ParseMoney(payment.CapturedAmount)
+		if err != nil {
+			return err
+		}
+		refunded, err := util.ParseMoney(payment.RefundedAmount)
+		if err != nil {
+			return err
+		}
+		remaining := captured - refunded
+		if remaining <= 0 {
+			return util.ErrOrderNotRefundable
+		}
+
+		if arg.ReturnID != 0 {
+			if err := lockReturnForRefund(ctx, q, arg.ReturnID); err != nil {
+				return err
+			}
+		}
+
+		lines, amount, err := refundLines(ctx, q, order.ID, arg)
+		if err != nil {
+			return err
+		}
+		if amount == 0 {
+			amount = remaining
+		}
+		if amount > remaining {
+			return fmt.Errorf("%w: %s left", util.ErrRefundExceedsCaptured, util.FormatMoney(remaining))
+		}
+
+		result.Refund, err = q.CreateRefund(ctx, CreateRefundParams{
+			OrderID:   order.ID,
+			PaymentID: payment.ID,
+			Amount:    util.FormatMoney(amount),
+			Reason:    arg.Reason,
+			// cancelling the order already returned its stock
+			Restock:   arg.Restock && len(lines) > 0 && order.Status != util.OrderStatusCancelled,
+			Status:    RefundStatusPending,
+			CreatedBy: util.ToInt32ToNullInt32(arg.CreatedBy),
+		})
+		if err != nil {
+			return err
+		}
+
+		result.Items = make([]RefundItem, 0, len(lines))
+		for _, line := range lines {
+			item, err := q.CreateRefundItem(ctx, CreateRefundItemParams{
+				RefundID:    result.Refund.ID,
+				OrderItemID: line.OrderItemID,
+				Quantity:    line.Quantity,
+				Amount:      line.Amount,
+			})
+			if err != nil {
+				return err
+			}
+			result.Items = append(result.Items, item)
+		}
+
+		if arg.ReturnID != 0 {
+			_, err = q.LinkReturnRefund(ctx, LinkReturnRefundParams{
+				ID:       arg.ReturnID,
+				RefundID: util.ToInt32ToNullInt32(result.Refund.ID),
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		result.Payment, err = q.AddPaymentRefundedAmount(ctx, AddPaymentRefundedAmountParams{
+			Amount: result.Refund.Amount,
+			ID:     payment.ID,
+		})
+		return err
+	})
+
+	return result, err
+}
+
+// lockReturnForRefund checks that a return is received and not refunded
+// yet. Only a failed refund of the return may be replaced by a new one.
+func lockReturnForRefund(ctx context.Context, q *Queries, returnID int32) error {
+	ret, err := q.GetReturnForUpdate(ctx, returnID)
+	if err != nil {
+		return err
+	}
+
+	if ret.Status != util.ReturnStatusReceived {
+		return fmt.Errorf("%w: the return is %s", util.ErrReturnTransition, ret.Status)
+	}
+
+	if !ret.RefundID.Valid {
+		return nil
+	}
+
+	linked, err := q.GetRefund(ctx, ret.RefundID.Int32)
+	if err != nil {
+		return err
+	}
+	if linked.Status != RefundStatusFailed {
+		return fmt.Errorf("%w: return %d already has the %s refund %d", util.ErrReturnTransition, ret.ID, linked.Status, linked.ID)
+	}
+	return nil
+}
+
+// refundLines resolves the order items a refund covers and what they are
+// worth. The amount is 0 when the whole remaining capture is refunded.
+func refundLines(ctx context.Context, q *Queries, orderID int32, arg CreateRefundTxParams) ([]CreateRefundItemParams, int64, error) {
+	if len(arg.Items) == 0 && arg.Amount != "" {
+		amount, err := util.ParseMoney(arg.Amount)
+		if err != nil {
+			return nil, 0, err
+		}
+		if amount <= 0 {
+			return nil, 0, fmt.Errorf("%w: %q", util.ErrInvalidAmount, arg.Amount)
+		}
+		return nil, amount, nil
+	}
+
+	items, err := q.GetAllOrderItemsByOrderId(ctx, orderID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	refundedRows, err := q.ListRefundedQuantitiesByOrderId(ctx, orderID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	left := make(map[int32]int32, len(items))
+	for _, item := range items {
+		left[item.ID] = item.Quantity
+	}
+	for _, row := range refundedRows {
+		left[row.OrderItemID] -= row.Quantity
+	}
+
+	requested := arg.Items
+	if len(requested) == 0 {
+		for _, item := range items {
+			if left[item.ID] > 0 {
+				requested = append(requested, RefundItemParams{OrderItemID: item.ID, Quantity: left[item.ID]})
+			}
+		}
+	}
+
+	ordered := make(map[int32]OrderItem, len(items))
+	for _, item := range items {
+		ordered[item.ID] = item
+	}
+
+	var lines []CreateRefundItemParams
+	var amount int64
+	for _, req := range requested {
+		item, ok := ordered[req.OrderItemID]
+		if !ok {
+			return nil, 0, fmt.Errorf("%w: order item %d is not part of the order", util.ErrInvalidRefundItem, req.OrderItemID)
+		}
+		if req.Quantity <= 0 || req.Quantity > left[req.OrderItemID] {
+			return nil, 0, fmt.Errorf("%w: only %d of order item %d can be refunded", util.ErrInvalidRefundItem, left[req.OrderItemID], req.OrderItemID)
+		}
+		left[req.OrderItemID] -= req.Quantity
+
+		lineAmount, err := orderItemPaid(item)
+		if err != nil {
+			return nil, 0, err
+		}
+		// a part of the line gives back its share of what was paid for it
+		lineAmount = lineAmount * int64(req.Quantity) / int64(item.Quantity)
+		amount += lineAmount
+
+		lines = append(lines, CreateRefundItemParams{
+			OrderItemID: req.OrderItemID,
+			Quantity:    req.Quantity,
+			Amount:      util.FormatMoney(lineAmount),
+		})
+	}
+
+	if len(arg.Items) == 0 {
+		// a whole order refund takes whatever is left on the payment
+		return lines, 0, nil
+	}
+	return lines, amount, nil
+}
+
+// orderItemPaid is what the customer paid for a whole order line, after its
+// share of the order discount
+func orderItemPaid(item OrderItem) (int64, error) {
+	price, err := util.ParseMoney(item.Price)
+	if err != nil {
+		return 0, err
+	}
+
+	discount, err := util.ParseMoney(item.DiscountAmount)
+	if err != nil {
+		return 0, err
+	}
+
+	return price*int64(item.Quantity) - discount, nil
+}
+
+type CompleteRefundTxParams struct {
+	RefundID  int32 `json:"refund_id"`
+	ChangedBy int32 `json:"changed_by"`
+}
+
+type CompleteRefundTxResult struct {
+	Refund Refund `json:"refund"`
+	Order  Order  `json:"order"`
+}
+
+// CompleteRefundTx marks a refund the gateway confirmed as succeeded,
+// restocks its items if asked to and records it on the order timeline. The
+// order becomes refunded once its payment is refunded in full.
+func (store *SQLStore) CompleteRefundTx(ctx context.Context, arg CompleteRefundTxParams) (CompleteRefundTxResult, error) {
+	var result CompleteRefundTxResult
+
+	err := store.ExecTx(ctx, func(q *Queries) error {
+		// the lock keeps a concurrent completion from restocking twice
+		refund, err := q.GetRefundForUpdate(ctx, arg.RefundID)
+		if err != nil {
+			return err
+		}
+
+		if refund.Status != RefundStatusPending {
+			return fmt.Errorf("refund %d is already %s", refund.ID, refund.Status)
+		}
+
+		order, err := q.GetOrderForUpdate(ctx, refund.OrderID)
+		if err != nil {
+			return err
+		}
+
+		result.Refund, err = q.UpdateRefundStatus(ctx, UpdateRefundStatusParams{
+			ID:     refund.ID,
+			Status: RefundStatusSucceeded,
+		})
+		if err != nil {
+			return err
+		}
+
+		if refund.Restock {
+			if err := restockRefund(ctx, q, refund); err != nil {
+				return err
+			}
+		}
+
+		payment, err := q.GetPayment(ctx, refund.PaymentID)
+		if err != nil {
+			return err
+		}
+
+		note := fmt.Sprintf("refund %d: %s", refund.ID, refund.Amount)
+		if refund.Reason != "" {
+			note += ", " + refund.Reason
+		}
+
+		if payment.Status == string(payments.StatusRefunded) && util.CanTransitionOrderStatus(order.Status, util.OrderStatusRefunded) {
+			result.Order, err = transitionOrder(ctx, q, UpdateOrderStatusTxParams{
+				OrderID:   order.ID,
+				Status:    util.OrderStatusRefunded,
+				ChangedBy: arg.ChangedBy,
+				Note:      note,
+			})
+			return err
+		}
+
+		// partial refunds leave the status alone but still show on the timeline
+		_, err = q.CreateOrderStatusHistory(ctx, CreateOrderStatusHistoryParams{
+			OrderID:    order.ID,
+			FromStatus: sql.NullString{String: order.Status, Valid: true},
+			ToStatus:   order.Status,
+			ChangedBy:  util.ToInt32ToNullInt32(arg.ChangedBy),
+			Note:       note,
+		})
+		result.Order = order
+		return err
+	})
+
+	return result, err
+}
+
+// restockRefund returns the refunded quantities to product_variants.stock
+func restockRefund(ctx context.Context, q *Queries, refund Refund) error {
+	refundItems, err := q.ListRefundItemsByOrderId(ctx, refund.OrderID)
+	if err != nil {
+		return err
+	}
+
+	quantities := make(map[int32]int32)
+	for _, item := range refundItems {
+		if item.RefundID == refund.ID {
+			quantities[item.OrderItemID] += item.Quantity
+		}
+	}
+
+	return restockOrderItems(ctx, q, refund.OrderID, quantities)
+}
+
+// FailRefundTx marks a refund the gateway rejected as failed and releases
+// its amount on the payment again
+func (store *SQLStore) FailRefundTx(ctx context.Context, refundID int32, reason string) (Refund, error) {
+	var result Refund
+
+	err := store.ExecTx(ctx, func(q *Queries) error {
+		refund, err := q.GetRefundForUpdate(ctx, refundID)
+		if err != nil {
+			return err
+		}
+
+		if refund.Status != RefundStatusPending {
+			return fmt.Errorf("refund %d is already %s", refund.ID, refund.Status)
+		}
+
+		result, err = q.UpdateRefundStatus(ctx, UpdateRefundStatusParams{
+			ID:            refund.ID,
+			Status:        RefundStatusFailed,
+			FailureReason: reason,
+		})
+		if err != nil {
+			return err
+		}
+
+		_, err = q.AddPaymentRefundedAmount(ctx, AddPaymentRefundedAmountParams{
+			Amount: "-" + refund.Amount,
+			ID:     refund.PaymentID,
+		})
+		return err
+	})
+
+	return result, err
+}
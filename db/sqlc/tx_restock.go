@@ -0,0 +1,41 @@
+package sqlc
+
+import (
+	"context"
+	"sort"
+)
+
+// restockOrderItems returns quantities of an order's items, keyed by order
+// item id, to product_variants.stock
+func restockOrderItems(ctx context.Context, q *Queries, orderID int32, quantities map[int32]int32) error {
+	orderItems, err := q.GetAllOrderItemsByOrderId(ctx, orderID)
+	if err != nil {
+		return err
+	}
+
+	stock := make(map[int32]int32)
+	for _, item := range orderItems {
+		if quantity := quantities[item.ID]; quantity > 0 {
+			stock[item.ProductVariantID] += quantity
+		}
+	}
+
+	// restock in variant id order, like checkout, to avoid deadlocks
+	variantIDs := make([]int32, 0, len(stock))
+	for id := range stock {
+		variantIDs = append(variantIDs, id)
+	}
+	sort.Slice(variantIDs, func(i, j int) bool { return variantIDs[i] < variantIDs[j] })
+
+	for _, id := range variantIDs {
+		_, err = q.AddProductVariantStock(ctx, AddProductVariantStockParams{
+			Amount: stock[id],
+			ID:     id,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
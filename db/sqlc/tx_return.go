@@ -0,0 +1,193 @@
+package sqlc
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/cihanalici/api/util"
+)
+
+type ReturnItemParams struct {
+	OrderItemID int32 `json:"order_item_id"`
+	Quantity    int32 `json:"quantity"`
+}
+
+type CreateReturnTxParams struct {
+	OrderID int32              `json:"order_id"`
+	UserID  int32              `json:"user_id"`
+	Reason  string             `json:"reason"`
+	Items   []ReturnItemParams `json:"items"`
+}
+
+type ReturnTxResult struct {
+	Return Return       `json:"return"`
+	Items  []ReturnItem `json:"items"`
+}
+
+// CreateReturnTx opens a return request for items of a delivered order. An
+// item cannot be returned more often than it was ordered, counting refunds
+// and the returns still open on it.
+func (store *SQLStore) CreateReturnTx(ctx context.Context, arg CreateReturnTxParams) (ReturnTxResult, error) {
+	var result ReturnTxResult
+
+	err := store.ExecTx(ctx, func(q *Queries) error {
+		order, err := q.GetOrderForUpdate(ctx, arg.OrderID)
+		if err != nil {
+			return err
+		}
+
+		if order.Status != util.OrderStatusDelivered {
+			return fmt.Errorf("%w: the order is %s", util.ErrOrderNotReturnable, order.Status)
+		}
+
+		items, err := q.GetAllOrderItemsByOrderId(ctx, order.ID)
+		if err != nil {
+			return err
+		}
+
+		refunded, err := q.ListRefundedQuantitiesByOrderId(ctx, order.ID)
+		if err != nil {
+			return err
+		}
+
+		returned, err := q.ListOpenReturnedQuantitiesByOrderId(ctx, order.ID)
+		if err != nil {
+			return err
+		}
+
+		left := make(map[int32]int32, len(items))
+		for _, item := range items {
+			left[item.ID] = item.Quantity
+		}
+		for _, row := range refunded {
+			left[row.OrderItemID] -= row.Quantity
+		}
+		for _, row := range returned {
+			left[row.OrderItemID] -= row.Quantity
+		}
+
+		for _, item := range arg.Items {
+			available, ok := left[item.OrderItemID]
+			if !ok {
+				return fmt.Errorf("%w: order item %d is not part of the order", util.ErrInvalidReturnItem, item.OrderItemID)
+			}
+			if item.Quantity <= 0 || item.Quantity > available {
+				return fmt.Errorf("%w: only %d of order item %d can be returned", util.ErrInvalidReturnItem, max(available, 0), item.OrderItemID)
+			}
+			left[item.OrderItemID] -= item.Quantity
+		}
+
+		result.Return, err = q.CreateReturn(ctx, CreateReturnParams{
+			OrderID: order.ID,
+			UserID:  arg.UserID,
+			Status:  util.ReturnStatusRequested,
+			Reason:  arg.Reason,
+		})
+		if err != nil {
+			return err
+		}
+
+		result.Items = make([]ReturnItem, 0, len(arg.Items))
+		for _, item := range arg.Items {
+			created, err := q.CreateReturnItem(ctx, CreateReturnItemParams{
+				ReturnID:    result.Return.ID,
+				OrderItemID: item.OrderItemID,
+				Quantity:    item.Quantity,
+			})
+			if err != nil {
+				return err
+			}
+			result.Items = append(result.Items, created)
+		}
+
+		return nil
+	})
+
+	return result, err
+}
+
+type UpdateReturnStatusTxParams struct {
+	ReturnID int32  `json:"return_id"`
+	Status   string `json:"status"`
+	Note     string `json:"note"`
+}
+
+// UpdateReturnStatusTx moves a return to a new status if the lifecycle
+// allows it. Receiving goes through ReceiveReturnTx and refunding through
+// MarkReturnRefunded, they have side effects.
+func (store *SQLStore) UpdateReturnStatusTx(ctx context.Context, arg UpdateReturnStatusTxParams) (Return, error) {
+	var result Return
+
+	err := store.ExecTx(ctx, func(q *Queries) error {
+		var err error
+		result, err = transitionReturn(ctx, q, arg)
+		return err
+	})
+
+	return result, err
+}
+
+// transitionReturn locks the return, validates the transition and updates it
+func transitionReturn(ctx context.Context, q *Queries, arg UpdateReturnStatusTxParams) (Return, error) {
+	if !util.IsSupportedReturnStatus(arg.Status) {
+		return Return{}, fmt.Errorf("%w: %q", util.ErrInvalidReturnStatus, arg.Status)
+	}
+
+	ret, err := q.GetReturnForUpdate(ctx, arg.ReturnID)
+	if err != nil {
+		return ret, err
+	}
+
+	if !util.CanTransitionReturnStatus(ret.Status, arg.Status) {
+		return ret, fmt.Errorf("%w: %s -> %s", util.ErrReturnTransition, ret.Status, arg.Status)
+	}
+
+	note := arg.Note
+	if note == "" {
+		note = ret.ResolutionNote
+	}
+
+	return q.UpdateReturnStatus(ctx, UpdateReturnStatusParams{
+		ID:             ret.ID,
+		Status:         arg.Status,
+		ResolutionNote: note,
+	})
+}
+
+type ReceiveReturnTxParams struct {
+	ReturnID int32  `json:"return_id"`
+	Note     string `json:"note"`
+}
+
+// ReceiveReturnTx marks an approved return received and puts the returned
+// quantities back into product_variants.stock. Refunding is left to the
+// caller, a refund failing does not undo the receipt.
+func (store *SQLStore) ReceiveReturnTx(ctx context.Context, arg ReceiveReturnTxParams) (ReturnTxResult, error) {
+	var result ReturnTxResult
+
+	err := store.ExecTx(ctx, func(q *Queries) error {
+		var err error
+		result.Return, err = transitionReturn(ctx, q, UpdateReturnStatusTxParams{
+			ReturnID: arg.ReturnID,
+			Status:   util.ReturnStatusReceived,
+			Note:     arg.Note,
+		})
+		if err != nil {
+			return err
+		}
+
+		result.Items, err = q.ListReturnItemsByReturnId(ctx, result.Return.ID)
+		if err != nil {
+			return err
+		}
+
+		quantities := make(map[int32]int32, len(result.Items))
+		for _, item := range result.Items {
+			quantities[item.OrderItemID] += item.Quantity
+		}
+
+		return restockOrderItems(ctx, q, result.Return.OrderID, quantities)
+	})
+
+	return result, err
+}
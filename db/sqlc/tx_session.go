@@ -0,0 +1,50 @@
+package sqlc
+
+import (
+	"context"
+	"time"
+
+	"github.com/cihanalici/api/util"
+	"github.com/google/uuid"
+)
+
+type RotateSessionTxParams struct {
+	SessionID    uuid.UUID           `json:"session_id"`
+	UserID       int32               `json:"user_id"`
+	RefreshToken string              `json:"refresh_token"`
+	NewSession   CreateSessionParams `json:"new_session"`
+}
+
+// RotateSessionTx exchanges a refresh token for a new one. The old session is
+// locked and blocked so a refresh token can only ever be used once.
+func (store *SQLStore) RotateSessionTx(ctx context.Context, arg RotateSessionTxParams) (Session, error) {
+	var result Session
+
+	err := store.ExecTx(ctx, func(q *Queries) error {
+		session, err := q.GetSessionForUpdate(ctx, arg.SessionID)
+		if err != nil {
+			return err
+		}
+
+		if session.IsBlocked {
+			return util.ErrSessionBlocked
+		}
+
+		if session.UserID != arg.UserID || session.RefreshToken != arg.RefreshToken {
+			return util.ErrInvalidToken
+		}
+
+		if time.Now().After(session.ExpiresAt) {
+			return util.ErrExpiredToken
+		}
+
+		if err := q.BlockSession(ctx, session.ID); err != nil {
+			return err
+		}
+
+		result, err = q.CreateSession(ctx, arg.NewSession)
+		return err
+	})
+
+	return result, err
+}
@@ -0,0 +1,79 @@
+package sqlc
+
+import (
+	"context"
+)
+
+// CreateUserAddressTx adds an address to the user's address book. The first
+// address becomes the default for shipping and billing, later ones take the
+// default flags over from the current defaults when asked to.
+func (store *SQLStore) CreateUserAddressTx(ctx context.Context, arg CreateUserAddressParams) (UserAddress, error) {
+	var result UserAddress
+
+	err := store.ExecTx(ctx, func(q *Queries) error {
+		count, err := q.CountUserAddresses(ctx, arg.UserID)
+		if err != nil {
+			return err
+		}
+
+		if count == 0 {
+			arg.IsDefaultShipping = true
+			arg.IsDefaultBilling = true
+		}
+
+		err = clearDefaultAddresses(ctx, q, arg.UserID, arg.IsDefaultShipping, arg.IsDefaultBilling)
+		if err != nil {
+			return err
+		}
+
+		result, err = q.CreateUserAddress(ctx, arg)
+		return err
+	})
+
+	return result, err
+}
+
+// UpdateUserAddressTx updates an address and moves the default flags to it
+// when they are set
+func (store *SQLStore) UpdateUserAddressTx(ctx context.Context, arg UpdateUserAddressParams) (UserAddress, error) {
+	var result UserAddress
+
+	err := store.ExecTx(ctx, func(q *Queries) error {
+		current, err := q.GetUserAddress(ctx, GetUserAddressParams{
+			ID:     arg.ID,
+			UserID: arg.UserID,
+		})
+		if err != nil {
+			return err
+		}
+
+		err = clearDefaultAddresses(ctx, q, arg.UserID,
+			arg.IsDefaultShipping && !current.IsDefaultShipping,
+			arg.IsDefaultBilling && !current.IsDefaultBilling,
+		)
+		if err != nil {
+			return err
+		}
+
+		result, err = q.UpdateUserAddress(ctx, arg)
+		return err
+	})
+
+	return result, err
+}
+
+func clearDefaultAddresses(ctx context.Context, q *Queries, userID int32, shipping, billing bool) error {
+	if shipping {
+		if err := q.ClearDefaultShippingAddress(ctx, userID); err != nil {
+			return err
+		}
+	}
+
+	if billing {
+		if err := q.ClearDefaultBillingAddress(ctx, userID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,21 @@
+package sqlc
+
+import "context"
+
+// UpdateUserRoleTx changes the role of a user and blocks every session of
+// the user, so tokens carrying the old role stop working right away
+func (store *SQLStore) UpdateUserRoleTx(ctx context.Context, arg UpdateUserRoleParams) (User, error) {
+	var result User
+
+	err := store.ExecTx(ctx, func(q *Queries) error {
+		var err error
+		result, err = q.UpdateUserRole(ctx, arg)
+		if err != nil {
+			return err
+		}
+
+		return q.BlockUserSessions(ctx, result.ID)
+	})
+
+	return result, err
+}
@@ -7,21 +7,19 @@ package sqlc
 
 import (
 	"context"
-	"encoding/json"
 )
 
 const createUser = `-- name: CreateUser :one
-INSERT INTO users (name, email, password, role, addresses)
-VALUES ($1, $2, $3, $4, $5)
-RETURNING id, name, email, password, role, addresses, created_at, updated_at
+INSERT INTO users (name, email, password, role)
+VALUES ($1, $2, $3, $4)
+RETURNING id, name, email, password, role, created_at, updated_at, email_verified_at
 `
 
 type CreateUserParams struct {
-	Name      string          `json:"name"`
-	Email     string          `json:"email"`
-	Password  string          `json:"password"`
-	Role      string          `json:"role"`
-	Addresses json.RawMessage `json:"addresses"`
+	Name     string `json:"name"`
+	Email    string `json:"email"`
+	Password string `json:"password"`
+	Role     string `json:"role"`
 }
 
 func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, error) {
@@ -30,7 +28,6 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, e
 		arg.Email,
 		arg.Password,
 		arg.Role,
-		arg.Addresses,
 	)
 	var i User
 	err := row.Scan(
@@ -39,9 +36,9 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, e
 		&i.Email,
 		&i.Password,
 		&i.Role,
-		&i.Addresses,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.EmailVerifiedAt,
 	)
 	return i, err
 }
@@ -57,7 +54,7 @@ func (q *Queries) DeleteUser(ctx context.Context, id int32) error {
 }
 
 const getUserByEmail = `-- name: GetUserByEmail :one
-SELECT id, name, email, password, role, addresses, created_at, updated_at
+SELECT id, name, email, password, role, created_at, updated_at, email_verified_at
 FROM users
 WHERE email = $1
 `
@@ -71,15 +68,15 @@ func (q *Queries) GetUserByEmail(ctx context.Context, email string) (User, error
 		&i.Email,
 		&i.Password,
 		&i.Role,
-		&i.Addresses,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.EmailVerifiedAt,
 	)
 	return i, err
 }
 
 const getUserById = `-- name: GetUserById :one
-SELECT id, name, email, password, role, addresses, created_at, updated_at
+SELECT id, name, email, password, role, created_at, updated_at, email_verified_at
 FROM users
 WHERE id = $1
 `
@@ -93,15 +90,15 @@ func (q *Queries) GetUserById(ctx context.Context, id int32) (User, error) {
 		&i.Email,
 		&i.Password,
 		&i.Role,
-		&i.Addresses,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.EmailVerifiedAt,
 	)
 	return i, err
 }
 
 const listUsers = `-- name: ListUsers :many
-SELECT id, name, email, role, password, addresses, created_at, updated_at
+SELECT id, name, email, role, password, created_at, updated_at, email_verified_at
 FROM users
 ORDER BY id
 LIMIT $1
@@ -129,9 +126,9 @@ func (q *Queries) ListUsers(ctx context.Context, arg ListUsersParams) ([]User, e
 			&i.Email,
 			&i.Role,
 			&i.Password,
-			&i.Addresses,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.EmailVerifiedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -146,30 +143,46 @@ func (q *Queries) ListUsers(ctx context.Context, arg ListUsersParams) ([]User, e
 	return items, nil
 }
 
+const markUserEmailVerified = `-- name: MarkUserEmailVerified :one
+UPDATE users
+SET email_verified_at = COALESCE(email_verified_at, CURRENT_TIMESTAMP), updated_at = CURRENT_TIMESTAMP
+WHERE id = $1
+RETURNING id, name, email, password, role, created_at, updated_at, email_verified_at
+`
+
+func (q *Queries) MarkUserEmailVerified(ctx context.Context, id int32) (User, error) {
+	row := q.db.QueryRowContext(ctx, markUserEmailVerified, id)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Email,
+		&i.Password,
+		&i.Role,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.EmailVerifiedAt,
+	)
+	return i, err
+}
+
 const updateUser = `-- name: UpdateUser :one
 UPDATE users
-SET name = $1, email = $2, role = $3, addresses = $4, updated_at = CURRENT_TIMESTAMP
-WHERE id = $5
-RETURNING id, name, email, role, password, addresses, created_at, updated_at
+SET name = $1, email = $2, updated_at = CURRENT_TIMESTAMP,
+  email_verified_at = CASE WHEN email = $2 THEN email_verified_at END
+WHERE id = $3
+RETURNING id, name, email, role, password, created_at, updated_at, email_verified_at
 `
 
 type UpdateUserParams struct {
-	Name      string          `json:"name"`
-	Email     string          `json:"email"`
-	Role      string          `json:"role"`
-	Addresses json.RawMessage `json:"addresses"`
-	ID        int32           `json:"id"`
+	Name  string `json:"name"`
+	Email string `json:"email"`
+	ID    int32  `json:"id"`
 }
 
 
 func (q *Queries) UpdateUser(ctx context.Context, arg UpdateUserParams) (User, error) {
-	row := q.db.QueryRowContext(ctx, updateUser,
-		arg.Name,
-		arg.Email,
-		arg.Role,
-		arg.Addresses,
-		arg.ID,
-	)
+	row := q.db.QueryRowContext(ctx, updateUser, arg.Name, arg.Email, arg.ID)
 	var i User
 	err := row.Scan(
 		&i.ID,
@@ -177,9 +190,9 @@ func (q *Queries) UpdateUser(ctx context.Context, arg UpdateUserParams) (User, e
 		&i.Email,
 		&i.Role,
 		&i.Password,
-		&i.Addresses,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.EmailVerifiedAt,
 	)
 	return i, err
 }
@@ -188,7 +201,7 @@ const updateUserPassword = `-- name: UpdateUserPassword :one
 UPDATE users
 SET password = $1, updated_at = CURRENT_TIMESTAMP
 WHERE id = $2
-RETURNING id, name, email, role, password, addresses, created_at, updated_at
+RETURNING id, name, email, role, password, created_at, updated_at, email_verified_at
 `
 
 type UpdateUserPasswordParams struct {
@@ -207,9 +220,37 @@ func (q *Queries) UpdateUserPassword(ctx context.Context, arg UpdateUserPassword
 		&i.Email,
 		&i.Role,
 		&i.Password,
-		&i.Addresses,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.EmailVerifiedAt,
+	)
+	return i, err
+}
+
+const updateUserRole = `-- name: UpdateUserRole :one
+UPDATE users
+SET role = $1, updated_at = CURRENT_TIMESTAMP
+WHERE id = $2
+RETURNING id, name, email, role, password, created_at, updated_at, email_verified_at
+`
+
+type UpdateUserRoleParams struct {
+	Role string `json:"role"`
+	ID   int32  `json:"id"`
+}
+
+func (q *Queries) UpdateUserRole(ctx context.Context, arg UpdateUserRoleParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, updateUserRole, arg.Role, arg.ID)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Email,
+		&i.Role,
+		&i.Password,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.EmailVerifiedAt,
 	)
 	return i, err
 }
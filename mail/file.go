@@ -0,0 +1,56 @@
+package mail
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// FileMailer writes every email as an .eml file into a directory instead of
+// sending it, which is handy for local development
+type FileMailer struct {
+	dir  string
+	from string
+}
+
+// NewFileMailer creates a new FileMailer, creating dir if needed
+func NewFileMailer(dir, from string) (*FileMailer, error) {
+	if dir == "" {
+		return nil, fmt.Errorf("mail drop directory is not set")
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("cannot create mail drop directory: %w", err)
+	}
+
+	return &FileMailer{dir: dir, from: from}, nil
+}
+
+func (mailer *FileMailer) Send(ctx context.Context, msg Message) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	suffix := make([]byte, 4)
+	if _, err := rand.Read(suffix); err != nil {
+		return err
+	}
+
+	name := fmt.Sprintf("%s-%s.eml", time.Now().UTC().Format("20060102T150405.000000000"), hex.EncodeToString(suffix))
+
+	file, err := os.Create(filepath.Join(mailer.dir, name))
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	if _, err := newGomailMessage(mailer.from, msg).WriteTo(file); err != nil {
+		return err
+	}
+
+	return file.Close()
+}
@@ -0,0 +1,142 @@
+package mail
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cihanalici/api/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderTemplates(t *testing.T) {
+	testCases := []struct {
+		name     string
+		data     any
+		subject  string
+		contains string
+	}{
+		{
+			name:     TemplatePasswordReset,
+			data:     PasswordResetData{Name: "Ada", ResetLink: "https://example.com/reset?token=abc&x=1", ExpiresIn: time.Hour},
+			subject:  "Reset your password",
+			contains: "https://example.com/reset?token=abc&x=1",
+		},
+		{
+			name:     TemplateEmailVerification,
+			data:     EmailVerificationData{Name: "Ada", VerifyLink: "https://example.com/verify?token=abc", ExpiresIn: time.Hour},
+			subject:  "Confirm your email address",
+			contains: "https://example.com/verify?token=abc",
+		},
+		{
+			name:     TemplateAccountLocked,
+			data:     AccountLockedData{Name: "Ada", UnlockLink: "https://example.com/unlock?token=abc", LockedFor: 15 * time.Minute},
+			subject:  "Your account has been locked",
+			contains: "https://example.com/unlock?token=abc",
+		},
+		{
+			name:     TemplateWelcome,
+			data:     WelcomeData{Name: "Ada"},
+			subject:  "Welcome, Ada!",
+			contains: "Hi Ada",
+		},
+		{
+			name: TemplateOrderConfirmation,
+			data: OrderConfirmationData{
+				Name:     "Ada",
+				OrderID:  7,
+				Items:    []OrderLine{{Description: "Red shirt", Quantity: 2, UnitPrice: "10.00", LineTotal: "20.00"}},
+				Subtotal: "20.00",
+				Total:    "20.00",
+			},
+			subject:  "Order #7 confirmed",
+			contains: "Red shirt x 2 @ 10.00 = 20.00",
+		},
+		{
+			name: TemplateOrderConfirmation,
+			data: OrderConfirmationData{
+				Name:     "Ada",
+				OrderID:  8,
+				Items:    []OrderLine{{Description: "Red shirt", Quantity: 2, UnitPrice: "10.00", LineTotal: "20.00"}},
+				Subtotal: "20.00",
+				Discount: "2.00",
+				Shipping: "4.99",
+				Total:    "22.99",
+			},
+			subject:  "Order #8 confirmed",
+			contains: "Discount: -2.00\nShipping: 4.99\nTotal: 22.99",
+		},
+		{
+			name:     TemplateShippingNotification,
+			data:     ShippingNotificationData{Name: "Ada", OrderID: 7},
+			subject:  "Order #7 has shipped",
+			contains: "order #7 is on its way",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			msg, err := Render("ada@example.com", tc.name, tc.data)
+			require.NoError(t, err)
+			require.Equal(t, "ada@example.com", msg.To)
+			require.Equal(t, tc.subject, msg.Subject)
+			require.Contains(t, msg.TextBody, tc.contains)
+			require.NotEmpty(t, msg.HTMLBody)
+		})
+	}
+}
+
+func TestRenderEscapesHTML(t *testing.T) {
+	msg, err := Render("ada@example.com", TemplateWelcome, WelcomeData{Name: "<script>alert(1)</script>"})
+	require.NoError(t, err)
+	require.NotContains(t, msg.HTMLBody, "<script>")
+}
+
+func TestRenderUnknownTemplate(t *testing.T) {
+	_, err := Render("ada@example.com", "missing", nil)
+	require.Error(t, err)
+}
+
+func TestMemoryMailer(t *testing.T) {
+	mailer := NewMemoryMailer()
+
+	err := mailer.Send(context.Background(), Message{To: "ada@example.com", Subject: "hi"})
+	require.NoError(t, err)
+
+	messages := mailer.Messages()
+	require.Len(t, messages, 1)
+	require.Equal(t, "hi", messages[0].Subject)
+}
+
+func TestFileMailer(t *testing.T) {
+	dir := t.TempDir()
+
+	mailer, err := NewFileMailer(dir, "no-reply@example.com")
+	require.NoError(t, err)
+
+	msg, err := Render("ada@example.com", TemplateWelcome, WelcomeData{Name: "Ada"})
+	require.NoError(t, err)
+	require.NoError(t, mailer.Send(context.Background(), msg))
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.eml"))
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+
+	content, err := os.ReadFile(files[0])
+	require.NoError(t, err)
+	require.True(t, strings.Contains(string(content), "Subject: Welcome, Ada!"))
+	require.Contains(t, string(content), "To: ada@example.com")
+}
+
+func TestNewMailerDefaultsToLocalSMTP(t *testing.T) {
+	mailer, err := NewMailer(util.Config{})
+	require.NoError(t, err)
+
+	smtp, ok := mailer.(*SMTPMailer)
+	require.True(t, ok)
+	require.Equal(t, defaultSMTPHost, smtp.dialer.Host)
+	require.Equal(t, defaultSMTPPort, smtp.dialer.Port)
+}
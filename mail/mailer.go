@@ -0,0 +1,58 @@
+package mail
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+
+	"github.com/cihanalici/api/util"
+	"gopkg.in/gomail.v2"
+)
+
+// Message is a rendered email ready to be delivered
+type Message struct {
+	To       string
+	Subject  string
+	HTMLBody string
+	TextBody string
+}
+
+// Mailer delivers transactional emails
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// Defaults of the SMTP driver, a local MailHog as started by `make mailHog`
+const (
+	defaultSMTPHost = "localhost"
+	defaultSMTPPort = 1025
+)
+
+// NewMailer creates the Mailer selected by MAIL_DRIVER, defaulting to SMTP
+func NewMailer(config util.Config) (Mailer, error) {
+	switch config.MailDriver {
+	case "", "smtp":
+		host := cmp.Or(config.SMTPHost, defaultSMTPHost)
+		port := cmp.Or(config.SMTPPort, defaultSMTPPort)
+		return NewSMTPMailer(host, port, config.SMTPUsername, config.SMTPPassword, config.MailFrom), nil
+	case "file":
+		return NewFileMailer(config.MailDropDir, config.MailFrom)
+	case "memory":
+		return NewMemoryMailer(), nil
+	default:
+		return nil, fmt.Errorf("unsupported mail driver %q", config.MailDriver)
+	}
+}
+
+// newGomailMessage builds the multipart message shared by the SMTP and file mailers
+func newGomailMessage(from string, msg Message) *gomail.Message {
+	m := gomail.NewMessage()
+	m.SetHeader("From", from)
+	m.SetHeader("To", msg.To)
+	m.SetHeader("Subject", msg.Subject)
+	m.SetBody("text/plain", msg.TextBody)
+	if msg.HTMLBody != "" {
+		m.AddAlternative("text/html", msg.HTMLBody)
+	}
+	return m
+}
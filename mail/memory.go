@@ -0,0 +1,32 @@
+package mail
+
+import (
+	"context"
+	"sync"
+)
+
+// MemoryMailer keeps sent emails in memory so tests can inspect them
+type MemoryMailer struct {
+	mu       sync.Mutex
+	messages []Message
+}
+
+func NewMemoryMailer() *MemoryMailer {
+	return &MemoryMailer{}
+}
+
+func (mailer *MemoryMailer) Send(ctx context.Context, msg Message) error {
+	mailer.mu.Lock()
+	defer mailer.mu.Unlock()
+
+	mailer.messages = append(mailer.messages, msg)
+	return nil
+}
+
+// Messages returns a copy of every email sent so far
+func (mailer *MemoryMailer) Messages() []Message {
+	mailer.mu.Lock()
+	defer mailer.mu.Unlock()
+
+	return append([]Message(nil), mailer.messages...)
+}
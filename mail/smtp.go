@@ -0,0 +1,30 @@
+package mail
+
+import (
+	"context"
+
+	"gopkg.in/gomail.v2"
+)
+
+// SMTPMailer sends emails through an SMTP server
+type SMTPMailer struct {
+	dialer *gomail.Dialer
+	from   string
+}
+
+// NewSMTPMailer creates a new SMTPMailer. Username and password may be empty
+// for servers that do not need authentication, such as MailHog.
+func NewSMTPMailer(host string, port int, username, password, from string) *SMTPMailer {
+	return &SMTPMailer{
+		dialer: gomail.NewDialer(host, port, username, password),
+		from:   from,
+	}
+}
+
+func (mailer *SMTPMailer) Send(ctx context.Context, msg Message) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return mailer.dialer.DialAndSend(newGomailMessage(mailer.from, msg))
+}
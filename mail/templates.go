@@ -0,0 +1,124 @@
+package mail
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"strings"
+	texttemplate "text/template"
+	"time"
+)
+
+//go:embed templates
+var templateFS embed.FS
+
+// template names, each backed by templates/<name>.txt and templates/<name>.html.
+// The text template also defines the "subject" block.
+const (
+	TemplatePasswordReset        = "password_reset"
+	TemplateEmailVerification    = "email_verification"
+	TemplateAccountLocked        = "account_locked"
+	TemplateWelcome              = "welcome"
+	TemplateOrderConfirmation    = "order_confirmation"
+	TemplateShippingNotification = "shipping_notification"
+)
+
+type PasswordResetData struct {
+	Name      string
+	ResetLink string
+	ExpiresIn time.Duration
+}
+
+type EmailVerificationData struct {
+	Name       string
+	VerifyLink string
+	ExpiresIn  time.Duration
+}
+
+type AccountLockedData struct {
+	Name       string
+	UnlockLink string
+	LockedFor  time.Duration
+}
+
+type WelcomeData struct {
+	Name string
+}
+
+type OrderLine struct {
+	Description string
+	Quantity    int32
+	UnitPrice   string
+	LineTotal   string
+}
+
+type OrderConfirmationData struct {
+	Name     string
+	OrderID  int32
+	Items    []OrderLine
+	Subtotal string
+	// Discount and Shipping are left out of the email when empty
+	Discount string
+	Shipping string
+	Total    string
+}
+
+type ShippingNotificationData struct {
+	Name    string
+	OrderID int32
+}
+
+type emailTemplate struct {
+	text *texttemplate.Template
+	html *htmltemplate.Template
+}
+
+var templates = mustParseTemplates(
+	TemplatePasswordReset,
+	TemplateEmailVerification,
+	TemplateAccountLocked,
+	TemplateWelcome,
+	TemplateOrderConfirmation,
+	TemplateShippingNotification,
+)
+
+func mustParseTemplates(names ...string) map[string]emailTemplate {
+	parsed := make(map[string]emailTemplate, len(names))
+	for _, name := range names {
+		parsed[name] = emailTemplate{
+			text: texttemplate.Must(texttemplate.ParseFS(templateFS, "templates/"+name+".txt")),
+			html: htmltemplate.Must(htmltemplate.ParseFS(templateFS, "templates/"+name+".html")),
+		}
+	}
+	return parsed
+}
+
+// Render builds the email for the named template addressed to to
+func Render(to, name string, data any) (Message, error) {
+	tmpl, ok := templates[name]
+	if !ok {
+		return Message{}, fmt.Errorf("unknown email template %q", name)
+	}
+
+	var subject, text, html bytes.Buffer
+
+	if err := tmpl.text.ExecuteTemplate(&subject, "subject", data); err != nil {
+		return Message{}, fmt.Errorf("cannot render subject of %s: %w", name, err)
+	}
+
+	if err := tmpl.text.ExecuteTemplate(&text, name+".txt", data); err != nil {
+		return Message{}, fmt.Errorf("cannot render %s.txt: %w", name, err)
+	}
+
+	if err := tmpl.html.ExecuteTemplate(&html, name+".html", data); err != nil {
+		return Message{}, fmt.Errorf("cannot render %s.html: %w", name, err)
+	}
+
+	return Message{
+		To:       to,
+		Subject:  strings.TrimSpace(subject.String()),
+		TextBody: strings.TrimSpace(text.String()) + "\n",
+		HTMLBody: html.String(),
+	}, nil
+}
@@ -6,6 +6,8 @@ import (
 
 	"github.com/cihanalici/api/api"
 	db "github.com/cihanalici/api/db/sqlc"
+	"github.com/cihanalici/api/mail"
+	"github.com/cihanalici/api/payments"
 	"github.com/cihanalici/api/util"
 	_ "github.com/lib/pq"
 )
@@ -21,8 +23,18 @@ func main() {
 		log.Fatal("cannot connect to database:", err)
 	}
 
+	mailer, err := mail.NewMailer(config)
+	if err != nil {
+		log.Fatal("cannot create mailer:", err)
+	}
+
+	gateway, err := payments.NewGateway(config)
+	if err != nil {
+		log.Fatal("cannot create payment gateway:", err)
+	}
+
 	store := db.NewStore(conn)
-	server, err := api.NewServer(config, store)
+	server, err := api.NewServer(config, store, mailer, gateway)
 	if err != nil {
 		log.Fatal("cannot create server:", err)
 	}
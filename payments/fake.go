@@ -0,0 +1,211 @@
+package payments
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// Magic card numbers understood by FakeGateway, any other valid card number
+// is authorized
+const (
+	FakeCardSuccess           = "4242424242424242"
+	FakeCardDeclined          = "4000000000000002"
+	FakeCardInsufficientFunds = "4000000000009995"
+	FakeCardRequiresAction    = "4000000000003220"
+)
+
+// fakePayment is what FakeGateway remembers about an authorization
+type fakePayment struct {
+	status   Status
+	amount   int64
+	captured int64
+	refunded int64
+}
+
+// FakeGateway is a deterministic in-process Gateway for development and
+// tests. Payment IDs are numbered in the order of authorizations.
+type FakeGateway struct {
+	mu       sync.Mutex
+	sequence int
+	payments map[string]*fakePayment
+}
+
+func NewFakeGateway() *FakeGateway {
+	return &FakeGateway{payments: make(map[string]*fakePayment)}
+}
+
+func (gateway *FakeGateway) Name() string {
+	return "fake"
+}
+
+func (gateway *FakeGateway) Authorize(ctx context.Context, req AuthorizeRequest) (Result, error) {
+	if err := ctx.Err(); err != nil {
+		return Result{}, err
+	}
+
+	if req.Amount <= 0 {
+		return Result{}, ErrInvalidAmount
+	}
+
+	card := strings.ReplaceAll(strings.ReplaceAll(req.PaymentMethod, " ", ""), "-", "")
+	if !validCardNumber(card) {
+		return Result{}, ErrInvalidPayment
+	}
+
+	gateway.mu.Lock()
+	defer gateway.mu.Unlock()
+
+	gateway.sequence++
+	id := fmt.Sprintf("fake_%06d", gateway.sequence)
+	payment := &fakePayment{status: StatusAuthorized, amount: req.Amount}
+	result := Result{ID: id, Amount: req.Amount}
+
+	switch card {
+	case FakeCardDeclined:
+		payment.status = StatusDeclined
+		gateway.payments[id] = payment
+		return Result{ID: id, Status: StatusDeclined}, fmt.Errorf("%w: card declined", ErrDeclined)
+	case FakeCardInsufficientFunds:
+		payment.status = StatusDeclined
+		gateway.payments[id] = payment
+		return Result{ID: id, Status: StatusDeclined}, fmt.Errorf("%w: insufficient funds", ErrDeclined)
+	case FakeCardRequiresAction:
+		payment.status = StatusRequiresAction
+		result.NextActionURL = "https://fake-gateway.local/3ds/" + id
+	}
+
+	gateway.payments[id] = payment
+	result.Status = payment.status
+	return result, nil
+}
+
+// Confirm treats the customer action as passed and authorizes the payment
+func (gateway *FakeGateway) Confirm(ctx context.Context, id string) (Result, error) {
+	if err := ctx.Err(); err != nil {
+		return Result{}, err
+	}
+
+	gateway.mu.Lock()
+	defer gateway.mu.Unlock()
+
+	payment, ok := gateway.payments[id]
+	if !ok {
+		return Result{}, ErrNotFound
+	}
+
+	if payment.status != StatusRequiresAction {
+		return Result{}, fmt.Errorf("%w: payment is %s", ErrInvalidPaymentOp, payment.status)
+	}
+
+	payment.status = StatusAuthorized
+	return Result{ID: id, Status: payment.status, Amount: payment.amount}, nil
+}
+
+func (gateway *FakeGateway) Capture(ctx context.Context, id string, amount int64) (Result, error) {
+	if err := ctx.Err(); err != nil {
+		return Result{}, err
+	}
+
+	gateway.mu.Lock()
+	defer gateway.mu.Unlock()
+
+	payment, ok := gateway.payments[id]
+	if !ok {
+		return Result{}, ErrNotFound
+	}
+
+	if payment.status != StatusAuthorized {
+		return Result{}, fmt.Errorf("%w: payment is %s", ErrInvalidPaymentOp, payment.status)
+	}
+
+	if amount <= 0 {
+		return Result{}, ErrInvalidAmount
+	}
+	if amount > payment.amount {
+		return Result{}, ErrAmountExceedsAuth
+	}
+
+	payment.status = StatusCaptured
+	payment.captured = amount
+	return Result{ID: id, Status: payment.status, Amount: amount}, nil
+}
+
+func (gateway *FakeGateway) Void(ctx context.Context, id string) (Result, error) {
+	if err := ctx.Err(); err != nil {
+		return Result{}, err
+	}
+
+	gateway.mu.Lock()
+	defer gateway.mu.Unlock()
+
+	payment, ok := gateway.payments[id]
+	if !ok {
+		return Result{}, ErrNotFound
+	}
+
+	if payment.status != StatusAuthorized && payment.status != StatusRequiresAction {
+		return Result{}, fmt.Errorf("%w: payment is %s", ErrInvalidPaymentOp, payment.status)
+	}
+
+	payment.status = StatusVoided
+	return Result{ID: id, Status: payment.status, Amount: payment.amount}, nil
+}
+
+func (gateway *FakeGateway) Refund(ctx context.Context, id string, amount int64) (Result, error) {
+	if err := ctx.Err(); err != nil {
+		return Result{}, err
+	}
+
+	gateway.mu.Lock()
+	defer gateway.mu.Unlock()
+
+	payment, ok := gateway.payments[id]
+	if !ok {
+		return Result{}, ErrNotFound
+	}
+
+	if payment.status != StatusCaptured {
+		return Result{}, fmt.Errorf("%w: payment is %s", ErrInvalidPaymentOp, payment.status)
+	}
+
+	if amount <= 0 {
+		return Result{}, ErrInvalidAmount
+	}
+	if payment.refunded+amount > payment.captured {
+		return Result{}, ErrAmountExceedsAuth
+	}
+
+	payment.refunded += amount
+	if payment.refunded == payment.captured {
+		payment.status = StatusRefunded
+	}
+	return Result{ID: id, Status: payment.status, Amount: amount}, nil
+}
+
+// validCardNumber checks the length and the Luhn checksum
+func validCardNumber(number string) bool {
+	if len(number) < 12 || len(number) > 19 {
+		return false
+	}
+
+	sum := 0
+	double := false
+	for i := len(number) - 1; i >= 0; i-- {
+		digit := int(number[i] - '0')
+		if digit < 0 || digit > 9 {
+			return false
+		}
+		if double {
+			digit *= 2
+			if digit > 9 {
+				digit -= 9
+			}
+		}
+		sum += digit
+		double = !double
+	}
+
+	return sum%10 == 0
+}
@@ -0,0 +1,117 @@
+package payments
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFakeGatewayAuthorize(t *testing.T) {
+	testCases := []struct {
+		name   string
+		card   string
+		status Status
+		err    error
+	}{
+		{name: "Success", card: FakeCardSuccess, status: StatusAuthorized},
+		{name: "SuccessWithSpaces", card: "4242 4242 4242 4242", status: StatusAuthorized},
+		{name: "Declined", card: FakeCardDeclined, status: StatusDeclined, err: ErrDeclined},
+		{name: "InsufficientFunds", card: FakeCardInsufficientFunds, status: StatusDeclined, err: ErrDeclined},
+		{name: "RequiresAction", card: FakeCardRequiresAction, status: StatusRequiresAction},
+		{name: "InvalidChecksum", card: "4242424242424241", err: ErrInvalidPayment},
+		{name: "NotACard", card: "tok_visa", err: ErrInvalidPayment},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			gateway := NewFakeGateway()
+
+			result, err := gateway.Authorize(context.Background(), AuthorizeRequest{
+				Amount:        1000,
+				Currency:      "USD",
+				PaymentMethod: tc.card,
+			})
+			if tc.err != nil {
+				require.ErrorIs(t, err, tc.err)
+			} else {
+				require.NoError(t, err)
+				require.Equal(t, "fake_000001", result.ID)
+			}
+			require.Equal(t, tc.status, result.Status)
+			require.Equal(t, tc.status == StatusRequiresAction, result.NextActionURL != "")
+		})
+	}
+}
+
+func TestFakeGatewayLifecycle(t *testing.T) {
+	ctx := context.Background()
+	gateway := NewFakeGateway()
+
+	auth, err := gateway.Authorize(ctx, AuthorizeRequest{Amount: 1000, Currency: "USD", PaymentMethod: FakeCardSuccess})
+	require.NoError(t, err)
+
+	_, err = gateway.Refund(ctx, auth.ID, 100)
+	require.ErrorIs(t, err, ErrInvalidPaymentOp)
+
+	_, err = gateway.Capture(ctx, auth.ID, 1001)
+	require.ErrorIs(t, err, ErrAmountExceedsAuth)
+
+	captured, err := gateway.Capture(ctx, auth.ID, 1000)
+	require.NoError(t, err)
+	require.Equal(t, StatusCaptured, captured.Status)
+
+	_, err = gateway.Void(ctx, auth.ID)
+	require.ErrorIs(t, err, ErrInvalidPaymentOp)
+
+	refund, err := gateway.Refund(ctx, auth.ID, 400)
+	require.NoError(t, err)
+	require.Equal(t, StatusCaptured, refund.Status)
+
+	_, err = gateway.Refund(ctx, auth.ID, 601)
+	require.ErrorIs(t, err, ErrAmountExceedsAuth)
+
+	refund, err = gateway.Refund(ctx, auth.ID, 600)
+	require.NoError(t, err)
+	require.Equal(t, StatusRefunded, refund.Status)
+
+	other, err := gateway.Authorize(ctx, AuthorizeRequest{Amount: 500, Currency: "USD", PaymentMethod: FakeCardSuccess})
+	require.NoError(t, err)
+	require.Equal(t, "fake_000002", other.ID)
+
+	voided, err := gateway.Void(ctx, other.ID)
+	require.NoError(t, err)
+	require.Equal(t, StatusVoided, voided.Status)
+
+	_, err = gateway.Capture(ctx, other.ID, 500)
+	require.ErrorIs(t, err, ErrInvalidPaymentOp)
+
+	_, err = gateway.Capture(ctx, "fake_999999", 500)
+	require.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestFakeGatewayConfirm(t *testing.T) {
+	ctx := context.Background()
+	gateway := NewFakeGateway()
+
+	auth, err := gateway.Authorize(ctx, AuthorizeRequest{Amount: 1000, Currency: "USD", PaymentMethod: FakeCardRequiresAction})
+	require.NoError(t, err)
+
+	_, err = gateway.Capture(ctx, auth.ID, 1000)
+	require.ErrorIs(t, err, ErrInvalidPaymentOp)
+
+	confirmed, err := gateway.Confirm(ctx, auth.ID)
+	require.NoError(t, err)
+	require.Equal(t, StatusAuthorized, confirmed.Status)
+	require.Equal(t, int64(1000), confirmed.Amount)
+
+	_, err = gateway.Confirm(ctx, auth.ID)
+	require.ErrorIs(t, err, ErrInvalidPaymentOp)
+
+	captured, err := gateway.Capture(ctx, auth.ID, 1000)
+	require.NoError(t, err)
+	require.Equal(t, StatusCaptured, captured.Status)
+
+	_, err = gateway.Confirm(ctx, "fake_999999")
+	require.ErrorIs(t, err, ErrNotFound)
+}
@@ -0,0 +1,80 @@
+package payments
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/cihanalici/api/util"
+)
+
+// Status is the state of a payment at the provider
+type Status string
+
+const (
+	StatusAuthorized     Status = "authorized"
+	StatusRequiresAction Status = "requires_action"
+	StatusCaptured       Status = "captured"
+	StatusVoided         Status = "voided"
+	StatusRefunded       Status = "refunded"
+	StatusDeclined       Status = "declined"
+	StatusFailed         Status = "failed"
+)
+
+var (
+	ErrDeclined          = errors.New("payment was declined")
+	ErrInvalidPayment    = errors.New("invalid payment method")
+	ErrInvalidAmount     = errors.New("invalid payment amount")
+	ErrNotFound          = errors.New("payment not found at the provider")
+	ErrInvalidPaymentOp  = errors.New("operation is not allowed in the current payment state")
+	ErrAmountExceedsAuth = errors.New("amount exceeds the authorized or captured amount")
+)
+
+// AuthorizeRequest reserves Amount, in cents of Currency, on a payment
+// method. PaymentMethod is whatever the provider's client side SDK hands out,
+// usually a token; the fake gateway takes a card number.
+type AuthorizeRequest struct {
+	Amount        int64
+	Currency      string
+	PaymentMethod string
+	// Reference ties the payment to our records on the provider's side
+	Reference string
+}
+
+// Result is the state of a payment after an operation. NextActionURL is only
+// set with StatusRequiresAction, the customer has to finish there, e.g. 3DS.
+type Result struct {
+	ID            string
+	Status        Status
+	Amount        int64
+	NextActionURL string
+}
+
+// Gateway is a payment provider. Amounts are in cents. Declines are returned
+// as ErrDeclined, wrapped with the provider's reason.
+type Gateway interface {
+	// Name identifies the provider in stored payments
+	Name() string
+	Authorize(ctx context.Context, req AuthorizeRequest) (Result, error)
+	// Confirm completes the authorization of a payment that required an
+	// action of the customer once they finished it
+	Confirm(ctx context.Context, id string) (Result, error)
+	// Capture collects up to the authorized amount
+	Capture(ctx context.Context, id string, amount int64) (Result, error)
+	// Void releases an authorization that was not captured
+	Void(ctx context.Context, id string) (Result, error)
+	// Refund returns part or all of a captured amount, it may be called
+	// until the whole capture is refunded
+	Refund(ctx context.Context, id string, amount int64) (Result, error)
+}
+
+// NewGateway creates the Gateway selected by PAYMENT_DRIVER. The fake gateway
+// is the only one so far and the default.
+func NewGateway(config util.Config) (Gateway, error) {
+	switch config.PaymentDriver {
+	case "", "fake":
+		return NewFakeGateway(), nil
+	default:
+		return nil, fmt.Errorf("unsupported payment driver %q", config.PaymentDriver)
+	}
+}
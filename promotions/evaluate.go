@@ -0,0 +1,267 @@
+package promotions
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/cihanalici/api/util"
+)
+
+// Line is an order or cart line as the evaluator sees it, UnitPrice is in
+// cents
+type Line struct {
+	ProductID  int32
+	CategoryID int32
+	Quantity   int32
+	UnitPrice  int64
+}
+
+// Adjustment explains the part of a line's discount given by one promotion
+type Adjustment struct {
+	PromotionID int32
+	Name        string
+	Description string
+	Amount      int64
+}
+
+// Result holds the discount of every line in the order they were given.
+// Adjustments of a line add up to its discount.
+type Result struct {
+	Discounts   []int64
+	Adjustments [][]Adjustment
+	Total       int64
+}
+
+// Evaluate applies the rules to the lines. The outcome only depends on the
+// rules and the lines, and the policy is:
+//
+//   - rules are evaluated by descending Priority, then ascending ID
+//   - buy_x_get_y and bundle rules claim the units they use, a unit is part
+//     of at most one such promotion; the most expensive units are grouped
+//     first and the cheapest units of a group are the discounted ones
+//   - tiered rules claim nothing, they apply to what is left to pay on the
+//     eligible lines after the rules evaluated before them, and the tier is
+//     picked from that amount too
+//   - an Exclusive rule is skipped when a promotion already applied and ends
+//     the evaluation when it applies itself
+//   - a line is never discounted below zero
+func Evaluate(rules []Rule, lines []Line) Result {
+	result := Result{
+		Discounts:   make([]int64, len(lines)),
+		Adjustments: make([][]Adjustment, len(lines)),
+	}
+
+	sorted := make([]Rule, len(rules))
+	copy(sorted, rules)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].Priority != sorted[j].Priority {
+			return sorted[i].Priority > sorted[j].Priority
+		}
+		return sorted[i].ID < sorted[j].ID
+	})
+
+	free := make([]int32, len(lines))
+	for i, line := range lines {
+		free[i] = line.Quantity
+	}
+
+	applied := false
+	for _, rule := range sorted {
+		if rule.Exclusive && applied {
+			continue
+		}
+
+		out := rule.evaluate(lines, free, result.Discounts)
+
+		var total int64
+		for i, share := range out.shares {
+			// what is left to pay on the line caps the share
+			paid := lines[i].UnitPrice*int64(lines[i].Quantity) - result.Discounts[i]
+			out.shares[i] = min(share, max(paid, 0))
+			total += out.shares[i]
+		}
+		if total <= 0 {
+			continue
+		}
+
+		for i, share := range out.shares {
+			free[i] -= out.claimed[i]
+			if share <= 0 {
+				continue
+			}
+
+			result.Discounts[i] += share
+			result.Adjustments[i] = append(result.Adjustments[i], Adjustment{
+				PromotionID: rule.ID,
+				Name:        rule.Name,
+				Description: out.description,
+				Amount:      share,
+			})
+		}
+		result.Total += total
+		applied = true
+
+		if rule.Exclusive {
+			break
+		}
+	}
+
+	return result
+}
+
+// outcome is what a rule would do to the lines: the discount of every line
+// and the units it claims
+type outcome struct {
+	shares      []int64
+	claimed     []int32
+	description string
+}
+
+// bucket is the unclaimed units of a line, they all cost the same
+type bucket struct {
+	line     int
+	price    int64
+	quantity int64
+}
+
+func (rule Rule) evaluate(lines []Line, free []int32, discounts []int64) outcome {
+	out := outcome{
+		shares:      make([]int64, len(lines)),
+		claimed:     make([]int32, len(lines)),
+		description: rule.description(),
+	}
+
+	switch rule.Type {
+	case TypeBuyXGetY:
+		var buckets []bucket
+		for i, line := range lines {
+			if rule.eligible(line) {
+				buckets = appendBucket(buckets, i, line, free[i])
+			}
+		}
+		sortBuckets(buckets)
+
+		// the units are laid out most expensive first and cut into groups
+		// of buy+get, only whole groups are claimed
+		var units int64
+		for _, b := range buckets {
+			units += b.quantity
+		}
+		group := int64(rule.Buy + rule.Get)
+		grouped := units / group * group
+
+		var first int64
+		for _, b := range buckets {
+			last := min(first+b.quantity, grouped)
+			if last > first {
+				out.claimed[b.line] += int32(last - first)
+				discounted := rule.discountedUnits(last) - rule.discountedUnits(first)
+				out.shares[b.line] += discounted * (b.price * rule.Percent / 10000)
+			}
+			first += b.quantity
+		}
+
+	case TypeBundle:
+		products := make([][]bucket, len(rule.BundleProductIDs))
+		for p, productID := range rule.BundleProductIDs {
+			for i, line := range lines {
+				if line.ProductID == productID {
+					products[p] = appendBucket(products[p], i, line, free[i])
+				}
+			}
+			sortBuckets(products[p])
+		}
+
+		// a set takes the next most expensive unit of every product, sets
+		// made of the same buckets cost the same and are priced at once
+		next := make([]int, len(products))
+		for {
+			sets := int64(-1)
+			for p := range products {
+				if next[p] == len(products[p]) {
+					return out
+				}
+				if left := products[p][next[p]].quantity; sets < 0 || left < sets {
+					sets = left
+				}
+			}
+
+			prices := make([]int64, len(products))
+			var price int64
+			for p := range products {
+				prices[p] = products[p][next[p]].price
+				price += prices[p]
+			}
+
+			// a set that would not save anything is left unclaimed
+			if price > rule.BundlePrice {
+				for p, share := range util.AllocateDiscount(price-rule.BundlePrice, prices) {
+					b := products[p][next[p]]
+					out.claimed[b.line] += int32(sets)
+					out.shares[b.line] += share * sets
+				}
+			}
+
+			for p := range products {
+				products[p][next[p]].quantity -= sets
+				if products[p][next[p]].quantity == 0 {
+					next[p]++
+				}
+			}
+		}
+
+	case TypeTiered:
+		var eligible []int
+		var amounts []int64
+		var base int64
+		for i, line := range lines {
+			if !rule.eligible(line) {
+				continue
+			}
+			paid := max(line.UnitPrice*int64(line.Quantity)-discounts[i], 0)
+			eligible = append(eligible, i)
+			amounts = append(amounts, paid)
+			base += paid
+		}
+
+		var tier *Tier
+		for i := range rule.Tiers {
+			if rule.Tiers[i].MinAmount <= base {
+				tier = &rule.Tiers[i]
+			}
+		}
+		if tier == nil || base <= 0 {
+			return out
+		}
+
+		discount := base * tier.Percent / 10000
+		for i, share := range util.AllocateDiscount(discount, amounts) {
+			out.shares[eligible[i]] = share
+		}
+		out.description = fmt.Sprintf("%s%% off from %s", util.FormatMoney(tier.Percent), util.FormatMoney(tier.MinAmount))
+	}
+
+	return out
+}
+
+// discountedUnits counts the get units among the first n grouped units
+func (rule Rule) discountedUnits(n int64) int64 {
+	group := int64(rule.Buy + rule.Get)
+	return n/group*int64(rule.Get) + max(n%group-int64(rule.Buy), 0)
+}
+
+// appendBucket adds the unclaimed units of a line
+func appendBucket(buckets []bucket, i int, line Line, free int32) []bucket {
+	if free <= 0 {
+		return buckets
+	}
+	return append(buckets, bucket{line: i, price: line.UnitPrice, quantity: int64(free)})
+}
+
+// sortBuckets puts the most expensive units first, units of the same price
+// keep the order of their lines
+func sortBuckets(buckets []bucket) {
+	sort.SliceStable(buckets, func(i, j int) bool {
+		return buckets[i].price > buckets[j].price
+	})
+}
@@ -0,0 +1,357 @@
+package promotions
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func buyTwoGetOne(id int32, priority int32, categoryIDs ...int32) Rule {
+	return Rule{ID: id, Name: "3 for 2", Type: TypeBuyXGetY, Priority: priority, CategoryIDs: categoryIDs, Buy: 2, Get: 1, Percent: 10000}
+}
+
+func tenPercentOver(id int32, priority int32, minAmount int64) Rule {
+	return Rule{ID: id, Name: "10% off", Type: TypeTiered, Priority: priority, Tiers: []Tier{{MinAmount: minAmount, Percent: 1000}}}
+}
+
+func bundle(id int32, priority int32, price int64, productIDs ...int32) Rule {
+	return Rule{ID: id, Name: "bundle", Type: TypeBundle, Priority: priority, BundleProductIDs: productIDs, BundlePrice: price}
+}
+
+func TestEvaluate(t *testing.T) {
+	testCases := []struct {
+		name      string
+		rules     []Rule
+		lines     []Line
+		discounts []int64
+		applied   [][]int32
+	}{
+		{
+			name:      "NoRules",
+			lines:     []Line{{ProductID: 1, CategoryID: 5, Quantity: 3, UnitPrice: 1000}},
+			discounts: []int64{0},
+			applied:   [][]int32{nil},
+		},
+		{
+			name:      "BuyTwoGetOneFree",
+			rules:     []Rule{buyTwoGetOne(1, 0, 5)},
+			lines:     []Line{{ProductID: 1, CategoryID: 5, Quantity: 7, UnitPrice: 1000}},
+			discounts: []int64{2000},
+			applied:   [][]int32{{1}},
+		},
+		{
+			name:  "BuyTwoGetOneCheapestFree",
+			rules: []Rule{buyTwoGetOne(1, 0, 5)},
+			lines: []Line{
+				{ProductID: 1, CategoryID: 5, Quantity: 1, UnitPrice: 500},
+				{ProductID: 2, CategoryID: 5, Quantity: 2, UnitPrice: 3000},
+				{ProductID: 3, CategoryID: 6, Quantity: 1, UnitPrice: 100},
+			},
+			discounts: []int64{500, 0, 0},
+			applied:   [][]int32{{1}, nil, nil},
+		},
+		{
+			name:  "BuyTwoGetOneHalfOff",
+			rules: []Rule{{ID: 1, Type: TypeBuyXGetY, Buy: 2, Get: 1, Percent: 5000}},
+			lines: []Line{
+				{ProductID: 1, Quantity: 2, UnitPrice: 1000},
+				{ProductID: 2, Quantity: 1, UnitPrice: 801},
+			},
+			discounts: []int64{0, 400},
+			applied:   [][]int32{nil, {1}},
+		},
+		{
+			name:  "TierPickedFromAmount",
+			rules: []Rule{{ID: 1, Type: TypeTiered, Tiers: []Tier{{MinAmount: 10000, Percent: 500}, {MinAmount: 50000, Percent: 1000}}}},
+			lines: []Line{
+				{ProductID: 1, Quantity: 2, UnitPrice: 20000},
+				{ProductID: 2, Quantity: 1, UnitPrice: 20000},
+			},
+			discounts: []int64{4000, 2000},
+			applied:   [][]int32{{1}, {1}},
+		},
+		{
+			name:      "TierNotReached",
+			rules:     []Rule{tenPercentOver(1, 0, 50000)},
+			lines:     []Line{{ProductID: 1, Quantity: 2, UnitPrice: 20000}},
+			discounts: []int64{0},
+			applied:   [][]int32{nil},
+		},
+		{
+			name:  "Bundle",
+			rules: []Rule{bundle(1, 0, 5000, 1, 2)},
+			lines: []Line{
+				{ProductID: 1, Quantity: 2, UnitPrice: 4000},
+				{ProductID: 2, Quantity: 1, UnitPrice: 2000},
+			},
+			discounts: []int64{667, 333},
+			applied:   [][]int32{{1}, {1}},
+		},
+		{
+			name:  "BundleIncomplete",
+			rules: []Rule{bundle(1, 0, 5000, 1, 2)},
+			lines: []Line{
+				{ProductID: 1, Quantity: 2, UnitPrice: 4000},
+			},
+			discounts: []int64{0},
+			applied:   [][]int32{nil},
+		},
+		{
+			name:  "BundleMoreExpensive",
+			rules: []Rule{bundle(1, 0, 7000, 1, 2)},
+			lines: []Line{
+				{ProductID: 1, Quantity: 1, UnitPrice: 4000},
+				{ProductID: 2, Quantity: 1, UnitPrice: 2000},
+			},
+			discounts: []int64{0, 0},
+			applied:   [][]int32{nil, nil},
+		},
+		{
+			name: "ClaimedUnitsAreNotReused",
+			rules: []Rule{
+				bundle(2, 10, 5000, 1, 2),
+				buyTwoGetOne(1, 0),
+			},
+			lines: []Line{
+				{ProductID: 1, Quantity: 1, UnitPrice: 4000},
+				{ProductID: 2, Quantity: 3, UnitPrice: 2000},
+			},
+			discounts: []int64{667, 333},
+			applied:   [][]int32{{2}, {2}},
+		},
+		{
+			name: "PriorityDecidesWhoClaims",
+			rules: []Rule{
+				bundle(2, 0, 5000, 1, 2),
+				buyTwoGetOne(1, 10),
+			},
+			lines: []Line{
+				{ProductID: 1, Quantity: 1, UnitPrice: 4000},
+				{ProductID: 2, Quantity: 3, UnitPrice: 2000},
+			},
+			discounts: []int64{0, 2000},
+			applied:   [][]int32{nil, {1}},
+		},
+		{
+			name: "TieredStacksOnWhatIsLeft",
+			rules: []Rule{
+				tenPercentOver(2, 0, 5000),
+				buyTwoGetOne(1, 10),
+			},
+			lines:     []Line{{ProductID: 1, Quantity: 3, UnitPrice: 3000}},
+			discounts: []int64{3600},
+			applied:   [][]int32{{1, 2}},
+		},
+		{
+			name: "TierMissedAfterEarlierDiscount",
+			rules: []Rule{
+				tenPercentOver(2, 0, 8000),
+				buyTwoGetOne(1, 10),
+			},
+			lines:     []Line{{ProductID: 1, Quantity: 3, UnitPrice: 3000}},
+			discounts: []int64{3000},
+			applied:   [][]int32{{1}},
+		},
+		{
+			name: "ExclusiveSkippedAfterAnotherApplied",
+			rules: []Rule{
+				buyTwoGetOne(1, 10),
+				{ID: 2, Type: TypeTiered, Exclusive: true, Tiers: []Tier{{Percent: 5000}}},
+			},
+			lines:     []Line{{ProductID: 1, Quantity: 3, UnitPrice: 3000}},
+			discounts: []int64{3000},
+			applied:   [][]int32{{1}},
+		},
+		{
+			name: "ExclusiveEndsEvaluation",
+			rules: []Rule{
+				{ID: 2, Type: TypeTiered, Priority: 10, Exclusive: true, Tiers: []Tier{{Percent: 5000}}},
+				buyTwoGetOne(1, 0),
+			},
+			lines:     []Line{{ProductID: 1, Quantity: 3, UnitPrice: 3000}},
+			discounts: []int64{4500},
+			applied:   [][]int32{{2}},
+		},
+		{
+			name: "ExclusiveThatDoesNotApply",
+			rules: []Rule{
+				{ID: 2, Type: TypeTiered, Priority: 10, Exclusive: true, Tiers: []Tier{{MinAmount: 100000, Percent: 5000}}},
+				buyTwoGetOne(1, 0),
+			},
+			lines:     []Line{{ProductID: 1, Quantity: 3, UnitPrice: 3000}},
+			discounts: []int64{3000},
+			applied:   [][]int32{{1}},
+		},
+		{
+			name: "SamePriorityByID",
+			rules: []Rule{
+				{ID: 3, Type: TypeTiered, Tiers: []Tier{{Percent: 10000}}},
+				{ID: 2, Type: TypeTiered, Tiers: []Tier{{Percent: 5000}}},
+			},
+			lines:     []Line{{ProductID: 1, Quantity: 1, UnitPrice: 1000}},
+			discounts: []int64{1000},
+			applied:   [][]int32{{2, 3}},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result := Evaluate(tc.rules, tc.lines)
+			require.Equal(t, tc.discounts, result.Discounts)
+
+			var total int64
+			for i, adjustments := range result.Adjustments {
+				var ids []int32
+				var sum int64
+				for _, adjustment := range adjustments {
+					ids = append(ids, adjustment.PromotionID)
+					sum += adjustment.Amount
+				}
+				require.Equal(t, tc.applied[i], ids)
+				require.Equal(t, result.Discounts[i], sum)
+				total += sum
+			}
+			require.Equal(t, total, result.Total)
+		})
+	}
+}
+
+func TestEvaluateIsDeterministic(t *testing.T) {
+	rules := []Rule{tenPercentOver(3, 0, 0), bundle(2, 5, 5000, 1, 2), buyTwoGetOne(1, 5)}
+	lines := []Line{
+		{ProductID: 1, Quantity: 2, UnitPrice: 4000},
+		{ProductID: 2, Quantity: 4, UnitPrice: 2000},
+	}
+
+	expected := Evaluate(rules, lines)
+	for i := 0; i < 10; i++ {
+		reversed := []Rule{rules[2], rules[1], rules[0]}
+		require.Equal(t, expected, Evaluate(reversed, lines))
+	}
+}
+
+func TestEvaluateHugeQuantities(t *testing.T) {
+	// units are counted per line, not allocated one by one
+	lines := []Line{
+		{ProductID: 1, CategoryID: 5, Quantity: math.MaxInt32, UnitPrice: 300},
+		{ProductID: 2, CategoryID: 6, Quantity: math.MaxInt32, UnitPrice: 100},
+		{ProductID: 3, CategoryID: 6, Quantity: math.MaxInt32, UnitPrice: 100},
+	}
+
+	result := Evaluate([]Rule{buyTwoGetOne(1, 5, 5), bundle(2, 0, 150, 2, 3)}, lines)
+
+	// 2147483647 units make 715827882 groups of three, one unit left over
+	require.Equal(t, int64(715827882*300), result.Discounts[0])
+	require.Equal(t, int64(math.MaxInt32*25), result.Discounts[1])
+	require.Equal(t, int64(math.MaxInt32*25), result.Discounts[2])
+}
+
+func TestNewRule(t *testing.T) {
+	testCases := []struct {
+		name       string
+		ruleType   Type
+		conditions Conditions
+		check      func(t *testing.T, rule Rule, err error)
+	}{
+		{
+			name:       "BuyXGetY",
+			ruleType:   TypeBuyXGetY,
+			conditions: Conditions{CategoryIDs: []int32{5}, Buy: 2, Get: 1},
+			check: func(t *testing.T, rule Rule, err error) {
+				require.NoError(t, err)
+				require.Equal(t, int64(10000), rule.Percent)
+				require.Equal(t, "buy 2 get 1 free", rule.description())
+			},
+		},
+		{
+			name:       "BuyXGetYPercent",
+			ruleType:   TypeBuyXGetY,
+			conditions: Conditions{Buy: 1, Get: 1, Percent: "50.00"},
+			check: func(t *testing.T, rule Rule, err error) {
+				require.NoError(t, err)
+				require.Equal(t, int64(5000), rule.Percent)
+				require.Equal(t, "buy 1 get 1 at 50.00% off", rule.description())
+			},
+		},
+		{
+			name:       "BuyXGetYWithoutGet",
+			ruleType:   TypeBuyXGetY,
+			conditions: Conditions{Buy: 2},
+			check: func(t *testing.T, rule Rule, err error) {
+				require.ErrorIs(t, err, ErrInvalidRule)
+			},
+		},
+		{
+			name:     "TiersSorted",
+			ruleType: TypeTiered,
+			conditions: Conditions{Tiers: []TierConditions{
+				{MinAmount: "1000.00", Percent: "15"},
+				{MinAmount: "500.00", Percent: "10"},
+			}},
+			check: func(t *testing.T, rule Rule, err error) {
+				require.NoError(t, err)
+				require.Equal(t, []Tier{{MinAmount: 50000, Percent: 1000}, {MinAmount: 100000, Percent: 1500}}, rule.Tiers)
+			},
+		},
+		{
+			name:     "TiersDuplicated",
+			ruleType: TypeTiered,
+			conditions: Conditions{Tiers: []TierConditions{
+				{MinAmount: "500.00", Percent: "15"},
+				{MinAmount: "500", Percent: "10"},
+			}},
+			check: func(t *testing.T, rule Rule, err error) {
+				require.ErrorIs(t, err, ErrInvalidRule)
+			},
+		},
+		{
+			name:       "TierOverHundredPercent",
+			ruleType:   TypeTiered,
+			conditions: Conditions{Tiers: []TierConditions{{MinAmount: "0", Percent: "101"}}},
+			check: func(t *testing.T, rule Rule, err error) {
+				require.ErrorIs(t, err, ErrInvalidRule)
+			},
+		},
+		{
+			name:       "Bundle",
+			ruleType:   TypeBundle,
+			conditions: Conditions{BundleProductIDs: []int32{1, 2}, BundlePrice: "99.90", CategoryIDs: []int32{5}},
+			check: func(t *testing.T, rule Rule, err error) {
+				require.NoError(t, err)
+				require.Equal(t, int64(9990), rule.BundlePrice)
+				require.Empty(t, rule.CategoryIDs)
+			},
+		},
+		{
+			name:       "BundleOfOneProduct",
+			ruleType:   TypeBundle,
+			conditions: Conditions{BundleProductIDs: []int32{1, 1}, BundlePrice: "99.90"},
+			check: func(t *testing.T, rule Rule, err error) {
+				require.ErrorIs(t, err, ErrInvalidRule)
+			},
+		},
+		{
+			name:       "BundleWithoutPrice",
+			ruleType:   TypeBundle,
+			conditions: Conditions{BundleProductIDs: []int32{1, 2}},
+			check: func(t *testing.T, rule Rule, err error) {
+				require.ErrorIs(t, err, ErrInvalidRule)
+			},
+		},
+		{
+			name:     "UnsupportedType",
+			ruleType: "percentage",
+			check: func(t *testing.T, rule Rule, err error) {
+				require.ErrorIs(t, err, ErrInvalidRule)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rule, err := NewRule(1, tc.name, tc.ruleType, 0, false, tc.conditions)
+			tc.check(t, rule, err)
+		})
+	}
+}
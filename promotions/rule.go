@@ -0,0 +1,204 @@
+package promotions
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+
+	"github.com/cihanalici/api/util"
+)
+
+// Type selects how a rule computes its discount
+type Type string
+
+const (
+	// TypeBuyXGetY makes every Get units after Buy units of the eligible
+	// products free, or Percent off them
+	TypeBuyXGetY Type = "buy_x_get_y"
+	// TypeTiered takes the percentage of the highest tier reached off the
+	// eligible lines
+	TypeTiered Type = "tiered"
+	// TypeBundle sells one unit of each bundled product for a fixed price
+	TypeBundle Type = "bundle"
+)
+
+var ErrInvalidRule = errors.New("invalid promotion rule")
+
+// IsSupportedType returns true if promotions of the type can be created
+func IsSupportedType(ruleType Type) bool {
+	switch ruleType {
+	case TypeBuyXGetY, TypeTiered, TypeBundle:
+		return true
+	}
+	return false
+}
+
+// Conditions are the parameters of a rule as they are stored and sent over
+// the API. Amounts are decimal strings and percentages are written the same
+// way, "10.00" meaning 10%. Only the fields of the rule's type are used.
+type Conditions struct {
+	// ProductIDs and CategoryIDs restrict buy_x_get_y and tiered rules,
+	// without them every product is eligible
+	ProductIDs  []int32 `json:"product_ids,omitempty"`
+	CategoryIDs []int32 `json:"category_ids,omitempty"`
+	Buy         int32   `json:"buy,omitempty"`
+	Get         int32   `json:"get,omitempty"`
+	// Percent is taken off the Get units, it defaults to 100
+	Percent          string           `json:"percent,omitempty"`
+	Tiers            []TierConditions `json:"tiers,omitempty"`
+	BundleProductIDs []int32          `json:"bundle_product_ids,omitempty"`
+	BundlePrice      string           `json:"bundle_price,omitempty"`
+}
+
+type TierConditions struct {
+	MinAmount string `json:"min_amount"`
+	Percent   string `json:"percent"`
+}
+
+// Tier is reached when the eligible lines cost at least MinAmount cents,
+// Percent is in hundredths of a percent
+type Tier struct {
+	MinAmount int64
+	Percent   int64
+}
+
+// Rule is a validated promotion ready to be evaluated. Rules with a higher
+// Priority are evaluated first, see Evaluate.
+type Rule struct {
+	ID        int32
+	Name      string
+	Type      Type
+	Priority  int32
+	Exclusive bool
+
+	ProductIDs  []int32
+	CategoryIDs []int32
+
+	Buy     int32
+	Get     int32
+	Percent int64
+
+	// Tiers are sorted by MinAmount
+	Tiers []Tier
+
+	BundleProductIDs []int32
+	BundlePrice      int64
+}
+
+// NewRule validates the conditions of a rule of the given type
+func NewRule(id int32, name string, ruleType Type, priority int32, exclusive bool, conditions Conditions) (Rule, error) {
+	rule := Rule{
+		ID:          id,
+		Name:        name,
+		Type:        ruleType,
+		Priority:    priority,
+		Exclusive:   exclusive,
+		ProductIDs:  conditions.ProductIDs,
+		CategoryIDs: conditions.CategoryIDs,
+	}
+
+	switch ruleType {
+	case TypeBuyXGetY:
+		if conditions.Buy <= 0 || conditions.Get <= 0 {
+			return rule, fmt.Errorf("%w: buy and get must be positive", ErrInvalidRule)
+		}
+		rule.Buy = conditions.Buy
+		rule.Get = conditions.Get
+
+		rule.Percent = 10000
+		if conditions.Percent != "" {
+			percent, err := parsePercent(conditions.Percent)
+			if err != nil {
+				return rule, err
+			}
+			rule.Percent = percent
+		}
+
+	case TypeTiered:
+		if len(conditions.Tiers) == 0 {
+			return rule, fmt.Errorf("%w: at least one tier is required", ErrInvalidRule)
+		}
+
+		for _, tier := range conditions.Tiers {
+			minAmount, err := util.ParseMoney(tier.MinAmount)
+			if err != nil || minAmount < 0 {
+				return rule, fmt.Errorf("%w: invalid tier amount %q", ErrInvalidRule, tier.MinAmount)
+			}
+
+			percent, err := parsePercent(tier.Percent)
+			if err != nil {
+				return rule, err
+			}
+
+			rule.Tiers = append(rule.Tiers, Tier{MinAmount: minAmount, Percent: percent})
+		}
+
+		sort.Slice(rule.Tiers, func(i, j int) bool {
+			return rule.Tiers[i].MinAmount < rule.Tiers[j].MinAmount
+		})
+		for i := 1; i < len(rule.Tiers); i++ {
+			if rule.Tiers[i].MinAmount == rule.Tiers[i-1].MinAmount {
+				return rule, fmt.Errorf("%w: two tiers start at %s", ErrInvalidRule, util.FormatMoney(rule.Tiers[i].MinAmount))
+			}
+		}
+
+	case TypeBundle:
+		if len(conditions.BundleProductIDs) < 2 {
+			return rule, fmt.Errorf("%w: a bundle needs at least two products", ErrInvalidRule)
+		}
+		for i, productID := range conditions.BundleProductIDs {
+			if productID <= 0 || slices.Contains(conditions.BundleProductIDs[:i], productID) {
+				return rule, fmt.Errorf("%w: bundle products must be distinct", ErrInvalidRule)
+			}
+		}
+		rule.BundleProductIDs = conditions.BundleProductIDs
+
+		price, err := util.ParseMoney(conditions.BundlePrice)
+		if err != nil || price <= 0 {
+			return rule, fmt.Errorf("%w: invalid bundle price %q", ErrInvalidRule, conditions.BundlePrice)
+		}
+		rule.BundlePrice = price
+
+		// the bundled products are the scope
+		rule.ProductIDs = nil
+		rule.CategoryIDs = nil
+
+	default:
+		return rule, fmt.Errorf("%w: unsupported type %q", ErrInvalidRule, ruleType)
+	}
+
+	return rule, nil
+}
+
+// parsePercent parses "10.00" as 1000 hundredths of a percent
+func parsePercent(s string) (int64, error) {
+	percent, err := util.ParseMoney(s)
+	if err != nil || percent <= 0 || percent > 10000 {
+		return 0, fmt.Errorf("%w: a percentage is between 0 and 100, got %q", ErrInvalidRule, s)
+	}
+	return percent, nil
+}
+
+// eligible returns true if the rule's product and category restrictions
+// allow the line
+func (rule Rule) eligible(line Line) bool {
+	if len(rule.ProductIDs) == 0 && len(rule.CategoryIDs) == 0 {
+		return true
+	}
+	return slices.Contains(rule.ProductIDs, line.ProductID) || slices.Contains(rule.CategoryIDs, line.CategoryID)
+}
+
+// description explains the rule to customers
+func (rule Rule) description() string {
+	switch rule.Type {
+	case TypeBuyXGetY:
+		if rule.Percent >= 10000 {
+			return fmt.Sprintf("buy %d get %d free", rule.Buy, rule.Get)
+		}
+		return fmt.Sprintf("buy %d get %d at %s%% off", rule.Buy, rule.Get, util.FormatMoney(rule.Percent))
+	case TypeBundle:
+		return fmt.Sprintf("bundle of %d products for %s", len(rule.BundleProductIDs), util.FormatMoney(rule.BundlePrice))
+	}
+	return ""
+}
@@ -0,0 +1,333 @@
+package token
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
+)
+
+const minRSAKeyBits = 2048
+
+var ErrCannotSign = errors.New("token maker has no signing key")
+
+// verificationKey is a public key trusted to verify tokens together with the
+// only algorithm it may be used with
+type verificationKey struct {
+	id     string
+	method jwt.SigningMethod
+	key    crypto.PublicKey
+}
+
+// AsymmetricJWTMaker signs tokens with an RSA (RS256) or Ed25519 (EdDSA)
+// private key and verifies them with any of its trusted public keys, picked by
+// the kid header. Old public keys stay trusted after a rotation so tokens
+// issued before it keep working until they expire.
+type AsymmetricJWTMaker struct {
+	signingKey       crypto.Signer
+	signingKeyID     string
+	signingMethod    jwt.SigningMethod
+	verificationKeys []verificationKey
+}
+
+// KeySetProvider is implemented by makers whose verification keys can be
+// published as a JSON Web Key Set
+type KeySetProvider interface {
+	KeySet() JSONWebKeySet
+}
+
+type JSONWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+type JSONWebKeySet struct {
+	Keys []JSONWebKey `json:"keys"`
+}
+
+// NewAsymmetricJWTMakerFromFiles loads the PEM encoded signing key and any
+// additional PEM encoded public keys that should still be accepted
+func NewAsymmetricJWTMakerFromFiles(privateKeyPath string, publicKeyPaths []string) (Maker, error) {
+	privateKeyPEM, err := os.ReadFile(privateKeyPath)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read private key: %w", err)
+	}
+
+	publicKeysPEM := make([][]byte, 0, len(publicKeyPaths))
+	for _, path := range publicKeyPaths {
+		publicKeyPEM, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("cannot read public key: %w", err)
+		}
+		publicKeysPEM = append(publicKeysPEM, publicKeyPEM)
+	}
+
+	return NewAsymmetricJWTMaker(privateKeyPEM, publicKeysPEM...)
+}
+
+// NewAsymmetricJWTMaker creates a new AsymmetricJWTMaker from a PEM encoded
+// PKCS#8 or PKCS#1 private key and optional PEM encoded PKIX public keys
+func NewAsymmetricJWTMaker(privateKeyPEM []byte, publicKeysPEM ...[]byte) (Maker, error) {
+	block, _ := pem.Decode(privateKeyPEM)
+	if block == nil {
+		return nil, errors.New("invalid private key: no PEM data found")
+	}
+
+	var privateKey any
+	var err error
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		privateKey, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	default:
+		privateKey, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid private key: %w", err)
+	}
+
+	signer, ok := privateKey.(crypto.Signer)
+	if !ok {
+		return nil, errors.New("invalid private key: unsupported key type")
+	}
+
+	signingKey, err := newVerificationKey(signer.Public())
+	if err != nil {
+		return nil, err
+	}
+
+	maker := &AsymmetricJWTMaker{
+		signingKey:       signer,
+		signingKeyID:     signingKey.id,
+		signingMethod:    signingKey.method,
+		verificationKeys: []verificationKey{signingKey},
+	}
+
+	for _, publicKeyPEM := range publicKeysPEM {
+		block, _ := pem.Decode(publicKeyPEM)
+		if block == nil {
+			return nil, errors.New("invalid public key: no PEM data found")
+		}
+
+		publicKey, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("invalid public key: %w", err)
+		}
+
+		if err := maker.addVerificationKey(publicKey); err != nil {
+			return nil, err
+		}
+	}
+
+	return maker, nil
+}
+
+// NewJWKSVerifier creates a Maker that can only verify tokens, using the keys
+// published by another service's /.well-known/jwks.json
+func NewJWKSVerifier(keySet []byte) (Maker, error) {
+	var set JSONWebKeySet
+	if err := json.Unmarshal(keySet, &set); err != nil {
+		return nil, fmt.Errorf("invalid key set: %w", err)
+	}
+
+	maker := &AsymmetricJWTMaker{}
+	for _, jwk := range set.Keys {
+		publicKey, err := jwk.publicKey()
+		if err != nil {
+			return nil, err
+		}
+
+		if err := maker.addVerificationKey(publicKey); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(maker.verificationKeys) == 0 {
+		return nil, errors.New("invalid key set: no keys")
+	}
+
+	return maker, nil
+}
+
+func (maker *AsymmetricJWTMaker) addVerificationKey(publicKey crypto.PublicKey) error {
+	key, err := newVerificationKey(publicKey)
+	if err != nil {
+		return err
+	}
+
+	for _, existing := range maker.verificationKeys {
+		if existing.id == key.id {
+			return nil
+		}
+	}
+
+	maker.verificationKeys = append(maker.verificationKeys, key)
+	return nil
+}
+
+// newVerificationKey picks the signing method for a public key and derives
+// its kid from the RFC 7638 JWK thumbprint
+func newVerificationKey(publicKey crypto.PublicKey) (verificationKey, error) {
+	jwk, err := newJSONWebKey(publicKey)
+	if err != nil {
+		return verificationKey{}, err
+	}
+
+	method := jwt.GetSigningMethod(jwk.Alg)
+	return verificationKey{id: jwk.Kid, method: method, key: publicKey}, nil
+}
+
+func newJSONWebKey(publicKey crypto.PublicKey) (JSONWebKey, error) {
+	var jwk JSONWebKey
+	var thumbprintInput string
+
+	switch key := publicKey.(type) {
+	case *rsa.PublicKey:
+		if key.N.BitLen() < minRSAKeyBits {
+			return jwk, fmt.Errorf("invalid key size: RSA keys must be at least %d bits", minRSAKeyBits)
+		}
+
+		jwk = JSONWebKey{
+			Kty: "RSA",
+			Alg: jwt.SigningMethodRS256.Alg(),
+			N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}
+		thumbprintInput = fmt.Sprintf(`{"e":%q,"kty":"RSA","n":%q}`, jwk.E, jwk.N)
+	case ed25519.PublicKey:
+		jwk = JSONWebKey{
+			Kty: "OKP",
+			Alg: jwt.SigningMethodEdDSA.Alg(),
+			Crv: "Ed25519",
+			X:   base64.RawURLEncoding.EncodeToString(key),
+		}
+		thumbprintInput = fmt.Sprintf(`{"crv":"Ed25519","kty":"OKP","x":%q}`, jwk.X)
+	default:
+		return jwk, errors.New("unsupported key type: only RSA and Ed25519 keys are supported")
+	}
+
+	thumbprint := sha256.Sum256([]byte(thumbprintInput))
+	jwk.Kid = base64.RawURLEncoding.EncodeToString(thumbprint[:])
+	jwk.Use = "sig"
+
+	return jwk, nil
+}
+
+// publicKey decodes a published JWK back into a public key
+func (jwk JSONWebKey) publicKey() (crypto.PublicKey, error) {
+	switch {
+	case jwk.Kty == "RSA":
+		n, err := base64.RawURLEncoding.DecodeString(jwk.N)
+		if err != nil {
+			return nil, fmt.Errorf("invalid key %q: %w", jwk.Kid, err)
+		}
+
+		e, err := base64.RawURLEncoding.DecodeString(jwk.E)
+		if err != nil || len(e) == 0 || len(e) > 4 {
+			return nil, fmt.Errorf("invalid key %q: bad exponent", jwk.Kid)
+		}
+
+		return &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}, nil
+	case jwk.Kty == "OKP" && jwk.Crv == "Ed25519":
+		x, err := base64.RawURLEncoding.DecodeString(jwk.X)
+		if err != nil || len(x) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("invalid key %q: bad public key", jwk.Kid)
+		}
+
+		return ed25519.PublicKey(x), nil
+	default:
+		return nil, fmt.Errorf("invalid key %q: unsupported key type %q", jwk.Kid, jwk.Kty)
+	}
+}
+
+// CreateToken creates a new token of the given type for a specific userID, role, session and duration
+func (maker *AsymmetricJWTMaker) CreateToken(userID int32, role string, sessionID uuid.UUID, tokenType TokenType, duration time.Duration) (string, *Payload, error) {
+	if maker.signingKey == nil {
+		return "", nil, ErrCannotSign
+	}
+
+	payload, err := NewPayload(userID, role, sessionID, tokenType, duration)
+	if err != nil {
+		return "", nil, err
+	}
+
+	jwtToken := jwt.NewWithClaims(maker.signingMethod, payload)
+	jwtToken.Header["kid"] = maker.signingKeyID
+
+	token, err := jwtToken.SignedString(maker.signingKey)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return token, payload, nil
+}
+
+// VerifyToken verifies the token, checks that it has the expected type and returns the payload
+func (maker *AsymmetricJWTMaker) VerifyToken(tokenString string, tokenType TokenType) (*Payload, error) {
+	keyFunc := func(token *jwt.Token) (interface{}, error) {
+		kid, ok := token.Header["kid"].(string)
+		if !ok {
+			return nil, ErrInvalidToken
+		}
+
+		for _, key := range maker.verificationKeys {
+			// the algorithm is bound to the key, never taken from the token
+			if key.id == kid && key.method.Alg() == token.Method.Alg() {
+				return key.key, nil
+			}
+		}
+
+		return nil, ErrInvalidToken
+	}
+
+	validMethods := []string{jwt.SigningMethodRS256.Alg(), jwt.SigningMethodEdDSA.Alg()}
+	token, err := jwt.ParseWithClaims(tokenString, &Payload{}, keyFunc, jwt.WithValidMethods(validMethods))
+	if err != nil {
+		verr, ok := err.(*jwt.ValidationError)
+		if ok && errors.Is(verr.Inner, ErrExpiredToken) {
+			return nil, ErrExpiredToken
+		}
+		return nil, ErrInvalidToken
+	}
+
+	payload, ok := token.Claims.(*Payload)
+	if !ok || payload.Type != tokenType {
+		return nil, ErrInvalidToken
+	}
+
+	return payload, nil
+}
+
+// KeySet returns the public keys trusted by this maker as a JSON Web Key Set,
+// with the current signing key first
+func (maker *AsymmetricJWTMaker) KeySet() JSONWebKeySet {
+	set := JSONWebKeySet{Keys: []JSONWebKey{}}
+	for _, key := range maker.verificationKeys {
+		jwk, err := newJSONWebKey(key.key)
+		if err != nil {
+			continue
+		}
+		set.Keys = append(set.Keys, jwk)
+	}
+	return set
+}
@@ -0,0 +1,151 @@
+package token
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func newRSAKeyPEM(t *testing.T) ([]byte, []byte) {
+	key, err := rsa.GenerateKey(rand.Reader, minRSAKeyBits)
+	require.NoError(t, err)
+	return encodeKeyPairPEM(t, key, &key.PublicKey)
+}
+
+func newEd25519KeyPEM(t *testing.T) ([]byte, []byte) {
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	return encodeKeyPairPEM(t, privateKey, publicKey)
+}
+
+func encodeKeyPairPEM(t *testing.T, privateKey crypto.PrivateKey, publicKey crypto.PublicKey) ([]byte, []byte) {
+	privateDER, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	require.NoError(t, err)
+
+	publicDER, err := x509.MarshalPKIXPublicKey(publicKey)
+	require.NoError(t, err)
+
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privateDER}),
+		pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER})
+}
+
+func TestAsymmetricJWTMakerRS256(t *testing.T) {
+	privateKeyPEM, _ := newRSAKeyPEM(t)
+
+	maker, err := NewAsymmetricJWTMaker(privateKeyPEM)
+	require.NoError(t, err)
+
+	testMakerConformance(t, maker)
+}
+
+func TestAsymmetricJWTMakerEdDSA(t *testing.T) {
+	privateKeyPEM, _ := newEd25519KeyPEM(t)
+
+	maker, err := NewAsymmetricJWTMaker(privateKeyPEM)
+	require.NoError(t, err)
+
+	testMakerConformance(t, maker)
+}
+
+func TestAsymmetricJWTMakerKeyRotation(t *testing.T) {
+	oldPrivateKeyPEM, oldPublicKeyPEM := newRSAKeyPEM(t)
+	newPrivateKeyPEM, _ := newEd25519KeyPEM(t)
+
+	oldMaker, err := NewAsymmetricJWTMaker(oldPrivateKeyPEM)
+	require.NoError(t, err)
+
+	oldToken, _, err := oldMaker.CreateToken(1, "user", uuid.New(), TokenTypeAccessToken, time.Minute)
+	require.NoError(t, err)
+
+	rotatedMaker, err := NewAsymmetricJWTMaker(newPrivateKeyPEM, oldPublicKeyPEM)
+	require.NoError(t, err)
+
+	// tokens signed before the rotation are still accepted
+	_, err = rotatedMaker.VerifyToken(oldToken, TokenTypeAccessToken)
+	require.NoError(t, err)
+
+	// once the old key is dropped they are not
+	newOnlyMaker, err := NewAsymmetricJWTMaker(newPrivateKeyPEM)
+	require.NoError(t, err)
+
+	_, err = newOnlyMaker.VerifyToken(oldToken, TokenTypeAccessToken)
+	require.ErrorIs(t, err, ErrInvalidToken)
+
+	keySet := rotatedMaker.(KeySetProvider).KeySet()
+	require.Len(t, keySet.Keys, 2)
+	require.Equal(t, "EdDSA", keySet.Keys[0].Alg)
+	require.Equal(t, "RS256", keySet.Keys[1].Alg)
+}
+
+func TestJWKSVerifier(t *testing.T) {
+	privateKeyPEM, _ := newRSAKeyPEM(t)
+
+	maker, err := NewAsymmetricJWTMaker(privateKeyPEM)
+	require.NoError(t, err)
+
+	keySet, err := json.Marshal(maker.(KeySetProvider).KeySet())
+	require.NoError(t, err)
+
+	verifier, err := NewJWKSVerifier(keySet)
+	require.NoError(t, err)
+
+	token, payload, err := maker.CreateToken(1, "user", uuid.New(), TokenTypeAccessToken, time.Minute)
+	require.NoError(t, err)
+
+	verified, err := verifier.VerifyToken(token, TokenTypeAccessToken)
+	require.NoError(t, err)
+	require.Equal(t, payload.ID, verified.ID)
+
+	_, _, err = verifier.CreateToken(1, "user", uuid.New(), TokenTypeAccessToken, time.Minute)
+	require.ErrorIs(t, err, ErrCannotSign)
+}
+
+func TestAsymmetricJWTMakerRejectsAlgorithmConfusion(t *testing.T) {
+	privateKeyPEM, publicKeyPEM := newRSAKeyPEM(t)
+
+	maker, err := NewAsymmetricJWTMaker(privateKeyPEM)
+	require.NoError(t, err)
+
+	kid := maker.(KeySetProvider).KeySet().Keys[0].Kid
+
+	payload, err := NewPayload(1, "admin", uuid.New(), TokenTypeAccessToken, time.Minute)
+	require.NoError(t, err)
+
+	// an HS256 token "signed" with the public key must not verify
+	jwtToken := jwt.NewWithClaims(jwt.SigningMethodHS256, payload)
+	jwtToken.Header["kid"] = kid
+	forged, err := jwtToken.SignedString(publicKeyPEM)
+	require.NoError(t, err)
+
+	_, err = maker.VerifyToken(forged, TokenTypeAccessToken)
+	require.ErrorIs(t, err, ErrInvalidToken)
+
+	// neither must an unsigned one
+	jwtToken = jwt.NewWithClaims(jwt.SigningMethodNone, payload)
+	jwtToken.Header["kid"] = kid
+	unsigned, err := jwtToken.SignedString(jwt.UnsafeAllowNoneSignatureType)
+	require.NoError(t, err)
+
+	_, err = maker.VerifyToken(unsigned, TokenTypeAccessToken)
+	require.ErrorIs(t, err, ErrInvalidToken)
+}
+
+func TestAsymmetricJWTMakerRejectsSmallRSAKeys(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 1024)
+	require.NoError(t, err)
+
+	privateKeyPEM, _ := encodeKeyPairPEM(t, key, &key.PublicKey)
+
+	_, err = NewAsymmetricJWTMaker(privateKeyPEM)
+	require.Error(t, err)
+}
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
 )
 
 const minSecretKeySize = 32
@@ -23,24 +24,24 @@ func NewJWTMaker(secretKey string) (Maker, error) {
 	return &JWTMaker{secretKey}, nil
 }
 
-// CreateToken creates a new token for a specific userID and duration
-func (maker *JWTMaker) CreateToken(userID int32, duration time.Duration) (string, error) {
-	payload, err := NewPayload(userID, duration)
+// CreateToken creates a new token of the given type for a specific userID, role, session and duration
+func (maker *JWTMaker) CreateToken(userID int32, role string, sessionID uuid.UUID, tokenType TokenType, duration time.Duration) (string, *Payload, error) {
+	payload, err := NewPayload(userID, role, sessionID, tokenType, duration)
 	if err != nil {
-		return "", err
+		return "", nil, err
 	}
 
 	jwtToken := jwt.NewWithClaims(jwt.SigningMethodHS256, payload)
 	token, err := jwtToken.SignedString([]byte(maker.secretKey))
 	if err != nil {
-		return "", err
+		return "", nil, err
 	}
 
-	return token, nil
+	return token, payload, nil
 }
 
-// VerifyToken verifies the token and returns the payload
-func (maker *JWTMaker) VerifyToken(tokenString string) (*Payload, error) {
+// VerifyToken verifies the token, checks that it has the expected type and returns the payload
+func (maker *JWTMaker) VerifyToken(tokenString string, tokenType TokenType) (*Payload, error) {
 	keyFunc := func(token *jwt.Token) (interface{}, error) {
 		_, ok := token.Method.(*jwt.SigningMethodHMAC)
 		if !ok {
@@ -50,17 +51,18 @@ func (maker *JWTMaker) VerifyToken(tokenString string) (*Payload, error) {
 		return []byte(maker.secretKey), nil
 	}
 
-	token, err := jwt.ParseWithClaims(tokenString, &Payload{}, keyFunc)
+	// only HS256 is accepted so a token cannot choose a weaker or asymmetric algorithm
+	token, err := jwt.ParseWithClaims(tokenString, &Payload{}, keyFunc, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}))
 	if err != nil {
 		verr, ok := err.(*jwt.ValidationError)
-		if ok && errors.Is(verr.Inner, jwt.ErrSignatureInvalid) {
-			return nil, ErrInvalidToken
+		if ok && errors.Is(verr.Inner, ErrExpiredToken) {
+			return nil, ErrExpiredToken
 		}
 		return nil, ErrInvalidToken
 	}
 
 	payload, ok := token.Claims.(*Payload)
-	if !ok {
+	if !ok || payload.Type != tokenType {
 		return nil, ErrInvalidToken
 	}
 
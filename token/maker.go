@@ -2,21 +2,37 @@ package token
 
 import (
 	"crypto/rand"
+	"crypto/sha256"
 	"encoding/hex"
 	"time"
+
+	"github.com/google/uuid"
 )
 
 type Maker interface {
-	// CreateToken creates a new token for a specific userID and duration
-	CreateToken(userID int32, duration time.Duration) (string, error)
-	// VerifyToken verifies the token and returns the payload
-	VerifyToken(token string) (*Payload, error)
+	// CreateToken creates a new token of the given type for a specific userID, role, session and duration
+	CreateToken(userID int32, role string, sessionID uuid.UUID, tokenType TokenType, duration time.Duration) (string, *Payload, error)
+	// VerifyToken verifies the token, checks that it has the expected type and returns the payload
+	VerifyToken(token string, tokenType TokenType) (*Payload, error)
 }
 
+// GenerateResetToken returns a new password reset token
 func GenerateResetToken() (string, error) {
-	token := make([]byte, 16)
+	return GenerateRandomToken(32)
+}
+
+// GenerateRandomToken returns a hex encoded opaque token made of size random bytes
+func GenerateRandomToken(size int) (string, error) {
+	token := make([]byte, size)
 	if _, err := rand.Read(token); err != nil {
 		return "", err
 	}
 	return hex.EncodeToString(token), nil
 }
+
+// HashToken returns the hex encoded SHA-256 of an opaque token so it can be
+// stored and looked up without keeping the token itself
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,113 @@
+package token
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+const testSymmetricKey = "01234567890123456789012345678901"
+
+// testMakerConformance checks the Payload semantics every Maker must share
+func testMakerConformance(t *testing.T, maker Maker) {
+	t.Run("ValidToken", func(t *testing.T) {
+		sessionID := uuid.New()
+		duration := time.Minute
+		issuedAt := time.Now()
+
+		token, payload, err := maker.CreateToken(1, "user", sessionID, TokenTypeAccessToken, duration)
+		require.NoError(t, err)
+		require.NotEmpty(t, token)
+
+		verified, err := maker.VerifyToken(token, TokenTypeAccessToken)
+		require.NoError(t, err)
+		require.Equal(t, payload.ID, verified.ID)
+		require.Equal(t, TokenTypeAccessToken, verified.Type)
+		require.Equal(t, sessionID, verified.SessionID)
+		require.Equal(t, int32(1), verified.UserID)
+		require.Equal(t, "user", verified.Role)
+		require.WithinDuration(t, issuedAt, verified.IssuedAt, time.Second)
+		require.WithinDuration(t, issuedAt.Add(duration), verified.ExpiredAt, time.Second)
+	})
+
+	t.Run("ExpiredToken", func(t *testing.T) {
+		token, _, err := maker.CreateToken(1, "user", uuid.New(), TokenTypeAccessToken, -time.Minute)
+		require.NoError(t, err)
+
+		verified, err := maker.VerifyToken(token, TokenTypeAccessToken)
+		require.ErrorIs(t, err, ErrExpiredToken)
+		require.Nil(t, verified)
+	})
+
+	t.Run("WrongTokenType", func(t *testing.T) {
+		token, _, err := maker.CreateToken(1, "user", uuid.New(), TokenTypeRefreshToken, time.Minute)
+		require.NoError(t, err)
+
+		verified, err := maker.VerifyToken(token, TokenTypeAccessToken)
+		require.ErrorIs(t, err, ErrInvalidToken)
+		require.Nil(t, verified)
+	})
+
+	t.Run("TamperedToken", func(t *testing.T) {
+		token, _, err := maker.CreateToken(1, "user", uuid.New(), TokenTypeAccessToken, time.Minute)
+		require.NoError(t, err)
+
+		// flip a character in the middle, which is always inside the signed part
+		tampered := []byte(token)
+		i := len(tampered) / 2
+		for tampered[i] == '.' {
+			i++
+		}
+		if tampered[i] == 'A' {
+			tampered[i] = 'B'
+		} else {
+			tampered[i] = 'A'
+		}
+
+		verified, err := maker.VerifyToken(string(tampered), TokenTypeAccessToken)
+		require.ErrorIs(t, err, ErrInvalidToken)
+		require.Nil(t, verified)
+	})
+
+	t.Run("Garbage", func(t *testing.T) {
+		verified, err := maker.VerifyToken("not-a-token", TokenTypeAccessToken)
+		require.ErrorIs(t, err, ErrInvalidToken)
+		require.Nil(t, verified)
+	})
+}
+
+func TestJWTMaker(t *testing.T) {
+	maker, err := NewJWTMaker(testSymmetricKey)
+	require.NoError(t, err)
+
+	testMakerConformance(t, maker)
+}
+
+func TestPasetoMaker(t *testing.T) {
+	maker, err := NewPasetoMaker(testSymmetricKey)
+	require.NoError(t, err)
+
+	testMakerConformance(t, maker)
+}
+
+func TestMakersRejectEachOthersTokens(t *testing.T) {
+	jwtMaker, err := NewJWTMaker(testSymmetricKey)
+	require.NoError(t, err)
+
+	pasetoMaker, err := NewPasetoMaker(testSymmetricKey)
+	require.NoError(t, err)
+
+	jwtToken, _, err := jwtMaker.CreateToken(1, "user", uuid.New(), TokenTypeAccessToken, time.Minute)
+	require.NoError(t, err)
+
+	pasetoToken, _, err := pasetoMaker.CreateToken(1, "user", uuid.New(), TokenTypeAccessToken, time.Minute)
+	require.NoError(t, err)
+
+	_, err = pasetoMaker.VerifyToken(jwtToken, TokenTypeAccessToken)
+	require.ErrorIs(t, err, ErrInvalidToken)
+
+	_, err = jwtMaker.VerifyToken(pasetoToken, TokenTypeAccessToken)
+	require.ErrorIs(t, err, ErrInvalidToken)
+}
@@ -0,0 +1,45 @@
+package token
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// official PASETO test vector 4-E-1
+func TestPasetoV4LocalVector(t *testing.T) {
+	key, err := hex.DecodeString("707172737475767778797a7b7c7d7e7f808182838485868788898a8b8c8d8e8f")
+	require.NoError(t, err)
+
+	maker := &PasetoMaker{symmetricKey: key}
+	nonce := make([]byte, pasetoNonceSize)
+	message := `{"data":"this is a secret message","exp":"2022-01-01T00:00:00+00:00"}`
+	expected := "v4.local.AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAQAr68PS4AXe7If_ZgesdkUMvSwscFlAl1pk5HC0e8kApeaqMfGo_7OpBnwJOAbY9V7WU6abu74MmcUE8YWAiaArVI8XJ5hOb_4v9RmDkneN0S92dx0OW4pgy7omxgf3S8c3LlQg"
+
+	token, err := maker.encrypt([]byte(message), nonce)
+	require.NoError(t, err)
+	require.Equal(t, expected, token)
+
+	decrypted, err := maker.decrypt(expected)
+	require.NoError(t, err)
+	require.Equal(t, message, string(decrypted))
+}
+
+func TestPasetoMakerKeySize(t *testing.T) {
+	_, err := NewPasetoMaker("too-short")
+	require.Error(t, err)
+
+	_, err = NewPasetoMaker(testSymmetricKey + "x")
+	require.Error(t, err)
+}
+
+func TestPasetoMakerRejectsOtherVersions(t *testing.T) {
+	maker, err := NewPasetoMaker(testSymmetricKey)
+	require.NoError(t, err)
+
+	for _, header := range []string{"v4.public.", "v3.local.", "v2.local."} {
+		_, err := maker.VerifyToken(header+"AAAA", TokenTypeAccessToken)
+		require.ErrorIs(t, err, ErrInvalidToken)
+	}
+}
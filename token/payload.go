@@ -12,15 +12,36 @@ var (
 	ErrExpiredToken = errors.New("expired token")
 )
 
+// TokenType tells access tokens, refresh tokens and single purpose tokens
+// apart so one can never be used in place of another
+type TokenType string
+
+const (
+	TokenTypeAccessToken   TokenType = "access"
+	TokenTypeRefreshToken  TokenType = "refresh"
+	TokenTypeUnlockAccount TokenType = "unlock_account"
+	// TokenTypeMfaPending proves the password step of a login that still
+	// needs a second factor
+	TokenTypeMfaPending TokenType = "mfa_pending"
+	// TokenTypeApiKey marks payloads built for requests authenticated with an
+	// API key. Makers never issue tokens of this type.
+	TokenTypeApiKey TokenType = "api_key"
+)
+
 type Payload struct {
 	ID        uuid.UUID `json:"id"`
+	Type      TokenType `json:"token_type"`
+	SessionID uuid.UUID `json:"session_id"`
 	UserID    int32     `json:"user_id"`
+	Role      string    `json:"role"`
 	IssuedAt  time.Time `json:"issued_at"`
 	ExpiredAt time.Time `json:"expired_at"`
+	// Scopes limits what an API key may do, it is empty for user tokens
+	Scopes []string `json:"scopes,omitempty"`
 }
 
-// NewPayload creates and returns a new Payload
-func NewPayload(userID int32, duration time.Duration) (*Payload, error) {
+// NewPayload creates and returns a new Payload bound to the given login session
+func NewPayload(userID int32, role string, sessionID uuid.UUID, tokenType TokenType, duration time.Duration) (*Payload, error) {
 	tokenId, err := uuid.NewRandom()
 	if err != nil {
 		return nil, err
@@ -28,7 +49,10 @@ func NewPayload(userID int32, duration time.Duration) (*Payload, error) {
 
 	payload := &Payload{
 		ID:        tokenId,
+		Type:      tokenType,
+		SessionID: sessionID,
 		UserID:    userID,
+		Role:      role,
 		IssuedAt:  time.Now(),
 		ExpiredAt: time.Now().Add(duration),
 	}
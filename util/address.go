@@ -0,0 +1,56 @@
+package util
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// addressRule holds what a country needs on top of the fields every address
+// has: the recipient, the first line, the city and the country itself
+type addressRule struct {
+	stateRequired      bool
+	postalCodeRequired bool
+	postalCode         *regexp.Regexp
+}
+
+// addressRules covers the countries we ship to most. Other countries only
+// need the common fields and accept any postal code.
+var addressRules = map[string]addressRule{
+	"US": {stateRequired: true, postalCodeRequired: true, postalCode: regexp.MustCompile(`^\d{5}(-\d{4})?$`)},
+	"CA": {stateRequired: true, postalCodeRequired: true, postalCode: regexp.MustCompile(`^[A-Za-z]\d[A-Za-z] ?\d[A-Za-z]\d$`)},
+	"AU": {stateRequired: true, postalCodeRequired: true, postalCode: regexp.MustCompile(`^\d{4}$`)},
+	"GB": {postalCodeRequired: true, postalCode: regexp.MustCompile(`^[A-Za-z]{1,2}\d[A-Za-z\d]? ?\d[A-Za-z]{2}$`)},
+	"DE": {postalCodeRequired: true, postalCode: regexp.MustCompile(`^\d{5}$`)},
+	"FR": {postalCodeRequired: true, postalCode: regexp.MustCompile(`^\d{5}$`)},
+	"TR": {stateRequired: true, postalCodeRequired: true, postalCode: regexp.MustCompile(`^\d{5}$`)},
+	"NL": {postalCodeRequired: true, postalCode: regexp.MustCompile(`^\d{4} ?[A-Za-z]{2}$`)},
+	"IE": {postalCode: regexp.MustCompile(`^[A-Za-z]\d[\dWw] ?[A-Za-z\d]{4}$`)},
+}
+
+// ValidateAddressForCountry checks the fields whose rules depend on the
+// country. The country is an ISO 3166-1 alpha-2 code.
+func ValidateAddressForCountry(country, state, postalCode string) error {
+	rule, ok := addressRules[strings.ToUpper(country)]
+	if !ok {
+		return nil
+	}
+
+	if rule.stateRequired && strings.TrimSpace(state) == "" {
+		return fmt.Errorf("state is required for addresses in %s", strings.ToUpper(country))
+	}
+
+	postalCode = strings.TrimSpace(postalCode)
+	if postalCode == "" {
+		if rule.postalCodeRequired {
+			return fmt.Errorf("postal code is required for addresses in %s", strings.ToUpper(country))
+		}
+		return nil
+	}
+
+	if rule.postalCode != nil && !rule.postalCode.MatchString(postalCode) {
+		return fmt.Errorf("invalid postal code %q for %s", postalCode, strings.ToUpper(country))
+	}
+
+	return nil
+}
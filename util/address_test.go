@@ -0,0 +1,40 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateAddressForCountry(t *testing.T) {
+	testCases := []struct {
+		name       string
+		country    string
+		state      string
+		postalCode string
+		valid      bool
+	}{
+		{name: "US", country: "US", state: "NY", postalCode: "10001", valid: true},
+		{name: "USZipPlusFour", country: "us", state: "NY", postalCode: "10001-1234", valid: true},
+		{name: "USMissingState", country: "US", postalCode: "10001", valid: false},
+		{name: "USMissingPostalCode", country: "US", state: "NY", valid: false},
+		{name: "USInvalidPostalCode", country: "US", state: "NY", postalCode: "1000", valid: false},
+		{name: "GB", country: "GB", postalCode: "SW1A 1AA", valid: true},
+		{name: "GBInvalidPostalCode", country: "GB", postalCode: "12345", valid: false},
+		{name: "TR", country: "TR", state: "Istanbul", postalCode: "34000", valid: true},
+		{name: "IEWithoutEircode", country: "IE", valid: true},
+		{name: "IEInvalidEircode", country: "IE", postalCode: "123", valid: false},
+		{name: "UnlistedCountry", country: "JP", valid: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := ValidateAddressForCountry(tc.country, tc.state, tc.postalCode)
+			if tc.valid {
+				require.NoError(t, err)
+			} else {
+				require.Error(t, err)
+			}
+		})
+	}
+}
@@ -9,13 +9,40 @@ import (
 // Config is the configuration for the application
 // It should be loaded from a file or other sources
 type Config struct {
-	DBDriver                 string        `mapstructure:"DB_DRIVER"`
-	DBSource                 string        `mapstructure:"DB_SOURCE"`
-	ServerAddress            string        `mapstructure:"SERVER_ADDRESS"`
-	TokenSymmetricKey        string        `mapstructure:"TOKEN_SYMMETRIC_KEY"`
-	AccessTokenDuration      time.Duration `mapstructure:"ACCESS_TOKEN_DURATION"`
-	ResetPasswordDuration    time.Duration `mapstructure:"RESET_PASSWORD_DURATION"`
-	ResetPasswordRedirectURL string        `mapstructure:"RESET_PASSWORD_REDIRECT_URL"`
+	DBDriver                     string        `mapstructure:"DB_DRIVER"`
+	DBSource                     string        `mapstructure:"DB_SOURCE"`
+	ServerAddress                string        `mapstructure:"SERVER_ADDRESS"`
+	TrustedProxies               []string      `mapstructure:"TRUSTED_PROXIES"`
+	TokenType                    string        `mapstructure:"TOKEN_TYPE"`
+	TokenSymmetricKey            string        `mapstructure:"TOKEN_SYMMETRIC_KEY"`
+	TokenPrivateKeyPath          string        `mapstructure:"TOKEN_PRIVATE_KEY_PATH"`
+	TokenPublicKeyPaths          []string      `mapstructure:"TOKEN_PUBLIC_KEY_PATHS"`
+	AccessTokenDuration          time.Duration `mapstructure:"ACCESS_TOKEN_DURATION"`
+	RefreshTokenDuration         time.Duration `mapstructure:"REFRESH_TOKEN_DURATION"`
+	ResetPasswordDuration        time.Duration `mapstructure:"RESET_PASSWORD_DURATION"`
+	ResetPasswordRedirectURL     string        `mapstructure:"RESET_PASSWORD_REDIRECT_URL"`
+	EmailVerificationDuration    time.Duration `mapstructure:"EMAIL_VERIFICATION_DURATION"`
+	EmailVerificationRedirectURL string        `mapstructure:"EMAIL_VERIFICATION_REDIRECT_URL"`
+	RequireVerifiedEmailCheckout bool          `mapstructure:"REQUIRE_VERIFIED_EMAIL_CHECKOUT"`
+	RequireVerifiedEmailLogin    bool          `mapstructure:"REQUIRE_VERIFIED_EMAIL_LOGIN"`
+	LoginMaxAttempts             int           `mapstructure:"LOGIN_MAX_ATTEMPTS"`
+	LoginMaxAttemptsPerIP        int           `mapstructure:"LOGIN_MAX_ATTEMPTS_PER_IP"`
+	LoginAttemptWindow           time.Duration `mapstructure:"LOGIN_ATTEMPT_WINDOW"`
+	LoginLockoutDuration         time.Duration `mapstructure:"LOGIN_LOCKOUT_DURATION"`
+	AccountUnlockRedirectURL     string        `mapstructure:"ACCOUNT_UNLOCK_REDIRECT_URL"`
+	MfaIssuer                    string        `mapstructure:"MFA_ISSUER"`
+	MfaPendingDuration           time.Duration `mapstructure:"MFA_PENDING_DURATION"`
+	MfaRequiredRoles             []string      `mapstructure:"MFA_REQUIRED_ROLES"`
+	PaymentDriver                string        `mapstructure:"PAYMENT_DRIVER"`
+	PaymentCurrency              string        `mapstructure:"PAYMENT_CURRENCY"`
+	ShippingFlatRate             string        `mapstructure:"SHIPPING_FLAT_RATE"`
+	MailDriver                   string        `mapstructure:"MAIL_DRIVER"`
+	MailFrom                     string        `mapstructure:"MAIL_FROM"`
+	MailDropDir                  string        `mapstructure:"MAIL_DROP_DIR"`
+	SMTPHost                     string        `mapstructure:"SMTP_HOST"`
+	SMTPPort                     int           `mapstructure:"SMTP_PORT"`
+	SMTPUsername                 string        `mapstructure:"SMTP_USERNAME"`
+	SMTPPassword                 string        `mapstructure:"SMTP_PASSWORD"`
 }
 
 func LoadConfig(path string) (config Config, err error) {
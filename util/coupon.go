@@ -0,0 +1,72 @@
+package util
+
+const (
+	CouponTypePercentage   = "percentage"
+	CouponTypeFixed        = "fixed"
+	CouponTypeFreeShipping = "free_shipping"
+)
+
+// IsSupportedCouponType returns true if coupons of the type can be created
+func IsSupportedCouponType(couponType string) bool {
+	switch couponType {
+	case CouponTypePercentage, CouponTypeFixed, CouponTypeFreeShipping:
+		return true
+	}
+	return false
+}
+
+// CouponDiscount computes what a coupon takes off the eligible amount, in
+// cents. value is in cents for fixed coupons and in hundredths of a percent
+// for percentage coupons, which is what ParseMoney gives for "15.00" meaning
+// 15%. The discount never exceeds the eligible amount and percentages round
+// down to the cent.
+func CouponDiscount(couponType string, value, eligible int64) int64 {
+	if eligible <= 0 || value <= 0 {
+		return 0
+	}
+
+	var discount int64
+	switch couponType {
+	case CouponTypePercentage:
+		discount = eligible * min(value, 10000) / 10000
+	case CouponTypeFixed:
+		discount = value
+	}
+
+	return min(discount, eligible)
+}
+
+// AllocateDiscount spreads a discount over line amounts in proportion to
+// them. Cents lost to rounding go to the first lines that still have room,
+// so the shares always add up to the discount when it does not exceed the
+// total of the lines.
+func AllocateDiscount(discount int64, amounts []int64) []int64 {
+	shares := make([]int64, len(amounts))
+
+	var total int64
+	for _, amount := range amounts {
+		total += max(amount, 0)
+	}
+	if total == 0 || discount <= 0 {
+		return shares
+	}
+	discount = min(discount, total)
+
+	allocated := int64(0)
+	for i, amount := range amounts {
+		if amount <= 0 {
+			continue
+		}
+		shares[i] = discount * amount / total
+		allocated += shares[i]
+	}
+
+	for i := 0; allocated < discount; i = (i + 1) % len(amounts) {
+		if shares[i] < amounts[i] {
+			shares[i]++
+			allocated++
+		}
+	}
+
+	return shares
+}
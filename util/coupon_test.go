@@ -0,0 +1,39 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCouponDiscount(t *testing.T) {
+	testCases := []struct {
+		name       string
+		couponType string
+		value      int64
+		eligible   int64
+		discount   int64
+	}{
+		{name: "Percentage", couponType: CouponTypePercentage, value: 1500, eligible: 2000, discount: 300},
+		{name: "PercentageRoundsDown", couponType: CouponTypePercentage, value: 1000, eligible: 999, discount: 99},
+		{name: "PercentageCapped", couponType: CouponTypePercentage, value: 15000, eligible: 2000, discount: 2000},
+		{name: "Fixed", couponType: CouponTypeFixed, value: 500, eligible: 2000, discount: 500},
+		{name: "FixedCapped", couponType: CouponTypeFixed, value: 5000, eligible: 2000, discount: 2000},
+		{name: "FreeShipping", couponType: CouponTypeFreeShipping, value: 500, eligible: 2000, discount: 0},
+		{name: "NothingEligible", couponType: CouponTypeFixed, value: 500, eligible: 0, discount: 0},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.discount, CouponDiscount(tc.couponType, tc.value, tc.eligible))
+		})
+	}
+}
+
+func TestAllocateDiscount(t *testing.T) {
+	require.Equal(t, []int64{100, 200}, AllocateDiscount(300, []int64{1000, 2000}))
+	require.Equal(t, []int64{34, 33, 33}, AllocateDiscount(100, []int64{1000, 1000, 1000}))
+	require.Equal(t, []int64{0, 150}, AllocateDiscount(150, []int64{0, 1000}))
+	require.Equal(t, []int64{100, 50}, AllocateDiscount(500, []int64{100, 50}))
+	require.Equal(t, []int64{0, 0}, AllocateDiscount(100, []int64{0, 0}))
+}
@@ -8,4 +8,38 @@ var (
 	ErrInvalidAuthFormat     = errors.New("invalid authorization header format")
 	ErrInvalidToken          = errors.New("invalid token")
 	ErrExpiredToken          = errors.New("expired token")
+	ErrRevokedToken          = errors.New("token has been revoked")
+	ErrEmptyOrder            = errors.New("order must contain at least one item")
+	ErrInvalidQuantity       = errors.New("quantity must be greater than zero")
+	ErrInsufficientStock     = errors.New("insufficient stock")
+	ErrVariantUnavailable    = errors.New("product variant is no longer available")
+	ErrInvalidAmount         = errors.New("invalid amount")
+	ErrInvalidOrderStatus    = errors.New("invalid order status")
+	ErrInvalidTransition     = errors.New("order status transition is not allowed")
+	ErrInvalidExpand         = errors.New("invalid expand value")
+	ErrPaymentRequired       = errors.New("orders are marked paid by capturing a payment")
+	ErrOrderNotPayable       = errors.New("only pending orders can be paid")
+	ErrRefundRequired        = errors.New("orders are marked refunded by refunding their payment")
+	ErrOrderNotRefundable    = errors.New("order has no captured payment left to refund")
+	ErrRefundExceedsCaptured = errors.New("refund exceeds the captured amount left on the order")
+	ErrInvalidRefundItem     = errors.New("invalid refund item")
+	ErrOrderNotReturnable    = errors.New("only delivered orders can be returned")
+	ErrInvalidReturnItem     = errors.New("invalid return item")
+	ErrInvalidReturnStatus   = errors.New("invalid return status")
+	ErrReturnTransition      = errors.New("return status transition is not allowed")
+	ErrInvalidCoupon         = errors.New("invalid coupon code")
+	ErrCouponNotApplicable   = errors.New("coupon cannot be applied to this order")
+	ErrOrderAddressLocked    = errors.New("order addresses cannot be changed after fulfillment")
+	ErrMissingAddress        = errors.New("a shipping address is required")
+	ErrSessionBlocked        = errors.New("session is blocked")
+	ErrEmailNotVerified      = errors.New("email address is not verified")
+	ErrInvalidCredentials    = errors.New("invalid credentials")
+	ErrTooManyLoginAttempts  = errors.New("too many failed login attempts, try again later")
+	ErrInvalidMfaCode        = errors.New("invalid two-factor authentication code")
+	ErrMfaRequired           = errors.New("two-factor authentication must be enabled for this role")
+	ErrMfaAlreadyEnabled     = errors.New("two-factor authentication is already enabled")
+	ErrMfaNotEnabled         = errors.New("two-factor authentication is not enabled")
+	ErrInvalidApiKey         = errors.New("invalid api key")
+	ErrApiKeyNotAllowed      = errors.New("api keys cannot be used on this route")
+	ErrInvalidScope          = errors.New("invalid api key scope")
 )
@@ -0,0 +1,70 @@
+package util
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ParseMoney converts a DECIMAL(10,2) value such as "12.50" into cents
+func ParseMoney(s string) (int64, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, ErrInvalidAmount
+	}
+
+	negative := false
+	if s[0] == '-' || s[0] == '+' {
+		negative = s[0] == '-'
+		s = s[1:]
+	}
+
+	whole, frac, hasFrac := strings.Cut(s, ".")
+	if !isDigits(whole) || (hasFrac && !isDigits(frac)) || len(frac) > 2 {
+		return 0, fmt.Errorf("%w: %q", ErrInvalidAmount, s)
+	}
+	for len(frac) < 2 {
+		frac += "0"
+	}
+
+	units, err := strconv.ParseInt(whole, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %q", ErrInvalidAmount, s)
+	}
+	cents, err := strconv.ParseInt(frac, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %q", ErrInvalidAmount, s)
+	}
+
+	amount := units*100 + cents
+	if negative {
+		amount = -amount
+	}
+
+	return amount, nil
+}
+
+// isDigits reports whether s is a non-empty run of ASCII digits; it rejects
+// the signs and spaces strconv would accept
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// FormatMoney converts cents back into the DECIMAL(10,2) text representation
+func FormatMoney(cents int64) string {
+	sign := ""
+	if cents < 0 {
+		sign = "-"
+		cents = -cents
+	}
+
+	return fmt.Sprintf("%s%d.%02d", sign, cents/100, cents%100)
+}
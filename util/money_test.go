@@ -0,0 +1,50 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMoney(t *testing.T) {
+	testCases := []struct {
+		input string
+		cents int64
+		ok    bool
+	}{
+		{"12.50", 1250, true},
+		{"12.5", 1250, true},
+		{"12", 1200, true},
+		{"0.01", 1, true},
+		{"-3.20", -320, true},
+		{"", 0, false},
+		{"1.234", 0, false},
+		{"abc", 0, false},
+		{".50", 0, false},
+		{"12.", 0, false},
+		{"1.+5", 0, false},
+		{"1.-5", 0, false},
+		{"--1", 0, false},
+		{"+-1", 0, false},
+		{"1 .50", 0, false},
+		{"1_000", 0, false},
+		{"99999999999999999999", 0, false},
+	}
+
+	for _, tc := range testCases {
+		cents, err := ParseMoney(tc.input)
+		if !tc.ok {
+			require.ErrorIs(t, err, ErrInvalidAmount, tc.input)
+			continue
+		}
+		require.NoError(t, err, tc.input)
+		require.Equal(t, tc.cents, cents, tc.input)
+	}
+}
+
+func TestFormatMoney(t *testing.T) {
+	require.Equal(t, "12.50", FormatMoney(1250))
+	require.Equal(t, "0.01", FormatMoney(1))
+	require.Equal(t, "-3.20", FormatMoney(-320))
+	require.Equal(t, "0.00", FormatMoney(0))
+}
@@ -0,0 +1,39 @@
+package util
+
+const (
+	OrderStatusPending   = "pending"
+	OrderStatusPaid      = "paid"
+	OrderStatusFulfilled = "fulfilled"
+	OrderStatusShipped   = "shipped"
+	OrderStatusDelivered = "delivered"
+	OrderStatusCancelled = "cancelled"
+	OrderStatusRefunded  = "refunded"
+)
+
+// orderStatusTransitions lists the statuses an order can move to from each status.
+// Cancelled and refunded orders are final.
+var orderStatusTransitions = map[string][]string{
+	OrderStatusPending:   {OrderStatusPaid, OrderStatusCancelled},
+	OrderStatusPaid:      {OrderStatusFulfilled, OrderStatusCancelled, OrderStatusRefunded},
+	OrderStatusFulfilled: {OrderStatusShipped, OrderStatusCancelled, OrderStatusRefunded},
+	OrderStatusShipped:   {OrderStatusDelivered, OrderStatusRefunded},
+	OrderStatusDelivered: {OrderStatusRefunded},
+	OrderStatusCancelled: {},
+	OrderStatusRefunded:  {},
+}
+
+// IsSupportedOrderStatus returns true if the status is part of the order lifecycle
+func IsSupportedOrderStatus(status string) bool {
+	_, ok := orderStatusTransitions[status]
+	return ok
+}
+
+// CanTransitionOrderStatus returns true if an order may move from one status to another
+func CanTransitionOrderStatus(from, to string) bool {
+	for _, next := range orderStatusTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
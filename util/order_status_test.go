@@ -0,0 +1,19 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCanTransitionOrderStatus(t *testing.T) {
+	require.True(t, CanTransitionOrderStatus(OrderStatusPending, OrderStatusPaid))
+	require.True(t, CanTransitionOrderStatus(OrderStatusShipped, OrderStatusDelivered))
+	require.True(t, CanTransitionOrderStatus(OrderStatusDelivered, OrderStatusRefunded))
+
+	require.False(t, CanTransitionOrderStatus(OrderStatusPending, OrderStatusShipped))
+	require.False(t, CanTransitionOrderStatus(OrderStatusShipped, OrderStatusCancelled))
+	require.False(t, CanTransitionOrderStatus(OrderStatusCancelled, OrderStatusPending))
+	require.False(t, CanTransitionOrderStatus(OrderStatusPaid, OrderStatusPaid))
+	require.False(t, CanTransitionOrderStatus("unknown", OrderStatusPaid))
+}
@@ -0,0 +1,37 @@
+package util
+
+const (
+	ReturnStatusRequested = "requested"
+	ReturnStatusApproved  = "approved"
+	ReturnStatusRejected  = "rejected"
+	ReturnStatusCancelled = "cancelled"
+	ReturnStatusReceived  = "received"
+	ReturnStatusRefunded  = "refunded"
+)
+
+// returnStatusTransitions lists the statuses a return can move to from each status.
+// Received returns are refunded, rejected, cancelled and refunded returns are final.
+var returnStatusTransitions = map[string][]string{
+	ReturnStatusRequested: {ReturnStatusApproved, ReturnStatusRejected, ReturnStatusCancelled},
+	ReturnStatusApproved:  {ReturnStatusReceived, ReturnStatusCancelled},
+	ReturnStatusReceived:  {ReturnStatusRefunded},
+	ReturnStatusRejected:  {},
+	ReturnStatusCancelled: {},
+	ReturnStatusRefunded:  {},
+}
+
+// IsSupportedReturnStatus returns true if the status is part of the return lifecycle
+func IsSupportedReturnStatus(status string) bool {
+	_, ok := returnStatusTransitions[status]
+	return ok
+}
+
+// CanTransitionReturnStatus returns true if a return may move from one status to another
+func CanTransitionReturnStatus(from, to string) bool {
+	for _, next := range returnStatusTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
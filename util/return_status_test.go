@@ -0,0 +1,19 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCanTransitionReturnStatus(t *testing.T) {
+	require.True(t, CanTransitionReturnStatus(ReturnStatusRequested, ReturnStatusApproved))
+	require.True(t, CanTransitionReturnStatus(ReturnStatusRequested, ReturnStatusCancelled))
+	require.True(t, CanTransitionReturnStatus(ReturnStatusApproved, ReturnStatusReceived))
+	require.True(t, CanTransitionReturnStatus(ReturnStatusReceived, ReturnStatusRefunded))
+
+	require.False(t, CanTransitionReturnStatus(ReturnStatusRequested, ReturnStatusReceived))
+	require.False(t, CanTransitionReturnStatus(ReturnStatusReceived, ReturnStatusCancelled))
+	require.False(t, CanTransitionReturnStatus(ReturnStatusRejected, ReturnStatusApproved))
+	require.False(t, CanTransitionReturnStatus("unknown", ReturnStatusApproved))
+}
@@ -0,0 +1,7 @@
+package util
+
+const (
+	UserRole  = "user"
+	StaffRole = "staff"
+	AdminRole = "admin"
+)
@@ -0,0 +1,70 @@
+package util
+
+import (
+	"encoding/base32"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHOTPRFC6238Vectors(t *testing.T) {
+	// SHA1 test vectors from RFC 6238 appendix B
+	key := []byte("12345678901234567890")
+
+	vectors := map[int64]string{
+		59:          "94287082",
+		1111111109:  "07081804",
+		1111111111:  "14050471",
+		1234567890:  "89005924",
+		2000000000:  "69279037",
+		20000000000: "65353130",
+	}
+
+	for unix, expected := range vectors {
+		step := TOTPStep(time.Unix(unix, 0))
+		require.Equal(t, expected, hotp(key, uint64(step), 8))
+	}
+}
+
+func TestValidateTOTP(t *testing.T) {
+	secret := base32.StdEncoding.EncodeToString([]byte("12345678901234567890"))
+	now := time.Unix(1111111111, 0)
+
+	code, err := TOTPCode(secret, now)
+	require.NoError(t, err)
+	require.Equal(t, "050471", code)
+
+	step, ok := ValidateTOTP(secret, code, now)
+	require.True(t, ok)
+	require.Equal(t, TOTPStep(now), step)
+
+	// one step of clock drift is tolerated, more is not
+	_, ok = ValidateTOTP(secret, code, now.Add(TOTPPeriod))
+	require.True(t, ok)
+	_, ok = ValidateTOTP(secret, code, now.Add(3*TOTPPeriod))
+	require.False(t, ok)
+
+	_, ok = ValidateTOTP(secret, "000000", now)
+	require.False(t, ok)
+	_, ok = ValidateTOTP(secret, "12345", now)
+	require.False(t, ok)
+	_, ok = ValidateTOTP("not base32!", code, now)
+	require.False(t, ok)
+}
+
+func TestGenerateTOTPSecret(t *testing.T) {
+	secret, err := GenerateTOTPSecret()
+	require.NoError(t, err)
+	require.Len(t, secret, 32)
+
+	other, err := GenerateTOTPSecret()
+	require.NoError(t, err)
+	require.NotEqual(t, secret, other)
+
+	uri := TOTPProvisioningURI("Shop", "jane@example.com", secret)
+	require.True(t, strings.HasPrefix(uri, "otpauth://totp/Shop:jane@example.com?"))
+	require.Contains(t, uri, "secret="+secret)
+	require.Contains(t, uri, "issuer=Shop")
+}